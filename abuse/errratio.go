@@ -0,0 +1,137 @@
+package abuse
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// ErrorRatioConfig configures 404/401 ratio-based abuse scoring.
+type ErrorRatioConfig struct {
+	// Ratio is the fraction of observed responses that may be 404 or 401
+	// before the client is throttled. Zero means the default of 0.5.
+	Ratio float64
+	// MinSamples is how many responses must be observed in the window
+	// before the ratio is acted on, so a single 404 cannot trip it. Zero
+	// means the default of 20.
+	MinSamples int
+	// Window is the observation window. Zero means one minute.
+	Window time.Duration
+	// Penalty is the restrictive policy applied to abusive keys. Zero
+	// fields inherit from the policy being overridden.
+	Penalty signalfence.Policy
+	// PenaltyTTL is how long the penalty lasts. Zero means ten minutes.
+	PenaltyTTL time.Duration
+	// OnAbuse, when set, is called once per detection with the client key
+	// and its error ratio at that point.
+	OnAbuse func(client string, ratio float64)
+}
+
+// statusWindow tracks one client's response counts in the current window.
+type statusWindow struct {
+	start     time.Time
+	total     int
+	errors    int
+	escalated bool
+}
+
+// ErrorRatioDetector watches response status codes per key and escalates
+// keys whose share of 404 and 401 responses is suspiciously high — typical
+// of enumeration and credential-guessing traffic. Windows for clients not
+// seen within cfg.Window are swept lazily as new windows open, keeping the
+// map bounded under client churn. It is safe for concurrent use.
+type ErrorRatioDetector struct {
+	mu      sync.Mutex
+	cfg     ErrorRatioConfig
+	limiter Escalator
+	clients map[string]*statusWindow
+	inserts int
+}
+
+// NewErrorRatioDetector returns a detector that escalates abusive keys on
+// limiter.
+func NewErrorRatioDetector(cfg ErrorRatioConfig, limiter Escalator) *ErrorRatioDetector {
+	if cfg.Ratio <= 0 {
+		cfg.Ratio = 0.5
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 20
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.PenaltyTTL <= 0 {
+		cfg.PenaltyTTL = 10 * time.Minute
+	}
+	return &ErrorRatioDetector{
+		cfg:     cfg,
+		limiter: limiter,
+		clients: make(map[string]*statusWindow),
+	}
+}
+
+// ObserveStatus records a response status for client and escalates the
+// client once per window if its error ratio crosses the threshold.
+func (d *ErrorRatioDetector) ObserveStatus(client string, status int) {
+	now := time.Now()
+
+	d.mu.Lock()
+	w := d.clients[client]
+	if w == nil || now.Sub(w.start) > d.cfg.Window {
+		w = &statusWindow{start: now}
+		d.clients[client] = w
+		d.inserts++
+		if d.inserts >= sweepEvery {
+			d.inserts = 0
+			for k, cw := range d.clients {
+				if now.Sub(cw.start) > d.cfg.Window {
+					delete(d.clients, k)
+				}
+			}
+		}
+	}
+	w.total++
+	if status == http.StatusNotFound || status == http.StatusUnauthorized {
+		w.errors++
+	}
+	ratio := float64(w.errors) / float64(w.total)
+	trigger := !w.escalated && w.total >= d.cfg.MinSamples && ratio >= d.cfg.Ratio
+	if trigger {
+		w.escalated = true
+	}
+	d.mu.Unlock()
+
+	if trigger {
+		d.limiter.Escalate(client, d.cfg.Penalty, d.cfg.PenaltyTTL)
+		if d.cfg.OnAbuse != nil {
+			d.cfg.OnAbuse(client, ratio)
+		}
+	}
+}
+
+// statusRecorder captures the response status written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Middleware observes the status of every response after the handler runs.
+// It never blocks by itself; blocking happens via the escalated policy on
+// the limiter. When keyFunc is nil the client IP is used.
+func (d *ErrorRatioDetector) Middleware(keyFunc func(*http.Request) string, next http.Handler) http.Handler {
+	if keyFunc == nil {
+		keyFunc = signalfence.ClientIP
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		d.ObserveStatus(keyFunc(r), rec.status)
+	})
+}
@@ -0,0 +1,68 @@
+package abuse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/signalfence"
+)
+
+func TestErrorRatioEscalates(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 100})
+	var abused string
+	d := NewErrorRatioDetector(ErrorRatioConfig{
+		Ratio:      0.5,
+		MinSamples: 10,
+		Penalty:    signalfence.Policy{Capacity: 1, RefillPerSec: 0.01},
+		OnAbuse: func(client string, ratio float64) {
+			abused = client
+		},
+	}, rl)
+
+	// 5 hits, then 5 misses: at the 10th sample the ratio reaches 0.5.
+	for i := 0; i < 5; i++ {
+		d.ObserveStatus("enum", http.StatusOK)
+	}
+	for i := 0; i < 4; i++ {
+		d.ObserveStatus("enum", http.StatusNotFound)
+	}
+	if rl.Escalated("enum") {
+		t.Fatal("escalated before min samples")
+	}
+	d.ObserveStatus("enum", http.StatusUnauthorized)
+	if !rl.Escalated("enum") {
+		t.Fatal("50% error ratio over 10 samples should escalate")
+	}
+	if abused != "enum" {
+		t.Fatalf("OnAbuse client = %q", abused)
+	}
+}
+
+func TestErrorRatioIgnoresHealthyTraffic(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 100})
+	d := NewErrorRatioDetector(ErrorRatioConfig{MinSamples: 10}, rl)
+	for i := 0; i < 100; i++ {
+		d.ObserveStatus("ok-client", http.StatusOK)
+	}
+	if rl.Escalated("ok-client") {
+		t.Fatal("healthy client escalated")
+	}
+}
+
+func TestErrorRatioMiddlewareObservesStatus(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 100})
+	d := NewErrorRatioDetector(ErrorRatioConfig{Ratio: 0.5, MinSamples: 2}, rl)
+	h := d.Middleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	req.RemoteAddr = "9.9.9.9:1"
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if !rl.Escalated("9.9.9.9") {
+		t.Fatal("two straight 404s over min samples 2 should escalate")
+	}
+}
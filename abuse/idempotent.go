@@ -0,0 +1,41 @@
+package abuse
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// IdempotentBudget wraps limiter's middleware so retries of the same
+// operation are not double-charged: a request carrying an Idempotency-Key
+// already charged to the client within window passes straight through.
+// Clients retrying a timed-out call per our own guidance keep their
+// budget; only the first attempt pays. Operations are only remembered once
+// they were admitted, so a 429'd attempt does not earn its retry a free
+// pass, and requests without the header take the normal charged path.
+//
+// The dedup state is a ReplayDetector keyed like the limiter. Note the
+// interaction with ReplayDetector.Middleware: use one or the other per
+// route — rejecting replays and waving them through are opposite policies.
+func IdempotentBudget(limiter *signalfence.RateLimiter, window time.Duration, next http.Handler) http.Handler {
+	d := NewReplayDetector(window)
+	limited := limiter.Middleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := r.Header.Get(NonceHeader)
+		if op == "" {
+			limited.ServeHTTP(w, r)
+			return
+		}
+		client := limiter.Key(r)
+		if d.Observed(client, op) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		limited.ServeHTTP(rec, r)
+		if rec.status != http.StatusTooManyRequests {
+			d.Seen(client, op)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package abuse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+func idempotentRequest(key string) *http.Request {
+	req := httptest.NewRequest("POST", "/charge", nil)
+	req.RemoteAddr = "7.7.7.7:1"
+	if key != "" {
+		req.Header.Set(NonceHeader, key)
+	}
+	return req
+}
+
+func TestIdempotentBudgetRetryNotDoubleCharged(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 2, RefillPerSec: 0.001})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := IdempotentBudget(rl, time.Minute, ok)
+
+	// Two attempts of the same operation cost one token: the retry rides
+	// the original charge, leaving one token for a fresh operation.
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, idempotentRequest("op-1"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("attempt %d: status = %d", i, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, idempotentRequest("op-2"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("second operation: status = %d", w.Code)
+	}
+	// Budget is now spent; a third distinct operation is denied.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, idempotentRequest("op-3"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third operation: status = %d, want 429", w.Code)
+	}
+}
+
+func TestIdempotentBudgetDeniedAttemptNotRemembered(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 1, RefillPerSec: 0.001})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := IdempotentBudget(rl, time.Minute, ok)
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("op-1"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, idempotentRequest("op-2"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over-budget operation: status = %d, want 429", w.Code)
+	}
+	// The denied operation must not have earned a free retry.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, idempotentRequest("op-2"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("retry of denied operation: status = %d, want 429", w.Code)
+	}
+}
+
+func TestIdempotentBudgetNoKeyChargedNormally(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 1, RefillPerSec: 0.001})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := IdempotentBudget(rl, time.Minute, ok)
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest(""))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, idempotentRequest(""))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unkeyed requests should each be charged: status = %d", w.Code)
+	}
+}
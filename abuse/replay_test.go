@@ -0,0 +1,61 @@
+package abuse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReplayDetectorFlagsRepeats(t *testing.T) {
+	d := NewReplayDetector(time.Minute)
+	if d.Seen("client-a", "n1") {
+		t.Fatal("first sighting is not a replay")
+	}
+	if !d.Seen("client-a", "n1") {
+		t.Fatal("second sighting should be flagged")
+	}
+	// Same nonce from a different client is independent.
+	if d.Seen("client-b", "n1") {
+		t.Fatal("nonces are tracked per client")
+	}
+}
+
+func TestReplayDetectorExpires(t *testing.T) {
+	d := NewReplayDetector(time.Millisecond)
+	d.Seen("c", "n")
+	time.Sleep(5 * time.Millisecond)
+	if d.Seen("c", "n") {
+		t.Fatal("expired nonce should not count as a replay")
+	}
+}
+
+func TestReplayMiddleware(t *testing.T) {
+	d := NewReplayDetector(time.Minute)
+	h := d.Middleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("POST", "/pay", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+	req.Header.Set(NonceHeader, "txn-1")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("first request status = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("replay status = %d, want 409", rec.Code)
+	}
+
+	// No nonce: always passes.
+	bare := httptest.NewRequest("GET", "/pay", nil)
+	bare.RemoteAddr = "1.2.3.4:5"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, bare)
+	if rec.Code != 200 {
+		t.Fatalf("nonce-less request status = %d", rec.Code)
+	}
+}
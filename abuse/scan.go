@@ -0,0 +1,122 @@
+package abuse
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// Escalator applies a temporary restrictive policy to a key.
+// *signalfence.RateLimiter satisfies it.
+type Escalator interface {
+	Escalate(key string, pol signalfence.Policy, ttl time.Duration)
+}
+
+// ScanDetectorConfig configures slow-scan detection.
+type ScanDetectorConfig struct {
+	// Threshold is the number of distinct paths within Window that marks a
+	// client as a scanner. Zero means the default of 100.
+	Threshold int
+	// Window is the observation window. Zero means one minute.
+	Window time.Duration
+	// Penalty is the restrictive policy applied to detected scanners.
+	// Zero fields inherit from the policy being overridden.
+	Penalty signalfence.Policy
+	// PenaltyTTL is how long the penalty lasts. Zero means ten minutes.
+	PenaltyTTL time.Duration
+	// OnScan, when set, is called once per detection with the client key
+	// and the number of distinct paths observed.
+	OnScan func(client string, uniquePaths int)
+}
+
+// pathWindow tracks the distinct paths one client touched in the current
+// fixed window.
+type pathWindow struct {
+	start     time.Time
+	paths     map[string]struct{}
+	escalated bool
+}
+
+// ScanDetector flags clients that touch an unusually high number of
+// distinct routes in a short window — the signature of a vulnerability
+// scanner crawling the API — and escalates them to a restrictive policy.
+// Windows for clients not seen within cfg.Window are swept lazily as new
+// windows open, so a scanner rotating source addresses cannot grow the
+// map without bound. It is safe for concurrent use.
+type ScanDetector struct {
+	mu      sync.Mutex
+	cfg     ScanDetectorConfig
+	limiter Escalator
+	clients map[string]*pathWindow
+	inserts int
+}
+
+// NewScanDetector returns a detector that escalates scanners on limiter.
+func NewScanDetector(cfg ScanDetectorConfig, limiter Escalator) *ScanDetector {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 100
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.PenaltyTTL <= 0 {
+		cfg.PenaltyTTL = 10 * time.Minute
+	}
+	return &ScanDetector{
+		cfg:     cfg,
+		limiter: limiter,
+		clients: make(map[string]*pathWindow),
+	}
+}
+
+// Observe records that client requested path. When the client crosses the
+// distinct-path threshold within the window it is escalated once per window
+// and OnScan is fired.
+func (d *ScanDetector) Observe(client, path string) {
+	now := time.Now()
+
+	d.mu.Lock()
+	w := d.clients[client]
+	if w == nil || now.Sub(w.start) > d.cfg.Window {
+		w = &pathWindow{start: now, paths: make(map[string]struct{})}
+		d.clients[client] = w
+		d.inserts++
+		if d.inserts >= sweepEvery {
+			d.inserts = 0
+			for k, cw := range d.clients {
+				if now.Sub(cw.start) > d.cfg.Window {
+					delete(d.clients, k)
+				}
+			}
+		}
+	}
+	w.paths[path] = struct{}{}
+	trigger := !w.escalated && len(w.paths) >= d.cfg.Threshold
+	if trigger {
+		w.escalated = true
+	}
+	unique := len(w.paths)
+	d.mu.Unlock()
+
+	if trigger {
+		d.limiter.Escalate(client, d.cfg.Penalty, d.cfg.PenaltyTTL)
+		if d.cfg.OnScan != nil {
+			d.cfg.OnScan(client, unique)
+		}
+	}
+}
+
+// Middleware observes every request before passing it through. It never
+// blocks by itself; blocking happens via the escalated policy on the
+// limiter. When keyFunc is nil the client IP is used.
+func (d *ScanDetector) Middleware(keyFunc func(*http.Request) string, next http.Handler) http.Handler {
+	if keyFunc == nil {
+		keyFunc = signalfence.ClientIP
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.Observe(keyFunc(r), r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
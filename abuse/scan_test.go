@@ -0,0 +1,52 @@
+package abuse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+func TestScanDetectorEscalatesAfterThreshold(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 100})
+	var scanned string
+	d := NewScanDetector(ScanDetectorConfig{
+		Threshold: 10,
+		Penalty:   signalfence.Policy{Capacity: 1, RefillPerSec: 0.01},
+		OnScan: func(client string, uniquePaths int) {
+			scanned = client
+		},
+	}, rl)
+
+	for i := 0; i < 9; i++ {
+		d.Observe("scanner", fmt.Sprintf("/path/%d", i))
+	}
+	if rl.Escalated("scanner") {
+		t.Fatal("escalated below threshold")
+	}
+	d.Observe("scanner", "/path/9")
+	if !rl.Escalated("scanner") {
+		t.Fatal("10 distinct paths should escalate")
+	}
+	if scanned != "scanner" {
+		t.Fatalf("OnScan client = %q", scanned)
+	}
+
+	// The escalated policy is enforced: capacity 1 blocks the second hit.
+	rl.Allow("scanner")
+	if d := rl.Allow("scanner"); d.Allowed {
+		t.Fatalf("escalated client should be clamped, got %+v", d)
+	}
+}
+
+func TestScanDetectorIgnoresRepeatedPaths(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 100})
+	d := NewScanDetector(ScanDetectorConfig{Threshold: 5, Window: time.Minute}, rl)
+	for i := 0; i < 50; i++ {
+		d.Observe("busy", "/same/path")
+	}
+	if rl.Escalated("busy") {
+		t.Fatal("repeated hits on one path are not a scan")
+	}
+}
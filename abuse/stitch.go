@@ -0,0 +1,181 @@
+package abuse
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StitcherConfig configures identity stitching.
+type StitcherConfig struct {
+	// MaxIdle is how long an identifier stays linked after it was last
+	// seen; idle links are forgotten so a recycled IP does not inherit a
+	// stranger's history. Zero means 30 minutes.
+	MaxIdle time.Duration
+	// MaxKeys bounds the number of tracked identifiers. At the cap, new
+	// identifiers are still keyed but not linked, so memory stays bounded
+	// under key-churn attacks. Zero means 100000.
+	MaxKeys int
+	// Now overrides the time source for tests.
+	Now func() time.Time
+}
+
+// stitchNode is one identifier in the union-find forest. Following parent
+// pointers reaches the group's representative, whose key is the logical
+// client's canonical key.
+type stitchNode struct {
+	key      string
+	parent   *stitchNode
+	lastSeen time.Time
+}
+
+// Stitcher links identifiers observed together — the IP, cookie and token
+// on one request — into one logical client, so a client rotating through
+// identifiers keeps drawing from a single budget. Groups are a union-find
+// forest: linking any member of two groups merges them, and the canonical
+// key is the representative's, stable as long as the group lives.
+type Stitcher struct {
+	cfg   StitcherConfig
+	mu    sync.Mutex
+	nodes map[string]*stitchNode
+	sweep time.Time
+}
+
+// NewStitcher returns a Stitcher with cfg's zero values defaulted.
+func NewStitcher(cfg StitcherConfig) *Stitcher {
+	if cfg.MaxIdle <= 0 {
+		cfg.MaxIdle = 30 * time.Minute
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = 100000
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Stitcher{cfg: cfg, nodes: make(map[string]*stitchNode)}
+}
+
+// find returns the representative of n's group, compressing the path.
+func find(n *stitchNode) *stitchNode {
+	for n.parent != nil {
+		if n.parent.parent != nil {
+			n.parent = n.parent.parent
+		}
+		n = n.parent
+	}
+	return n
+}
+
+// node returns the tracked node for key, creating it when under the cap.
+func (s *Stitcher) node(key string, now time.Time) *stitchNode {
+	if n, ok := s.nodes[key]; ok {
+		n.lastSeen = now
+		find(n).lastSeen = now
+		return n
+	}
+	if len(s.nodes) >= s.cfg.MaxKeys {
+		return nil
+	}
+	n := &stitchNode{key: key, lastSeen: now}
+	s.nodes[key] = n
+	return n
+}
+
+// Link records that keys were observed together on one request, merging
+// their groups. Unknown keys are added; empty keys are ignored.
+func (s *Stitcher) Link(keys ...string) {
+	now := s.cfg.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maybeSweep(now)
+	var root *stitchNode
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		n := s.node(key, now)
+		if n == nil {
+			continue
+		}
+		r := find(n)
+		if root == nil {
+			root = r
+			continue
+		}
+		if r != root {
+			r.parent = root
+		}
+	}
+}
+
+// Canonical returns the logical client key for an identifier: the group
+// representative's key, or the identifier itself when it is unknown.
+func (s *Stitcher) Canonical(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nodes[key]
+	if !ok {
+		return key
+	}
+	return find(n).key
+}
+
+// Len returns the number of tracked identifiers.
+func (s *Stitcher) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.nodes)
+}
+
+// maybeSweep drops identifiers idle past MaxIdle, at most once per MaxIdle
+// so the sweep cost amortizes to nothing. Callers hold s.mu. A group's
+// representative is kept alive by activity from any member, so sweeping
+// never orphans a live group; a swept representative's survivors become
+// their own groups again, which only resets budget sharing after the whole
+// constellation went quiet.
+func (s *Stitcher) maybeSweep(now time.Time) {
+	if now.Sub(s.sweep) < s.cfg.MaxIdle {
+		return
+	}
+	s.sweep = now
+	for key, n := range s.nodes {
+		if now.Sub(n.lastSeen) > s.cfg.MaxIdle {
+			delete(s.nodes, key)
+		}
+	}
+	// Detach parent pointers into swept nodes.
+	for _, n := range s.nodes {
+		for p := n; p.parent != nil; p = p.parent {
+			if _, ok := s.nodes[p.parent.key]; !ok {
+				p.parent = nil
+				break
+			}
+		}
+	}
+}
+
+// KeyFunc returns an extractor that stitches every identity found on a
+// request and keys it by the logical client. Each identify function
+// returns one identifier or "" when the request does not carry it; the
+// last one should always succeed (an IP extractor) so every request gets a
+// key. Use it as Config.KeyFunc:
+//
+//	stitch := abuse.NewStitcher(abuse.StitcherConfig{})
+//	cfg.KeyFunc = stitch.KeyFunc(cookieKey, tokenKey, signalfence.ClientIP)
+func (s *Stitcher) KeyFunc(identify ...func(*http.Request) string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		keys := make([]string, 0, len(identify))
+		for _, fn := range identify {
+			if key := fn(r); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return ""
+		}
+		if len(keys) > 1 {
+			s.Link(keys...)
+		}
+		return s.Canonical(keys[0])
+	}
+}
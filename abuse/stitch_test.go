@@ -0,0 +1,102 @@
+package abuse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+func TestStitcherLinksIdentities(t *testing.T) {
+	s := NewStitcher(StitcherConfig{})
+
+	// One request carries both cookie and token: linked.
+	s.Link("cookie:c1", "token:t1")
+	// Another carries the token and the IP: all three are now one client.
+	s.Link("token:t1", "ip:10.1.1.1")
+
+	canon := s.Canonical("cookie:c1")
+	for _, key := range []string{"token:t1", "ip:10.1.1.1"} {
+		if got := s.Canonical(key); got != canon {
+			t.Fatalf("Canonical(%q) = %q, want %q", key, got, canon)
+		}
+	}
+	if got := s.Canonical("ip:10.9.9.9"); got != "ip:10.9.9.9" {
+		t.Fatalf("unknown key not returned as-is: %q", got)
+	}
+}
+
+func TestStitcherMergesGroups(t *testing.T) {
+	s := NewStitcher(StitcherConfig{})
+	s.Link("cookie:a", "token:a")
+	s.Link("cookie:b", "token:b")
+	if s.Canonical("cookie:a") == s.Canonical("cookie:b") {
+		t.Fatal("distinct clients share a canonical key")
+	}
+	// A request carrying identifiers from both groups merges them.
+	s.Link("token:a", "token:b")
+	if s.Canonical("cookie:a") != s.Canonical("cookie:b") {
+		t.Fatal("groups not merged")
+	}
+}
+
+func TestStitcherForgetsIdleLinks(t *testing.T) {
+	now := time.Now()
+	s := NewStitcher(StitcherConfig{
+		MaxIdle: time.Minute,
+		Now:     func() time.Time { return now },
+	})
+	s.Link("cookie:a", "ip:10.1.1.1")
+
+	now = now.Add(2 * time.Minute)
+	s.Link("cookie:fresh") // triggers the sweep
+	if got := s.Canonical("cookie:a"); got != "cookie:a" {
+		t.Fatalf("idle link survived: Canonical = %q", got)
+	}
+	if s.Len() != 1 {
+		t.Fatalf("Len = %d after sweep, want 1", s.Len())
+	}
+}
+
+func TestStitcherKeyFunc(t *testing.T) {
+	s := NewStitcher(StitcherConfig{})
+	cookieKey := func(r *http.Request) string {
+		if c, err := r.Cookie("sid"); err == nil {
+			return "cookie:" + c.Value
+		}
+		return ""
+	}
+	keyFn := s.KeyFunc(cookieKey, signalfence.ExtractIP)
+
+	both := httptest.NewRequest("GET", "/", nil)
+	both.RemoteAddr = "10.1.1.1:999"
+	both.AddCookie(&http.Cookie{Name: "sid", Value: "s1"})
+	canon := keyFn(both)
+
+	// The same client without its cookie still maps to the same budget.
+	bare := httptest.NewRequest("GET", "/", nil)
+	bare.RemoteAddr = "10.1.1.1:999"
+	if got := keyFn(bare); got != canon {
+		t.Fatalf("cookieless request key = %q, want %q", got, canon)
+	}
+	// A different client on a different IP does not.
+	other := httptest.NewRequest("GET", "/", nil)
+	other.RemoteAddr = "10.2.2.2:999"
+	if got := keyFn(other); got == canon {
+		t.Fatal("unrelated client stitched into the group")
+	}
+}
+
+func TestStitcherKeyCap(t *testing.T) {
+	s := NewStitcher(StitcherConfig{MaxKeys: 2})
+	s.Link("cookie:a", "ip:1")
+	s.Link("cookie:overflow", "ip:2")
+	if s.Len() != 2 {
+		t.Fatalf("Len = %d, want cap of 2", s.Len())
+	}
+	if got := s.Canonical("cookie:overflow"); got != "cookie:overflow" {
+		t.Fatalf("over-cap key linked: %q", got)
+	}
+}
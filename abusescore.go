@@ -0,0 +1,97 @@
+package signalfence
+
+// AbuseSignals gathers everything the limiter knows about a key, handed to
+// the scoring function. Traffic counters require TrackTraffic; without it
+// DenialRate stays zero.
+type AbuseSignals struct {
+	// Traffic is the key's accumulated request counters.
+	Traffic TrafficStats
+	// DenialRate is the fraction of the key's requests that were denied.
+	DenialRate float64
+	// Banned is true while the key is blocklisted (trap hit or manual
+	// ban).
+	Banned bool
+	// Escalated is true while an abuse detector's policy override is
+	// active for the key.
+	Escalated bool
+	// Extra holds named values from Config.AbuseSignals hooks — anomaly
+	// detectors, error-ratio trackers — for custom scoring functions.
+	Extra map[string]float64
+}
+
+// AbuseScore rates how abusive a key looks on a 0-to-1 scale, combining
+// denial rate, blocklist state, active escalations and any registered
+// extra signals. Application code uses it to drive its own responses —
+// CAPTCHAs, step-up auth — from SignalFence's data without re-deriving it:
+//
+//	if limiter.AbuseScore(key) > 0.7 {
+//		serveChallenge(w, r)
+//		return
+//	}
+//
+// The default scoring treats a ban as conclusive and otherwise weighs
+// denial rate and escalation; set Config.ScoreFunc to replace it.
+func (rl *RateLimiter) AbuseScore(key string) float64 {
+	sig := AbuseSignals{
+		Traffic:   rl.traffic.snapshot(key),
+		Banned:    rl.Banned(key),
+		Escalated: rl.Escalated(key),
+	}
+	if sig.Traffic.Requests > 0 {
+		sig.DenialRate = float64(sig.Traffic.Requests-sig.Traffic.Allowed) / float64(sig.Traffic.Requests)
+	}
+	rl.mu.RLock()
+	score := rl.cfg.ScoreFunc
+	extras := rl.cfg.AbuseSignals
+	rl.mu.RUnlock()
+	if len(extras) > 0 {
+		sig.Extra = make(map[string]float64, len(extras))
+		for name, fn := range extras {
+			sig.Extra[name] = fn(key)
+		}
+	}
+	if vs := rl.ViolationScore(key); vs > 0 {
+		// Reported violations join the extra signals, normalized so the
+		// ban threshold maps to 1.
+		if sig.Extra == nil {
+			sig.Extra = make(map[string]float64, 1)
+		}
+		if banAt := rl.violationConfig().BanAt; vs >= banAt {
+			sig.Extra["violations"] = 1
+		} else {
+			sig.Extra["violations"] = vs / banAt
+		}
+	}
+	if score == nil {
+		score = defaultAbuseScore
+	}
+	s := score(key, sig)
+	if s < 0 {
+		return 0
+	}
+	if s > 1 {
+		return 1
+	}
+	return s
+}
+
+// defaultAbuseScore is the built-in scoring: a ban is conclusive, and
+// below that denial rate dominates with escalation as a strong tiebreaker.
+// Extra signals are averaged in at equal weight with the denial rate so
+// registered detectors influence the score without custom code.
+func defaultAbuseScore(_ string, sig AbuseSignals) float64 {
+	if sig.Banned {
+		return 1
+	}
+	s := sig.DenialRate
+	for _, v := range sig.Extra {
+		s += v
+	}
+	if n := 1 + len(sig.Extra); n > 1 {
+		s /= float64(n)
+	}
+	if sig.Escalated && s < 0.5 {
+		s = 0.5
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAbuseScoreSignals(t *testing.T) {
+	rl := NewRateLimiter(Config{TrackTraffic: true})
+
+	if got := rl.AbuseScore("quiet"); got != 0 {
+		t.Fatalf("unseen key score = %v, want 0", got)
+	}
+
+	// Half the key's requests denied: score tracks denial rate.
+	for i := 0; i < 5; i++ {
+		rl.traffic.record("noisy", true, 0, 0)
+		rl.traffic.record("noisy", false, 0, 0)
+	}
+	if got := rl.AbuseScore("noisy"); got != 0.5 {
+		t.Fatalf("denial-rate score = %v, want 0.5", got)
+	}
+
+	rl.Escalate("suspect", Policy{Capacity: 1}, time.Minute)
+	if got := rl.AbuseScore("suspect"); got != 0.5 {
+		t.Fatalf("escalated score = %v, want 0.5", got)
+	}
+
+	rl.Ban("banned", time.Minute)
+	if got := rl.AbuseScore("banned"); got != 1 {
+		t.Fatalf("banned score = %v, want 1", got)
+	}
+}
+
+func TestAbuseScoreExtraSignals(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		AbuseSignals: map[string]func(string) float64{
+			"err_ratio": func(key string) float64 { return 0.8 },
+		},
+	})
+	// No traffic, so the score is the average of denial rate (0) and the
+	// extra signal.
+	if got := rl.AbuseScore("k"); got != 0.4 {
+		t.Fatalf("score with extra signal = %v, want 0.4", got)
+	}
+}
+
+func TestAbuseScoreCustomFunc(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		ScoreFunc: func(key string, sig AbuseSignals) float64 { return 7 },
+	})
+	if got := rl.AbuseScore("k"); got != 1 {
+		t.Fatalf("score not clamped: %v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package signalfence
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// AccessLogger emits one structured JSON log line per rate limit decision,
+// for the binaries (and any embedder) that need per-request observability.
+// Denials always log at warn level; allowed decisions log at info level and
+// can be sampled down so high-QPS deployments are not drowned in log volume.
+type AccessLogger struct {
+	logger *slog.Logger
+	// every is the sampling stride for allowed decisions: 1 logs all,
+	// N logs one in N, 0 logs none.
+	every   uint64
+	counter atomic.Uint64
+}
+
+// NewAccessLogger returns a logger writing JSON lines to w. Records below
+// level are dropped entirely; sample is the fraction (0-1] of allowed
+// decisions to log, with denials always logged.
+func NewAccessLogger(w io.Writer, level slog.Level, sample float64) *AccessLogger {
+	al := &AccessLogger{
+		logger: slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})),
+	}
+	switch {
+	case sample >= 1:
+		al.every = 1
+	case sample > 0:
+		al.every = uint64(1/sample + 0.5)
+	}
+	return al
+}
+
+// Log records one decision with its key, route and handling latency.
+func (al *AccessLogger) Log(key, route string, d Decision, latency time.Duration) {
+	lvl := slog.LevelInfo
+	if !d.Allowed || d.WouldBlock || d.Banned {
+		lvl = slog.LevelWarn
+	} else if !al.sampled() {
+		return
+	}
+	al.logger.Log(context.Background(), lvl, "decision",
+		"key", key,
+		"route", route,
+		"allowed", d.Allowed,
+		"remaining", d.Remaining,
+		"latency_us", latency.Microseconds(),
+		"would_block", d.WouldBlock,
+		"banned", d.Banned,
+	)
+}
+
+// sampled reports whether this allowed decision falls in the sample.
+func (al *AccessLogger) sampled() bool {
+	if al.every == 1 {
+		return true
+	}
+	if al.every == 0 {
+		return false
+	}
+	return al.counter.Add(1)%al.every == 0
+}
+
+// Middleware logs one line per HTTP request from the response status and the
+// rate limit headers the limiter middleware wrote. Wrap it outside
+// RateLimiter.Middleware:
+//
+//	logger.Middleware(limiter.Middleware(handler))
+func (al *AccessLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		lvl := slog.LevelInfo
+		if rec.status == http.StatusTooManyRequests {
+			lvl = slog.LevelWarn
+		} else if !al.sampled() {
+			return
+		}
+		al.logger.Log(r.Context(), lvl, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"remaining", rec.Header().Get(headerRemaining),
+			"latency_us", time.Since(start).Microseconds(),
+		)
+	})
+}
+
+// statusWriter captures the response status for access logging.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,76 @@
+package signalfence
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func logLines(buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var m map[string]any
+		if json.Unmarshal([]byte(raw), &m) == nil {
+			lines = append(lines, m)
+		}
+	}
+	return lines
+}
+
+func TestAccessLoggerLevelsAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	al := NewAccessLogger(&buf, slog.LevelInfo, 1)
+
+	al.Log("alice", "/api", Decision{Allowed: true, Remaining: 7}, 250*time.Microsecond)
+	al.Log("bob", "/api", Decision{Allowed: false}, time.Millisecond)
+
+	lines := logLines(&buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2", len(lines))
+	}
+	if lines[0]["level"] != "INFO" || lines[0]["key"] != "alice" || lines[0]["remaining"] != float64(7) {
+		t.Fatalf("allowed line = %v", lines[0])
+	}
+	if lines[1]["level"] != "WARN" || lines[1]["allowed"] != false {
+		t.Fatalf("denied line = %v", lines[1])
+	}
+}
+
+func TestAccessLoggerSampling(t *testing.T) {
+	var buf bytes.Buffer
+	al := NewAccessLogger(&buf, slog.LevelInfo, 0.1)
+
+	for i := 0; i < 100; i++ {
+		al.Log("k", "", Decision{Allowed: true}, 0)
+	}
+	if got := len(logLines(&buf)); got != 10 {
+		t.Fatalf("sampled %d allowed lines, want 10", got)
+	}
+
+	// Denials bypass sampling entirely.
+	buf.Reset()
+	for i := 0; i < 5; i++ {
+		al.Log("k", "", Decision{Allowed: false}, 0)
+	}
+	if got := len(logLines(&buf)); got != 5 {
+		t.Fatalf("logged %d denials, want all 5", got)
+	}
+}
+
+func TestAccessLoggerLevelFilter(t *testing.T) {
+	var buf bytes.Buffer
+	al := NewAccessLogger(&buf, slog.LevelWarn, 1)
+
+	al.Log("k", "", Decision{Allowed: true}, 0)
+	al.Log("k", "", Decision{Allowed: false}, 0)
+	lines := logLines(&buf)
+	if len(lines) != 1 || lines[0]["level"] != "WARN" {
+		t.Fatalf("warn-level filter kept %v", lines)
+	}
+}
@@ -0,0 +1,268 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// configExport is the wire form of the fully-resolved config served by the
+// admin API. KeyFunc cannot be serialized, so only its origin is reported.
+type configExport struct {
+	Version   int               `json:"version"`
+	Mode      string            `json:"mode"`
+	Default   Policy            `json:"default"`
+	Routes    map[string]Policy `json:"routes,omitempty"`
+	Extractor string            `json:"extractor"`
+	Keys      int               `json:"keys"`
+}
+
+// exportConfig snapshots the effective configuration with all defaults
+// merged in.
+func (rl *RateLimiter) exportConfig() configExport {
+	rl.mu.RLock()
+	custom := rl.cfg.KeyFunc != nil
+	routeNames := make([]string, 0, len(rl.cfg.Routes))
+	for name := range rl.cfg.Routes {
+		routeNames = append(routeNames, name)
+	}
+	rl.mu.RUnlock()
+
+	exp := configExport{
+		Version:   rl.ConfigVersion(),
+		Mode:      rl.Mode().String(),
+		Default:   rl.EffectivePolicy(""),
+		Extractor: "client_ip",
+		Keys:      rl.store.Len(),
+	}
+	if custom {
+		exp.Extractor = "custom"
+	}
+	if len(routeNames) > 0 {
+		exp.Routes = make(map[string]Policy, len(routeNames))
+		for _, name := range routeNames {
+			exp.Routes[name] = rl.EffectivePolicy(name)
+		}
+	}
+	return exp
+}
+
+// routeExport is one row of the admin API's per-route table.
+type routeExport struct {
+	Route      string     `json:"route"`
+	Policy     Policy     `json:"policy"`
+	Overridden bool       `json:"overridden"`
+	Requests   uint64     `json:"requests"`
+	Denied     uint64     `json:"denied"`
+	BlockRate  float64    `json:"block_rate"`
+	Offenders  []Offender `json:"offenders,omitempty"`
+}
+
+// exportRoutes builds the per-route table: every configured override plus
+// every route traffic has been observed on, sorted by route.
+func (rl *RateLimiter) exportRoutes() []routeExport {
+	rl.mu.RLock()
+	overridden := make(map[string]bool, len(rl.cfg.Routes))
+	for name := range rl.cfg.Routes {
+		overridden[name] = true
+	}
+	rl.mu.RUnlock()
+
+	seen := rl.RouteSnapshot()
+	names := make([]string, 0, len(seen)+len(overridden))
+	for name := range seen {
+		names = append(names, name)
+	}
+	for name := range overridden {
+		if _, ok := seen[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	rows := make([]routeExport, 0, len(names))
+	for _, name := range names {
+		stats := seen[name]
+		rows = append(rows, routeExport{
+			Route:      name,
+			Policy:     rl.EffectivePolicy(name),
+			Overridden: overridden[name],
+			Requests:   stats.Requests,
+			Denied:     stats.Denied,
+			BlockRate:  stats.BlockRate(),
+			Offenders:  rl.TopOffenders(name, 5),
+		})
+	}
+	return rows
+}
+
+// AdminHandler returns an http.Handler exposing operational endpoints.
+// Mount it under /admin on a trusted listener:
+//
+//	mux.Handle("/admin/", limiter.AdminHandler())
+//
+// GET /admin/config returns the fully-resolved configuration — defaults
+// merged with every per-route override — so operators can verify what is
+// actually being enforced.
+//
+// POST /admin/config/rollback reverts to the config version that was in
+// effect before the most recent Reload and returns the restored config.
+//
+// GET /admin/mode returns the current decision mode; POST /admin/mode with
+// a JSON body like {"mode": "observe"} switches it.
+//
+// GET /admin/routes returns the per-route table backing the dashboard:
+// effective policy, request and denial counters with the block rate, and
+// the top offenders for each route (counters require TrackTraffic). PUT
+// /admin/routes?route=R with a policy JSON body installs or replaces R's
+// override at runtime; DELETE with the same query drops it back to the
+// default policy. Both go through Reload, so /admin/config/rollback
+// reverts them.
+//
+// GET /admin/buckets?key=K returns the tokens-remaining time series for K,
+// starting sampling on first request (see WatchBucket), so the dashboard
+// can chart a bucket's drain and refill during an investigation. DELETE
+// with the same query stops sampling and drops the history.
+//
+// GET /admin/traces returns sampled decision traces newest first (requires
+// Config.Trace), optionally filtered with ?key=K and truncated with
+// ?limit=N.
+func (rl *RateLimiter) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/config", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rl.exportConfig())
+	})
+	mux.HandleFunc("/admin/config/rollback", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !rl.Rollback() {
+			http.Error(w, "no previous config version", http.StatusConflict)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rl.exportConfig())
+	})
+	mux.HandleFunc("/admin/routes", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(rl.exportRoutes())
+		case http.MethodPut, http.MethodDelete:
+			route := r.URL.Query().Get("route")
+			if route == "" {
+				http.Error(w, "route query parameter required", http.StatusBadRequest)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				rl.ClearRoutePolicy(route)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			var pol Policy
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&pol); err != nil {
+				http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if pol.Capacity <= 0 && pol.RefillPerSec <= 0 {
+				http.Error(w, "policy needs capacity or refill_per_sec", http.StatusBadRequest)
+				return
+			}
+			rl.SetRoutePolicy(route, pol)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/admin/buckets", func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			http.Error(w, "key query parameter required", http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			rl.WatchBucket(key)
+			samples := rl.BucketSeries(key)
+			if samples == nil {
+				samples = []BucketSample{}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(struct {
+				Key     string         `json:"key"`
+				EveryMS int64          `json:"every_ms"`
+				Samples []BucketSample `json:"samples"`
+			}{key, rl.bucketSampleEvery().Milliseconds(), samples})
+		case http.MethodDelete:
+			rl.UnwatchBucket(key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/admin/traces", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		traces := rl.Traces(0)
+		if key := r.URL.Query().Get("key"); key != "" {
+			kept := traces[:0]
+			for _, tr := range traces {
+				if tr.Key == key {
+					kept = append(kept, tr)
+				}
+			}
+			traces = kept
+		}
+		if limit > 0 && len(traces) > limit {
+			traces = traces[:limit]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(traces)
+	})
+	mux.HandleFunc("/admin/mode", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			// fall through to the shared response below
+		case http.MethodPost:
+			var body struct {
+				Mode string `json:"mode"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "invalid body", http.StatusBadRequest)
+				return
+			}
+			m, ok := parseMode(body.Mode)
+			if !ok {
+				http.Error(w, "unknown mode: "+body.Mode, http.StatusBadRequest)
+				return
+			}
+			rl.SetMode(m)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"mode": rl.Mode().String()})
+	})
+	return mux
+}
@@ -0,0 +1,47 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminConfigExport(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     20,
+		RefillPerSec: 10,
+		Routes: map[string]Policy{
+			"/login": {Capacity: 5}, // refill inherited from default
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/admin/config", nil)
+	rec := httptest.NewRecorder()
+	rl.AdminHandler().ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got configExport
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Default.Capacity != 20 || got.Default.RefillPerSec != 10 {
+		t.Fatalf("default = %+v", got.Default)
+	}
+	login := got.Routes["/login"]
+	if login.Capacity != 5 || login.RefillPerSec != 10 {
+		t.Fatalf("merged /login policy = %+v, want capacity 5, refill 10", login)
+	}
+	if got.Extractor != "client_ip" {
+		t.Fatalf("extractor = %q", got.Extractor)
+	}
+}
+
+func TestEffectivePolicyFallsBackToDefault(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 7, RefillPerSec: 3})
+	p := rl.EffectivePolicy("/anything")
+	if p.Capacity != 7 || p.RefillPerSec != 3 {
+		t.Fatalf("policy = %+v", p)
+	}
+}
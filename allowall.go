@@ -0,0 +1,114 @@
+package signalfence
+
+import (
+	"math"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// AllowAll checks and consumes one token from every listed key's bucket
+// atomically, or from none — the classic user-AND-org-AND-global check.
+// Running the checks sequentially instead leaks tokens from the early
+// buckets whenever a later one declines. Each key resolves its own policy
+// (including escalations); the returned Decision carries the tightest
+// limit, the smallest remaining balance, and — on denial — the longest
+// wait among the buckets that declined. Bans on any key deny the whole set.
+func (rl *RateLimiter) AllowAll(keys []string) Decision {
+	version := rl.ConfigVersion()
+	mode := rl.Mode()
+	limit := 0
+
+	if mode == ModeBypass {
+		pol := rl.EffectivePolicy("")
+		return Decision{
+			Allowed:       true,
+			Limit:         pol.Capacity,
+			Remaining:     pol.Capacity,
+			ConfigVersion: version,
+			Mode:          mode,
+		}
+	}
+	for _, key := range keys {
+		if left, banned := rl.banRemaining(key); banned {
+			return observe(mode, Decision{
+				RetryAfter:    left,
+				ConfigVersion: version,
+				Banned:        true,
+				Mode:          mode,
+			})
+		}
+	}
+
+	buckets := make([]core.TokenBucket, len(keys))
+	for i, key := range keys {
+		pol := rl.EffectivePolicy("")
+		if escPol, ok := rl.escalated.get(key); ok {
+			pol = mergePolicy(escPol, pol)
+		}
+		pol = rl.clampPolicy(pol)
+		pol = rl.warmupPolicy(pol)
+		b := rl.bucketFor(key, pol)
+		b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+		buckets[i] = b
+		if limit == 0 || pol.Capacity < limit {
+			limit = pol.Capacity
+		}
+	}
+
+	ok, remaining, retryAfter := takeAll(buckets, rl.now())
+	if remaining < 0 {
+		remaining = 0
+	}
+	return observe(mode, Decision{
+		Allowed:       ok,
+		Limit:         limit,
+		Remaining:     int(remaining),
+		RetryAfter:    retryAfter,
+		ConfigVersion: version,
+		Mode:          mode,
+	})
+}
+
+// takeAll takes one token from every bucket or from none. When all buckets
+// are the concrete *core.Bucket — the built-in stores always hand those
+// out — core.TakeAll locks the set together for a truly atomic take.
+// Custom bucket implementations fall back to sequential takes with refunds
+// on failure, which leaves the same balances but can interleave with
+// concurrent takers.
+func takeAll(buckets []core.TokenBucket, now time.Time) (ok bool, minRemaining float64, retryAfter time.Duration) {
+	concrete := make([]*core.Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		cb, isConcrete := b.(*core.Bucket)
+		if !isConcrete {
+			concrete = nil
+			break
+		}
+		concrete = append(concrete, cb)
+	}
+	if concrete != nil {
+		return core.TakeAll(concrete, 1, now)
+	}
+
+	minRemaining = math.Inf(1)
+	taken := make([]core.TokenBucket, 0, len(buckets))
+	seen := make(map[core.TokenBucket]bool, len(buckets))
+	for _, b := range buckets {
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		bOK, remaining, retry := b.Take(1, now)
+		if remaining < minRemaining {
+			minRemaining = remaining
+		}
+		if !bOK {
+			for _, t := range taken {
+				t.ForceTake(-1, now)
+			}
+			return false, minRemaining, retry
+		}
+		taken = append(taken, b)
+	}
+	return true, minRemaining, 0
+}
@@ -0,0 +1,70 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowAllAtomicity(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001, Now: clock.Now})
+
+	// Drain the org bucket.
+	for i := 0; i < 10; i++ {
+		rl.Allow("org:acme")
+	}
+
+	keys := []string{"user:u1", "org:acme", "global"}
+	d := rl.AllowAll(keys)
+	if d.Allowed {
+		t.Fatal("set admitted with the org bucket empty")
+	}
+	if d.RetryAfter <= 0 {
+		t.Fatalf("denial has no retry hint: %+v", d)
+	}
+	// The denial must not have leaked tokens from the other buckets.
+	if got := rl.Peek("user:u1", "").Remaining; got != 10 {
+		t.Fatalf("user bucket leaked: remaining %d, want 10", got)
+	}
+	if got := rl.Peek("global", "").Remaining; got != 10 {
+		t.Fatalf("global bucket leaked: remaining %d, want 10", got)
+	}
+
+	// A successful take charges every bucket exactly once.
+	d = rl.AllowAll([]string{"user:u1", "global"})
+	if !d.Allowed || d.Remaining != 9 {
+		t.Fatalf("joint take = %+v, want allowed with 9 remaining", d)
+	}
+	if got := rl.Peek("user:u1", "").Remaining; got != 9 {
+		t.Fatalf("user bucket remaining %d, want 9", got)
+	}
+	if got := rl.Peek("global", "").Remaining; got != 9 {
+		t.Fatalf("global bucket remaining %d, want 9", got)
+	}
+}
+
+func TestAllowAllBans(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	rl.Ban("user:bad", time.Minute)
+
+	d := rl.AllowAll([]string{"user:bad", "global"})
+	if d.Allowed || !d.Banned {
+		t.Fatalf("set with banned member = %+v", d)
+	}
+	if got := rl.Peek("global", "").Remaining; got != 10 {
+		t.Fatalf("global bucket charged despite ban: %d", got)
+	}
+}
+
+func TestAllowAllDuplicateKeys(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001})
+
+	// The same key listed twice is locked and charged once.
+	d := rl.AllowAll([]string{"user:u1", "user:u1"})
+	if !d.Allowed {
+		t.Fatalf("duplicate-key take denied: %+v", d)
+	}
+	if got := rl.Peek("user:u1", "").Remaining; got != 9 {
+		t.Fatalf("duplicate key charged twice: remaining %d, want 9", got)
+	}
+}
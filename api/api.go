@@ -0,0 +1,324 @@
+// Package api implements the HTTP decision API served by cmd/server. A
+// caller POSTs a check describing the client and route and receives the
+// rate limit decision as JSON, so services in any language can share one
+// central limiter instead of each running their own.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// CheckRequest is the body of POST /check.
+type CheckRequest struct {
+	// ClientID identifies the subject being limited (an IP, user ID,
+	// API key hash — whatever the caller keys on).
+	ClientID string `json:"client_id"`
+	// Route selects a per-route policy; empty uses the default policy.
+	Route string `json:"route,omitempty"`
+	// Group makes the check draw from a bucket shared by every client_id
+	// tagged with the same group — for example all IPs of one tenant — so
+	// callers don't have to compute composite keys themselves. Groups are
+	// namespaced per service like client IDs.
+	Group string `json:"group,omitempty"`
+	// Policy names a policy previously registered via PUT /policies/{name};
+	// it cannot be combined with the inline override fields below.
+	Policy string `json:"policy,omitempty"`
+	// Capacity and RefillPerSec, when non-zero, override the configured
+	// policy for this client. Overridden clients are tracked in separate
+	// buckets from normally limited traffic.
+	Capacity     int     `json:"capacity,omitempty"`
+	RefillPerSec float64 `json:"refill_per_sec,omitempty"`
+	// Cost is how many tokens this check consumes; zero means 1. Expensive
+	// operations can charge more than one unit in a single atomic take.
+	Cost float64 `json:"cost,omitempty"`
+	// Peek reports the decision without consuming any tokens. Cost is
+	// ignored while peeking.
+	Peek bool `json:"peek,omitempty"`
+}
+
+// CheckResponse is the body returned for every check, allowed or not.
+type CheckResponse struct {
+	Allowed       bool  `json:"allowed"`
+	Limit         int   `json:"limit"`
+	Remaining     int   `json:"remaining"`
+	RetryAfterMS  int64 `json:"retry_after_ms,omitempty"`
+	ConfigVersion int   `json:"config_version"`
+	Warning       bool  `json:"warning,omitempty"`
+	Banned        bool  `json:"banned,omitempty"`
+	WouldBlock    bool  `json:"would_block,omitempty"`
+}
+
+// OverrideMode controls which callers may use the inline capacity/refill
+// override fields of CheckRequest. Overrides let a caller grant arbitrary
+// quota, so production deployments should restrict them.
+type OverrideMode int
+
+const (
+	// OverridesAll honors inline overrides from any caller (the default,
+	// matching the original behavior).
+	OverridesAll OverrideMode = iota
+	// OverridesAdmin honors inline overrides only from services listed in
+	// Handler.AdminServices.
+	OverridesAdmin
+	// OverridesOff rejects inline overrides from everyone; named policies
+	// are the only sanctioned way to vary limits per check.
+	OverridesOff
+)
+
+// ParseOverrideMode maps the flag spellings "all", "admin" and "off" to an
+// OverrideMode.
+func ParseOverrideMode(s string) (OverrideMode, bool) {
+	switch s {
+	case "all":
+		return OverridesAll, true
+	case "admin":
+		return OverridesAdmin, true
+	case "off":
+		return OverridesOff, true
+	}
+	return OverridesAll, false
+}
+
+// Handler serves the decision API for one limiter.
+type Handler struct {
+	limiter *signalfence.RateLimiter
+	mux     *http.ServeMux
+
+	// AccessLog, when set, records every decision the handler makes. Set it
+	// before serving.
+	AccessLog *signalfence.AccessLogger
+
+	// Metrics, when set, counts every decision and its handling time. Set
+	// it before serving; leave it nil when the limiter itself carries a
+	// sink via Config.Metrics, or decisions are counted twice.
+	Metrics signalfence.MetricsSink
+
+	// ServiceTokens maps static bearer tokens to service names. When
+	// non-empty, every check must carry "Authorization: Bearer <token>" and
+	// client IDs are namespaced under the service name, so one service can
+	// neither consume nor inspect another service's buckets. Registered
+	// policy names are namespaced the same way. Set it before serving.
+	ServiceTokens map[string]string
+
+	// Overrides gates the inline capacity/refill override fields; see
+	// OverrideMode. AdminServices lists the service names trusted with
+	// overrides under OverridesAdmin. Set both before serving.
+	Overrides     OverrideMode
+	AdminServices map[string]bool
+
+	// polMu guards policies, the named policies registered via
+	// PUT /policies/{name}.
+	polMu    sync.RWMutex
+	policies map[string]signalfence.Policy
+}
+
+// NewHandler returns a Handler answering POST /check against rl.
+func NewHandler(rl *signalfence.RateLimiter) *Handler {
+	h := &Handler{
+		limiter:  rl,
+		mux:      http.NewServeMux(),
+		policies: make(map[string]signalfence.Policy),
+	}
+	h.mux.HandleFunc("/check", h.check)
+	h.mux.HandleFunc("/policies/", h.policy)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// authService authenticates the caller when service tokens are configured
+// and returns the service name ("" when auth is disabled). On failure it
+// writes the error response and reports ok=false.
+func (h *Handler) authService(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if len(h.ServiceTokens) == 0 {
+		return "", true
+	}
+	auth := r.Header.Get("Authorization")
+	token, found := strings.CutPrefix(auth, "Bearer ")
+	if !found {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "bearer token required", http.StatusUnauthorized)
+		return "", false
+	}
+	service, known := h.ServiceTokens[token]
+	if !known {
+		http.Error(w, "unknown service token", http.StatusUnauthorized)
+		return "", false
+	}
+	return service, true
+}
+
+// namespaced prefixes name with the service when auth is enabled, so both
+// client IDs and policy names are isolated per calling service.
+func namespaced(service, name string) string {
+	if service == "" {
+		return name
+	}
+	return service + "/" + name
+}
+
+// policy handles PUT/GET/DELETE /policies/{name} for named policy
+// registration. A registered policy can then be referenced from checks by
+// name instead of repeating raw capacity/refill numbers on every call.
+func (h *Handler) policy(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/policies/")
+	if name == "" || strings.Contains(name, "/") {
+		http.Error(w, "policy name required", http.StatusBadRequest)
+		return
+	}
+	service, ok := h.authService(w, r)
+	if !ok {
+		return
+	}
+	name = namespaced(service, name)
+
+	switch r.Method {
+	case http.MethodPut:
+		var pol signalfence.Policy
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&pol); err != nil {
+			http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if pol.Capacity <= 0 && pol.RefillPerSec <= 0 {
+			http.Error(w, "policy needs capacity or refill_per_sec", http.StatusBadRequest)
+			return
+		}
+		h.polMu.Lock()
+		h.policies[name] = pol
+		h.polMu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		h.polMu.RLock()
+		pol, found := h.policies[name]
+		h.polMu.RUnlock()
+		if !found {
+			http.Error(w, "unknown policy", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pol)
+	case http.MethodDelete:
+		h.polMu.Lock()
+		delete(h.policies, name)
+		h.polMu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// overrideAllowed reports whether service may use inline overrides under
+// the configured OverrideMode.
+func (h *Handler) overrideAllowed(service string) bool {
+	switch h.Overrides {
+	case OverridesAdmin:
+		return h.AdminServices[service]
+	case OverridesOff:
+		return false
+	}
+	return true
+}
+
+func (h *Handler) check(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req CheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.ClientID == "" {
+		http.Error(w, "client_id is required", http.StatusBadRequest)
+		return
+	}
+	if req.Cost < 0 {
+		http.Error(w, "cost must not be negative", http.StatusBadRequest)
+		return
+	}
+	cost := req.Cost
+	if cost == 0 {
+		cost = 1
+	}
+	service, ok := h.authService(w, r)
+	if !ok {
+		return
+	}
+	key := namespaced(service, req.ClientID)
+	if req.Group != "" {
+		key = namespaced(service, "group:"+req.Group)
+	}
+
+	start := time.Now()
+	var d signalfence.Decision
+	switch {
+	case req.Policy != "":
+		if req.Capacity > 0 || req.RefillPerSec > 0 {
+			http.Error(w, "policy and inline override are mutually exclusive", http.StatusBadRequest)
+			return
+		}
+		h.polMu.RLock()
+		pol, found := h.policies[namespaced(service, req.Policy)]
+		h.polMu.RUnlock()
+		if !found {
+			http.Error(w, "unknown policy: "+req.Policy, http.StatusBadRequest)
+			return
+		}
+		// Each named policy gets its own bucket per client, so a client
+		// checked under "login" and "search" is tracked separately.
+		if req.Peek {
+			d = h.limiter.PeekPolicy(key+"#"+req.Policy, pol)
+		} else {
+			d = h.limiter.AllowPolicyCost(key+"#"+req.Policy, pol, cost)
+		}
+	case req.Capacity > 0 || req.RefillPerSec > 0:
+		if !h.overrideAllowed(service) {
+			http.Error(w, "inline overrides are not permitted; register a named policy instead", http.StatusForbidden)
+			return
+		}
+		pol := signalfence.Policy{Capacity: req.Capacity, RefillPerSec: req.RefillPerSec}
+		if req.Peek {
+			d = h.limiter.PeekPolicy(key, pol)
+		} else {
+			d = h.limiter.AllowPolicyCost(key, pol, cost)
+		}
+	case req.Peek:
+		d = h.limiter.Peek(key, req.Route)
+	default:
+		d = h.limiter.AllowCost(key, req.Route, cost)
+	}
+	if h.AccessLog != nil {
+		h.AccessLog.Log(key, req.Route, d, time.Since(start))
+	}
+	if h.Metrics != nil {
+		if d.Allowed {
+			h.Metrics.IncAllowed(req.Route)
+		} else {
+			h.Metrics.IncDenied(req.Route)
+		}
+		h.Metrics.ObserveLatency(req.Route, time.Since(start))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CheckResponse{
+		Allowed:       d.Allowed,
+		Limit:         d.Limit,
+		Remaining:     d.Remaining,
+		RetryAfterMS:  d.RetryAfter.Milliseconds(),
+		ConfigVersion: d.ConfigVersion,
+		Warning:       d.Warning,
+		Banned:        d.Banned,
+		WouldBlock:    d.WouldBlock,
+	})
+}
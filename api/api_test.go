@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/signalfence"
+)
+
+func doCheck(t *testing.T, h http.Handler, body string) (*httptest.ResponseRecorder, CheckResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/check", strings.NewReader(body))
+	h.ServeHTTP(rec, req)
+	var resp CheckResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("bad response body: %v", err)
+		}
+	}
+	return rec, resp
+}
+
+func TestCheckConsumesTokens(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 2, RefillPerSec: 0.001})
+	h := NewHandler(rl)
+
+	for i := 0; i < 2; i++ {
+		rec, resp := doCheck(t, h, `{"client_id": "alice"}`)
+		if rec.Code != http.StatusOK || !resp.Allowed {
+			t.Fatalf("request %d: code=%d allowed=%v", i, rec.Code, resp.Allowed)
+		}
+	}
+	rec, resp := doCheck(t, h, `{"client_id": "alice"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("denied check should still be 200, got %d", rec.Code)
+	}
+	if resp.Allowed {
+		t.Fatal("third request should be denied")
+	}
+	if resp.RetryAfterMS <= 0 {
+		t.Fatalf("denied response has no retry hint: %+v", resp)
+	}
+
+	// A different client is unaffected.
+	if _, resp := doCheck(t, h, `{"client_id": "bob"}`); !resp.Allowed {
+		t.Fatal("bob should not share alice's bucket")
+	}
+}
+
+func TestCheckPolicyOverride(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 1, RefillPerSec: 0.001})
+	h := NewHandler(rl)
+
+	_, resp := doCheck(t, h, `{"client_id": "c", "capacity": 5}`)
+	if !resp.Allowed || resp.Limit != 5 {
+		t.Fatalf("override not applied: %+v", resp)
+	}
+}
+
+func TestCheckServiceAuth(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 1, RefillPerSec: 0.001})
+	h := NewHandler(rl)
+	h.ServiceTokens = map[string]string{"tok-billing": "billing", "tok-search": "search"}
+
+	call := func(token string) (*httptest.ResponseRecorder, CheckResponse) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/check", strings.NewReader(`{"client_id": "u1"}`))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		h.ServeHTTP(rec, req)
+		var resp CheckResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	if rec, _ := call(""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: got %d, want 401", rec.Code)
+	}
+	if rec, _ := call("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token: got %d, want 401", rec.Code)
+	}
+
+	// Identical client IDs from different services hit different buckets.
+	if _, resp := call("tok-billing"); !resp.Allowed {
+		t.Fatal("billing's first request denied")
+	}
+	if _, resp := call("tok-billing"); resp.Allowed {
+		t.Fatal("billing's bucket should be exhausted")
+	}
+	if _, resp := call("tok-search"); !resp.Allowed {
+		t.Fatal("search must not share billing's bucket for u1")
+	}
+}
+
+func TestNamedPolicies(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 0.001})
+	h := NewHandler(rl)
+
+	put := httptest.NewRequest("PUT", "/policies/login", strings.NewReader(`{"capacity": 3, "refill_per_sec": 1}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, put)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("PUT policy: got %d, want 204", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/policies/login", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET policy: got %d", rec.Code)
+	}
+
+	_, resp := doCheck(t, h, `{"client_id": "u1", "policy": "login"}`)
+	if !resp.Allowed || resp.Limit != 3 {
+		t.Fatalf("policy check = %+v, want limit 3", resp)
+	}
+
+	// Unknown and conflicting references are rejected.
+	if rec, _ := doCheck(t, h, `{"client_id": "u1", "policy": "nope"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown policy: got %d, want 400", rec.Code)
+	}
+	if rec, _ := doCheck(t, h, `{"client_id": "u1", "policy": "login", "capacity": 9}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("policy + inline override: got %d, want 400", rec.Code)
+	}
+
+	// Empty policies cannot be registered.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", "/policies/empty", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty policy: got %d, want 400", rec.Code)
+	}
+}
+
+func TestNamedPoliciesAreServiceScoped(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 1})
+	h := NewHandler(rl)
+	h.ServiceTokens = map[string]string{"tok-billing": "billing", "tok-search": "search"}
+
+	put := httptest.NewRequest("PUT", "/policies/login", strings.NewReader(`{"capacity": 3, "refill_per_sec": 1}`))
+	put.Header.Set("Authorization", "Bearer tok-billing")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, put)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("PUT policy: got %d", rec.Code)
+	}
+
+	// billing sees its policy; search does not.
+	check := httptest.NewRequest("POST", "/check", strings.NewReader(`{"client_id": "u1", "policy": "login"}`))
+	check.Header.Set("Authorization", "Bearer tok-search")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, check)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("cross-service policy reference: got %d, want 400", rec.Code)
+	}
+}
+
+func TestOverrideGating(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 1, RefillPerSec: 1})
+	h := NewHandler(rl)
+	h.ServiceTokens = map[string]string{"tok-ops": "ops", "tok-web": "web"}
+	h.Overrides = OverridesAdmin
+	h.AdminServices = map[string]bool{"ops": true}
+
+	call := func(token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/check", strings.NewReader(`{"client_id": "u1", "capacity": 999}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call("tok-ops"); rec.Code != http.StatusOK {
+		t.Fatalf("admin override: got %d, want 200", rec.Code)
+	}
+	if rec := call("tok-web"); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin override: got %d, want 403", rec.Code)
+	}
+
+	h.Overrides = OverridesOff
+	if rec := call("tok-ops"); rec.Code != http.StatusForbidden {
+		t.Fatalf("override with overrides off: got %d, want 403", rec.Code)
+	}
+
+	// Checks without overrides are unaffected.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/check", strings.NewReader(`{"client_id": "u2"}`))
+	req.Header.Set("Authorization", "Bearer tok-web")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("plain check: got %d, want 200", rec.Code)
+	}
+}
+
+func TestCheckCostAndPeek(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 10, RefillPerSec: 0.001})
+	h := NewHandler(rl)
+
+	// Peeking never consumes.
+	for i := 0; i < 3; i++ {
+		if _, resp := doCheck(t, h, `{"client_id": "u1", "peek": true}`); !resp.Allowed || resp.Remaining != 10 {
+			t.Fatalf("peek %d consumed tokens: %+v", i, resp)
+		}
+	}
+
+	// A cost-7 take leaves 3 tokens; a second cost-7 take must not fit.
+	if _, resp := doCheck(t, h, `{"client_id": "u1", "cost": 7}`); !resp.Allowed || resp.Remaining != 3 {
+		t.Fatalf("cost take = %+v", resp)
+	}
+	if _, resp := doCheck(t, h, `{"client_id": "u1", "cost": 7}`); resp.Allowed {
+		t.Fatalf("second cost-7 take should be denied: %+v", resp)
+	}
+	// But a cost-3 take still fits — the denial consumed nothing.
+	if _, resp := doCheck(t, h, `{"client_id": "u1", "cost": 3}`); !resp.Allowed {
+		t.Fatalf("cost-3 take after denial = %+v", resp)
+	}
+
+	if rec, _ := doCheck(t, h, `{"client_id": "u1", "cost": -2}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative cost: got %d, want 400", rec.Code)
+	}
+}
+
+func TestCheckSharedGroups(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 2, RefillPerSec: 0.001})
+	h := NewHandler(rl)
+
+	// Two clients in the same group drain one shared bucket.
+	if _, resp := doCheck(t, h, `{"client_id": "ip-1", "group": "tenant-a"}`); !resp.Allowed {
+		t.Fatal("first group request denied")
+	}
+	if _, resp := doCheck(t, h, `{"client_id": "ip-2", "group": "tenant-a"}`); !resp.Allowed {
+		t.Fatal("second group request denied")
+	}
+	if _, resp := doCheck(t, h, `{"client_id": "ip-3", "group": "tenant-a"}`); resp.Allowed {
+		t.Fatal("shared group bucket should be exhausted")
+	}
+
+	// Other groups and ungrouped clients are unaffected.
+	if _, resp := doCheck(t, h, `{"client_id": "ip-1", "group": "tenant-b"}`); !resp.Allowed {
+		t.Fatal("tenant-b blocked by tenant-a's bucket")
+	}
+	if _, resp := doCheck(t, h, `{"client_id": "ip-1"}`); !resp.Allowed {
+		t.Fatal("ungrouped client blocked by group bucket")
+	}
+}
+
+func TestCheckRejectsBadRequests(t *testing.T) {
+	h := NewHandler(signalfence.NewRateLimiter(signalfence.Config{}))
+
+	if rec, _ := doCheck(t, h, `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing client_id: got %d, want 400", rec.Code)
+	}
+	if rec, _ := doCheck(t, h, `not json`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid body: got %d, want 400", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/check", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /check: got %d, want 405", rec.Code)
+	}
+}
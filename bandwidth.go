@@ -0,0 +1,114 @@
+package signalfence
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// MiddlewareBytes wraps next with byte-budget rate limiting: each request
+// consumes tokens equal to its body size, so the policy's Capacity and
+// RefillPerSec describe a byte budget (burst bytes and bytes per second)
+// rather than request counts. Upload endpoints want this; pair it with a
+// per-route policy so normal routes keep request-based limits.
+//
+// Requests that declare a Content-Length are admitted or rejected up front.
+// Requests without one (chunked uploads) are admitted while the bucket has
+// any headroom and charged for the bytes actually read as the handler
+// consumes the body; overshoot is carried as bucket debt that future refill
+// repays before the next upload is admitted.
+func (rl *RateLimiter) MiddlewareBytes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := rl.keyFunc(r)
+		route := rl.routeOf(r.URL.Path)
+
+		if r.ContentLength >= 0 {
+			cost := float64(r.ContentLength)
+			if cost == 0 {
+				cost = 1 // bodyless requests still count minimally
+			}
+			d := rl.AllowCost(key, route, cost)
+			writeLimitHeaders(w, d)
+			if !d.Allowed {
+				rl.reject(w, r, route, d)
+				rl.recordTraffic(key, false, declaredBytes(r), 0)
+				return
+			}
+			if rl.cfg.TrackTraffic {
+				cw := &byteCountWriter{ResponseWriter: w}
+				next.ServeHTTP(cw, r)
+				rl.traffic.record(key, true, declaredBytes(r), cw.n)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		d := rl.Peek(key, route)
+		writeLimitHeaders(w, d)
+		if !d.Allowed {
+			rl.reject(w, r, route, d)
+			rl.recordTraffic(key, false, 0, 0)
+			return
+		}
+		cr := &countingReader{rc: r.Body}
+		r.Body = cr
+		if rl.cfg.TrackTraffic {
+			cw := &byteCountWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+			read := cr.n.Load()
+			rl.charge(key, route, float64(read))
+			rl.traffic.record(key, true, uint64(read), cw.n)
+			return
+		}
+		next.ServeHTTP(w, r)
+		rl.charge(key, route, float64(cr.n.Load()))
+	})
+}
+
+// charge deducts cost tokens from the bucket serving key on route, even
+// into debt, mirroring allowRoute's bucket selection without the decision
+// bookkeeping. It backs after-the-fact byte accounting.
+func (rl *RateLimiter) charge(key, route string, cost float64) {
+	if cost <= 0 {
+		return
+	}
+	pol := rl.EffectivePolicy(route)
+	rl.mu.RLock()
+	_, overridden := rl.cfg.Routes[route]
+	can, hasCanary := rl.cfg.Canaries[route]
+	rl.mu.RUnlock()
+	bucketKey := key
+	if overridden {
+		bucketKey = key + "|" + route
+	}
+	if hasCanary && inCanary(key, can.Percent) {
+		pol = mergePolicy(can.Policy, pol)
+		bucketKey += "#canary"
+	}
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+	b := rl.bucketFor(bucketKey, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	b.ForceTake(cost, rl.now())
+}
+
+// countingReader counts the bytes the handler actually reads from a body of
+// unknown length.
+type countingReader struct {
+	rc io.ReadCloser
+	n  atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.rc.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	return c.rc.Close()
+}
@@ -0,0 +1,69 @@
+package signalfence
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postBytes(h http.Handler, n int, contentLength int64) *httptest.ResponseRecorder {
+	body := strings.Repeat("x", n)
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader(body))
+	req.RemoteAddr = "1.2.3.4:5"
+	req.ContentLength = contentLength
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareBytesContentLength(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10, Now: clock.Now})
+	h := rl.MiddlewareBytes(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	if rec := postBytes(h, 60, 60); rec.Code != http.StatusOK {
+		t.Fatalf("60-byte upload: got %d", rec.Code)
+	}
+	if rec := postBytes(h, 60, 60); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second 60-byte upload should exceed the byte budget, got %d", rec.Code)
+	}
+	if rec := postBytes(h, 30, 30); rec.Code != http.StatusOK {
+		t.Fatalf("30-byte upload should still fit, got %d", rec.Code)
+	}
+
+	// 10 bytes/sec refill: 5 virtual seconds buy another 50 bytes, leaving
+	// 60 in the bucket.
+	clock.Advance(5 * time.Second)
+	if rec := postBytes(h, 55, 55); rec.Code != http.StatusOK {
+		t.Fatalf("55-byte upload with 60 bytes available: got %d", rec.Code)
+	}
+	if rec := postBytes(h, 10, 10); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("10-byte upload with 5 bytes left: got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareBytesUnknownLength(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 1, Now: clock.Now})
+	h := rl.MiddlewareBytes(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+
+	// A chunked upload of 150 bytes is admitted on headroom and charged for
+	// what was read, leaving the bucket 50 bytes in debt.
+	if rec := postBytes(h, 150, -1); rec.Code != http.StatusOK {
+		t.Fatalf("chunked upload: got %d", rec.Code)
+	}
+	if rec := postBytes(h, 10, 10); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("bucket should be in debt after overshoot, got %d", rec.Code)
+	}
+
+	// Refill repays the debt; afterwards uploads pass again.
+	clock.Advance(60 * time.Second)
+	if rec := postBytes(h, 10, 10); rec.Code != http.StatusOK {
+		t.Fatalf("upload after debt repaid: got %d", rec.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package signalfence
+
+// AllowBatch checks and consumes one take per entry of costs for key
+// against the default policy. The policy is resolved once and the bucket
+// locked once for the whole batch, so a pipeline stage can admit a batch of
+// messages without paying the per-message synchronization cost. Denied
+// entries consume nothing and do not prevent later, smaller entries from
+// being admitted. Bans, escalations, clampdown, warm-up and the decision
+// mode apply to the batch as a whole.
+func (rl *RateLimiter) AllowBatch(key string, costs []float64) []Decision {
+	version := rl.ConfigVersion()
+	mode := rl.Mode()
+	pol := rl.EffectivePolicy("")
+	ds := make([]Decision, len(costs))
+
+	if mode == ModeBypass {
+		for i := range ds {
+			ds[i] = Decision{
+				Allowed:       true,
+				Limit:         pol.Capacity,
+				Remaining:     pol.Capacity,
+				ConfigVersion: version,
+				Mode:          mode,
+			}
+		}
+		return ds
+	}
+	if left, banned := rl.banRemaining(key); banned {
+		for i := range ds {
+			ds[i] = observe(mode, Decision{
+				Limit:         pol.Capacity,
+				RetryAfter:    left,
+				ConfigVersion: version,
+				Banned:        true,
+				Mode:          mode,
+			})
+		}
+		return ds
+	}
+
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+
+	b := rl.bucketFor(key, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	for i, r := range b.TakeBatch(costs, rl.now()) {
+		remaining := r.Remaining
+		if remaining < 0 {
+			remaining = 0
+		}
+		ds[i] = observe(mode, Decision{
+			Allowed:       r.OK,
+			Limit:         pol.Capacity,
+			Remaining:     int(remaining),
+			RetryAfter:    r.RetryAfter,
+			ConfigVersion: version,
+			Mode:          mode,
+		})
+	}
+	return ds
+}
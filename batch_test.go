@@ -0,0 +1,57 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowBatch(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1, Now: clock.Now})
+
+	ds := rl.AllowBatch("worker", []float64{4, 4, 4, 2})
+	want := []bool{true, true, false, true}
+	for i, d := range ds {
+		if d.Allowed != want[i] {
+			t.Fatalf("entry %d: allowed=%v, want %v (batch %+v)", i, d.Allowed, want[i], ds)
+		}
+	}
+	// 4+4+2 consumed; the denied 4 must not have leaked tokens.
+	if ds[3].Remaining != 0 {
+		t.Fatalf("remaining after batch = %d, want 0", ds[3].Remaining)
+	}
+	if ds[2].RetryAfter <= 0 {
+		t.Fatalf("denied entry has no retry hint: %+v", ds[2])
+	}
+
+	// The batch draws from the same bucket as Allow.
+	if d := rl.Allow("worker"); d.Allowed {
+		t.Fatal("bucket should be empty after the batch")
+	}
+	clock.Advance(2 * time.Second)
+	if ds := rl.AllowBatch("worker", []float64{1, 1, 1}); ds[1].Allowed != true || ds[2].Allowed {
+		t.Fatalf("after 2s refill want exactly 2 admitted, got %+v", ds)
+	}
+}
+
+func TestAllowBatchModes(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+
+	rl.Ban("bad", time.Minute)
+	for _, d := range rl.AllowBatch("bad", []float64{1, 1}) {
+		if d.Allowed || !d.Banned {
+			t.Fatalf("banned batch entry = %+v", d)
+		}
+	}
+
+	rl.SetMode(ModeObserve)
+	ds := rl.AllowBatch("worker", []float64{1, 1, 1})
+	for i, d := range ds {
+		if !d.Allowed {
+			t.Fatalf("observe mode blocked entry %d", i)
+		}
+	}
+	if !ds[1].WouldBlock || !ds[2].WouldBlock {
+		t.Fatalf("observe mode lost would-block marks: %+v", ds)
+	}
+}
@@ -0,0 +1,45 @@
+package signalfence
+
+import "testing"
+
+func BenchmarkAllow(b *testing.B) {
+	rl := NewRateLimiter(Config{Capacity: 1 << 30, RefillPerSec: 1 << 20})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rl.Allow("bench-key")
+	}
+}
+
+func BenchmarkAllowFast(b *testing.B) {
+	rl := NewRateLimiter(Config{Capacity: 1 << 30, RefillPerSec: 1 << 20})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rl.AllowFast("bench-key")
+	}
+}
+
+func TestAllowFastMatchesAllow(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 3, RefillPerSec: 0.001})
+	for i := 0; i < 3; i++ {
+		if !rl.AllowFast("k") {
+			t.Fatalf("take %d: want admission", i)
+		}
+	}
+	if rl.AllowFast("k") {
+		t.Fatal("4th take: want rejection")
+	}
+}
+
+func TestAllowFastHonorsBansAndMode(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10})
+	rl.Ban("bad", DefaultTrapBanDuration)
+	if rl.AllowFast("bad") {
+		t.Fatal("banned key admitted")
+	}
+	rl.SetMode(ModeObserve)
+	if !rl.AllowFast("bad") {
+		t.Fatal("observe mode must not block")
+	}
+}
@@ -0,0 +1,216 @@
+package signalfence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBlocklistRefresh is how often BlocklistLoader re-reads its source
+// when Every is zero.
+const DefaultBlocklistRefresh = 5 * time.Minute
+
+// blockSet is the imported blocklist: exact keys plus CIDR ranges matched
+// against IP-shaped keys. It is replaced wholesale on refresh so entries
+// removed upstream stop matching.
+type blockSet struct {
+	mu   sync.RWMutex
+	keys map[string]struct{}
+	nets []*net.IPNet
+}
+
+// match reports whether key is blocklisted, either exactly or — when the
+// key is an IP, bare or after an extractor prefix like "ip:" — by CIDR.
+func (s *blockSet) match(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.keys[key]; ok {
+		return true
+	}
+	if len(s.nets) == 0 {
+		return false
+	}
+	ip := net.ParseIP(key)
+	if ip == nil {
+		if _, rest, ok := strings.Cut(key, ":"); ok {
+			ip = net.ParseIP(rest)
+		}
+	}
+	if ip == nil {
+		return false
+	}
+	for _, n := range s.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBlocklist replaces the imported blocklist. Entries containing a "/"
+// are parsed as CIDR ranges and matched against IP-shaped keys; everything
+// else is an exact key. Matching keys are denied like banned ones, but the
+// list is separate from Ban state: Unban and Forget do not remove entries,
+// only the next SetBlocklist does.
+func (rl *RateLimiter) SetBlocklist(entries []string) error {
+	keys := make(map[string]struct{})
+	var nets []*net.IPNet
+	for _, e := range entries {
+		if strings.Contains(e, "/") {
+			_, n, err := net.ParseCIDR(e)
+			if err != nil {
+				return fmt.Errorf("blocklist entry %q: %w", e, err)
+			}
+			nets = append(nets, n)
+			continue
+		}
+		keys[e] = struct{}{}
+	}
+	rl.blocked.mu.Lock()
+	rl.blocked.keys = keys
+	rl.blocked.nets = nets
+	rl.blocked.mu.Unlock()
+	return nil
+}
+
+// Blocklisted reports whether key matches the imported blocklist.
+func (rl *RateLimiter) Blocklisted(key string) bool {
+	return rl.blocked.match(key)
+}
+
+// BlocklistLoader pulls a shared blocklist from a file or URL on an
+// interval and feeds it into SetBlocklist, so every service consumes the
+// org-wide list automatically. The format is one entry per line — exact
+// keys or CIDR ranges — with blank lines and "#" comments ignored. URL
+// fetches send If-None-Match, so an unchanged list costs a 304; file reads
+// are skipped while the modification time stands still. An object store
+// works through its HTTPS URL (e.g. a presigned or public S3 object). The
+// zero value is not usable; exactly one of Path or URL is required.
+type BlocklistLoader struct {
+	// Path is a local file holding the list.
+	Path string
+	// URL is an HTTP(S) location holding the list.
+	URL string
+	// Every is the refresh interval. Zero means DefaultBlocklistRefresh.
+	Every time.Duration
+	// HTTPClient overrides http.DefaultClient for URL fetches.
+	HTTPClient *http.Client
+	// OnError, when set, is called for fetch and parse failures. The
+	// previous list stays in effect until a refresh succeeds.
+	OnError func(error)
+
+	etag    string
+	modTime time.Time
+}
+
+// Load reads the source once, reporting the entries and whether they
+// changed since the previous Load.
+func (l *BlocklistLoader) Load(ctx context.Context) ([]string, bool, error) {
+	if l.Path != "" {
+		return l.loadFile()
+	}
+	return l.loadURL(ctx)
+}
+
+func (l *BlocklistLoader) loadFile() ([]string, bool, error) {
+	info, err := os.Stat(l.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !l.modTime.IsZero() && info.ModTime().Equal(l.modTime) {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(l.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	l.modTime = info.ModTime()
+	return parseBlocklist(data), true, nil
+}
+
+func (l *BlocklistLoader) loadURL(ctx context.Context) ([]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if l.etag != "" {
+		req.Header.Set("If-None-Match", l.etag)
+	}
+	client := l.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("blocklist %s: status %d", l.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	l.etag = resp.Header.Get("Etag")
+	return parseBlocklist(data), true, nil
+}
+
+// parseBlocklist splits the line-oriented list format.
+func parseBlocklist(data []byte) []string {
+	var entries []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries
+}
+
+// refresh performs one Load-and-apply cycle.
+func (l *BlocklistLoader) refresh(ctx context.Context, rl *RateLimiter) {
+	entries, changed, err := l.Load(ctx)
+	if err == nil && changed {
+		err = rl.SetBlocklist(entries)
+	}
+	if err != nil && l.OnError != nil {
+		l.OnError(err)
+	}
+}
+
+// Start loads the list once immediately, then keeps it fresh on the
+// configured interval under the limiter's worker supervision (see Health,
+// worker "blocklist") until the limiter is closed.
+func (l *BlocklistLoader) Start(rl *RateLimiter) {
+	every := l.Every
+	if every <= 0 {
+		every = DefaultBlocklistRefresh
+	}
+	rl.Go("blocklist", func(ctx context.Context) {
+		l.refresh(ctx, rl)
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				l.refresh(ctx, rl)
+			}
+		}
+	})
+}
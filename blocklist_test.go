@@ -0,0 +1,130 @@
+package signalfence
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetBlocklistMatchesKeysAndCIDRs(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	if err := rl.SetBlocklist([]string{"user:evil", "10.9.0.0/16"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rl.Blocklisted("user:evil") {
+		t.Fatal("exact key not matched")
+	}
+	if !rl.Blocklisted("10.9.4.4") {
+		t.Fatal("bare IP inside CIDR not matched")
+	}
+	if !rl.Blocklisted("ip:10.9.4.4") {
+		t.Fatal("prefixed IP inside CIDR not matched")
+	}
+	if rl.Blocklisted("10.10.0.1") || rl.Blocklisted("user:fine") {
+		t.Fatal("unlisted key matched")
+	}
+
+	// Blocklisted keys are denied like bans.
+	if d := rl.Allow("10.9.4.4"); d.Allowed || !d.Banned {
+		t.Fatalf("blocklisted decision = %+v", d)
+	}
+	if !rl.Allow("10.10.0.1").Allowed {
+		t.Fatal("clean key denied")
+	}
+
+	// A refresh replaces the list; dropped entries stop matching.
+	if err := rl.SetBlocklist([]string{"user:other"}); err != nil {
+		t.Fatal(err)
+	}
+	if rl.Blocklisted("user:evil") || rl.Blocklisted("10.9.4.4") {
+		t.Fatal("stale entries survived refresh")
+	}
+}
+
+func TestSetBlocklistRejectsBadCIDR(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	if err := rl.SetBlocklist([]string{"10.0.0.0/99"}); err == nil {
+		t.Fatal("bad CIDR accepted")
+	}
+}
+
+func TestBlocklistLoaderFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	write := func(body string) {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("# org blocklist\nuser:evil\n\n10.9.0.0/16\n")
+
+	l := &BlocklistLoader{Path: path}
+	entries, changed, err := l.Load(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Load = %v, changed %v", err, changed)
+	}
+	if len(entries) != 2 || entries[0] != "user:evil" || entries[1] != "10.9.0.0/16" {
+		t.Fatalf("entries = %v", entries)
+	}
+
+	// Unchanged mtime short-circuits.
+	if _, changed, err = l.Load(context.Background()); err != nil || changed {
+		t.Fatalf("unchanged file: changed %v, err %v", changed, err)
+	}
+
+	write("user:other\n")
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	entries, changed, err = l.Load(context.Background())
+	if err != nil || !changed || len(entries) != 1 || entries[0] != "user:other" {
+		t.Fatalf("after rewrite: entries %v, changed %v, err %v", entries, changed, err)
+	}
+}
+
+func TestBlocklistLoaderURLHonorsETag(t *testing.T) {
+	var fetches, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Etag", `"v1"`)
+		w.Write([]byte("user:evil\n"))
+	}))
+	defer srv.Close()
+
+	l := &BlocklistLoader{URL: srv.URL}
+	entries, changed, err := l.Load(context.Background())
+	if err != nil || !changed || len(entries) != 1 {
+		t.Fatalf("first load: entries %v, changed %v, err %v", entries, changed, err)
+	}
+	if _, changed, err = l.Load(context.Background()); err != nil || changed {
+		t.Fatalf("second load: changed %v, err %v", changed, err)
+	}
+	if fetches != 2 || notModified != 1 {
+		t.Fatalf("fetches = %d, 304s = %d", fetches, notModified)
+	}
+}
+
+func TestBlocklistLoaderStartApplies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user:evil\n"))
+	}))
+	defer srv.Close()
+
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	defer rl.Close()
+	(&BlocklistLoader{URL: srv.URL, Every: time.Hour}).Start(rl)
+
+	waitFor(t, func() bool { return rl.Blocklisted("user:evil") })
+	if _, ok := rl.Health()["blocklist"]; !ok {
+		t.Fatal("loader not supervised")
+	}
+}
@@ -0,0 +1,143 @@
+package signalfence
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for bucket sampling when the Config knobs are zero.
+const (
+	DefaultBucketSampleEvery   = time.Second
+	DefaultBucketSampleHistory = 300
+)
+
+// BucketSample is one tokens-remaining observation of a watched bucket.
+type BucketSample struct {
+	At        time.Time `json:"at"`
+	Remaining float64   `json:"remaining"`
+	Capacity  float64   `json:"capacity"`
+}
+
+// bucketSeries is a fixed-size ring of samples for one key: the dashboard
+// wants a bounded drain/refill window, not unbounded history.
+type bucketSeries struct {
+	mu      sync.Mutex
+	samples []BucketSample
+	next    int
+	full    bool
+}
+
+func newBucketSeries(history int) *bucketSeries {
+	return &bucketSeries{samples: make([]BucketSample, history)}
+}
+
+func (s *bucketSeries) add(sample BucketSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples[s.next] = sample
+	s.next++
+	if s.next == len(s.samples) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+// snapshot returns the recorded samples oldest first.
+func (s *bucketSeries) snapshot() []BucketSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		out := make([]BucketSample, s.next)
+		copy(out, s.samples[:s.next])
+		return out
+	}
+	out := make([]BucketSample, 0, len(s.samples))
+	out = append(out, s.samples[s.next:]...)
+	out = append(out, s.samples[:s.next]...)
+	return out
+}
+
+// bucketSampler holds the watched keys and their rings; the sampling worker
+// starts lazily with the first watch.
+type bucketSampler struct {
+	keys    sync.Map // key string -> *bucketSeries
+	started atomic.Bool
+}
+
+// WatchBucket starts sampling key's tokens-remaining on the configured
+// interval (Config.BucketSampleEvery) into a ring of
+// Config.BucketSampleHistory samples, retrievable via BucketSeries. The
+// first watch starts the sampling worker (see Health, worker
+// "bucket-sampler"); watching an already-watched key is a no-op that keeps
+// its history.
+func (rl *RateLimiter) WatchBucket(key string) {
+	rl.sampler.keys.LoadOrStore(key, newBucketSeries(rl.bucketSampleHistory()))
+	if rl.sampler.started.CompareAndSwap(false, true) {
+		every := rl.bucketSampleEvery()
+		rl.Go("bucket-sampler", func(ctx context.Context) {
+			ticker := time.NewTicker(every)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					rl.sampleBuckets()
+				}
+			}
+		})
+	}
+}
+
+// UnwatchBucket stops sampling key and drops its history.
+func (rl *RateLimiter) UnwatchBucket(key string) {
+	rl.sampler.keys.Delete(key)
+}
+
+// BucketSeries returns the recorded samples for a watched key, oldest
+// first. An unwatched key returns nil; a watched key whose bucket has not
+// been created yet returns an empty series.
+func (rl *RateLimiter) BucketSeries(key string) []BucketSample {
+	v, ok := rl.sampler.keys.Load(key)
+	if !ok {
+		return nil
+	}
+	return v.(*bucketSeries).snapshot()
+}
+
+// sampleBuckets takes one observation of every watched key. Keys without a
+// bucket yet are skipped rather than recorded as full, so the series only
+// shows real bucket state.
+func (rl *RateLimiter) sampleBuckets() {
+	now := rl.now()
+	rl.sampler.keys.Range(func(k, v any) bool {
+		if b, ok := rl.store.Load(k.(string)); ok {
+			v.(*bucketSeries).add(BucketSample{
+				At:        now,
+				Remaining: b.Peek(now),
+				Capacity:  b.Capacity(),
+			})
+		}
+		return true
+	})
+}
+
+func (rl *RateLimiter) bucketSampleEvery() time.Duration {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	if rl.cfg.BucketSampleEvery > 0 {
+		return rl.cfg.BucketSampleEvery
+	}
+	return DefaultBucketSampleEvery
+}
+
+func (rl *RateLimiter) bucketSampleHistory() int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	if rl.cfg.BucketSampleHistory > 0 {
+		return rl.cfg.BucketSampleHistory
+	}
+	return DefaultBucketSampleHistory
+}
@@ -0,0 +1,100 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBucketSeriesRingWraps(t *testing.T) {
+	s := newBucketSeries(3)
+	for i := 1; i <= 5; i++ {
+		s.add(BucketSample{Remaining: float64(i)})
+	}
+	got := s.snapshot()
+	if len(got) != 3 {
+		t.Fatalf("len = %d, want 3", len(got))
+	}
+	for i, want := range []float64{3, 4, 5} {
+		if got[i].Remaining != want {
+			t.Fatalf("sample %d = %v, want %v (oldest first)", i, got[i].Remaining, want)
+		}
+	}
+}
+
+func TestWatchBucketSamples(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:          10,
+		RefillPerSec:      1,
+		BucketSampleEvery: 5 * time.Millisecond,
+	})
+	defer rl.Close()
+
+	rl.Allow("k") // create the bucket with one token consumed
+	rl.WatchBucket("k")
+
+	waitFor(t, func() bool { return len(rl.BucketSeries("k")) >= 2 })
+	samples := rl.BucketSeries("k")
+	last := samples[len(samples)-1]
+	if last.Capacity != 10 {
+		t.Fatalf("Capacity = %v", last.Capacity)
+	}
+	if last.Remaining < 0 || last.Remaining > 10 {
+		t.Fatalf("Remaining = %v", last.Remaining)
+	}
+	if rl.BucketSeries("unwatched") != nil {
+		t.Fatal("unwatched key returned a series")
+	}
+
+	rl.UnwatchBucket("k")
+	if rl.BucketSeries("k") != nil {
+		t.Fatal("unwatched key kept its series")
+	}
+}
+
+func TestAdminBucketsEndpoint(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:          10,
+		RefillPerSec:      1,
+		BucketSampleEvery: 5 * time.Millisecond,
+	})
+	defer rl.Close()
+	h := rl.AdminHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/buckets", nil))
+	if w.Code != 400 {
+		t.Fatalf("missing key: status = %d", w.Code)
+	}
+
+	rl.Allow("k")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/buckets?key=k", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var body struct {
+		Key     string         `json:"key"`
+		EveryMS int64          `json:"every_ms"`
+		Samples []BucketSample `json:"samples"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Key != "k" || body.EveryMS != 5 || body.Samples == nil {
+		t.Fatalf("body = %+v", body)
+	}
+
+	// The first GET started sampling; later reads see data.
+	waitFor(t, func() bool { return len(rl.BucketSeries("k")) >= 1 })
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/buckets?key=k", nil))
+	if w.Code != 204 {
+		t.Fatalf("delete: status = %d", w.Code)
+	}
+	if rl.BucketSeries("k") != nil {
+		t.Fatal("series survived DELETE")
+	}
+}
@@ -0,0 +1,151 @@
+package signalfence
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yourusername/signalfence/store"
+)
+
+// ConfigBuilder assembles a Config fluently. It exists purely for
+// discoverability: the Config struct spreads its knobs across doc
+// sections, and a chain of methods surfaces them in one place under
+// editor completion. Builder() starts a chain and Build() validates and
+// constructs the limiter:
+//
+//	rl, err := signalfence.Builder().
+//		Capacity(100).
+//		Refill(10).
+//		KeyBy(signalfence.ExtractIP).
+//		Route("/search", signalfence.Policy{Capacity: 5, RefillPerSec: 1}).
+//		Build()
+type ConfigBuilder struct {
+	cfg  Config
+	mode Mode
+}
+
+// Builder starts a fluent configuration chain.
+func Builder() *ConfigBuilder {
+	return &ConfigBuilder{mode: ModeEnforce}
+}
+
+// Capacity sets the default policy's burst size.
+func (b *ConfigBuilder) Capacity(n int) *ConfigBuilder {
+	b.cfg.Capacity = n
+	return b
+}
+
+// Refill sets the default policy's refill rate in tokens per second.
+func (b *ConfigBuilder) Refill(perSec float64) *ConfigBuilder {
+	b.cfg.RefillPerSec = perSec
+	return b
+}
+
+// WarnAt sets the warning threshold as a fraction of capacity.
+func (b *ConfigBuilder) WarnAt(threshold float64) *ConfigBuilder {
+	b.cfg.WarnThreshold = threshold
+	return b
+}
+
+// Grace sets the negative-token allowance.
+func (b *ConfigBuilder) Grace(tokens int) *ConfigBuilder {
+	b.cfg.Grace = tokens
+	return b
+}
+
+// KeyBy sets the request key extractor.
+func (b *ConfigBuilder) KeyBy(fn func(*http.Request) string) *ConfigBuilder {
+	b.cfg.KeyFunc = fn
+	return b
+}
+
+// Route adds a per-route policy override.
+func (b *ConfigBuilder) Route(path string, pol Policy) *ConfigBuilder {
+	if b.cfg.Routes == nil {
+		b.cfg.Routes = make(map[string]Policy)
+	}
+	b.cfg.Routes[path] = pol
+	return b
+}
+
+// Canary adds a percentage rollout of a candidate policy for route.
+func (b *ConfigBuilder) Canary(route string, percent int, pol Policy) *ConfigBuilder {
+	if b.cfg.Canaries == nil {
+		b.cfg.Canaries = make(map[string]Canary)
+	}
+	b.cfg.Canaries[route] = Canary{Percent: percent, Policy: pol}
+	return b
+}
+
+// Shadow adds a policy evaluated in shadow mode for route.
+func (b *ConfigBuilder) Shadow(route string, pol Policy) *ConfigBuilder {
+	if b.cfg.Shadows == nil {
+		b.cfg.Shadows = make(map[string]Policy)
+	}
+	b.cfg.Shadows[route] = pol
+	return b
+}
+
+// Store sets the bucket storage backend.
+func (b *ConfigBuilder) Store(s store.Store) *ConfigBuilder {
+	b.cfg.Store = s
+	return b
+}
+
+// Now sets the time source, for virtual-time tests.
+func (b *ConfigBuilder) Now(fn func() time.Time) *ConfigBuilder {
+	b.cfg.Now = fn
+	return b
+}
+
+// TrackTraffic enables per-key request and byte accounting.
+func (b *ConfigBuilder) TrackTraffic() *ConfigBuilder {
+	b.cfg.TrackTraffic = true
+	return b
+}
+
+// ProblemJSON answers denials with RFC 9457 problem documents.
+func (b *ConfigBuilder) ProblemJSON() *ConfigBuilder {
+	b.cfg.ProblemJSON = true
+	return b
+}
+
+// BypassKey registers an HMAC secret for signed bypass tokens.
+func (b *ConfigBuilder) BypassKey(id string, secret []byte) *ConfigBuilder {
+	if b.cfg.BypassKeys == nil {
+		b.cfg.BypassKeys = make(map[string][]byte)
+	}
+	b.cfg.BypassKeys[id] = secret
+	return b
+}
+
+// OnWarning sets the warn-threshold hook.
+func (b *ConfigBuilder) OnWarning(fn func(key, route string, d Decision)) *ConfigBuilder {
+	b.cfg.OnWarning = fn
+	return b
+}
+
+// Mode sets the starting decision mode.
+func (b *ConfigBuilder) Mode(m Mode) *ConfigBuilder {
+	b.mode = m
+	return b
+}
+
+// Config returns the assembled Config without constructing a limiter, for
+// callers that want to tweak fields the builder does not cover.
+func (b *ConfigBuilder) Config() Config {
+	return b.cfg
+}
+
+// Build validates the assembled config and constructs the limiter. Unlike
+// NewRateLimiter it fails loudly on misconfiguration (see Config.Validate).
+func (b *ConfigBuilder) Build() (*RateLimiter, error) {
+	if err := b.cfg.Validate(); err != nil {
+		return nil, err
+	}
+	rl := NewRateLimiter(b.cfg)
+	if b.mode != ModeEnforce {
+		rl.SetMode(b.mode)
+	}
+	return rl, nil
+}
@@ -0,0 +1,46 @@
+package signalfence
+
+import (
+	"testing"
+)
+
+func TestBuilderBuildsWorkingLimiter(t *testing.T) {
+	clock := newFakeClock()
+	rl, err := Builder().
+		Capacity(2).
+		Refill(1).
+		WarnAt(0.5).
+		Route("/search", Policy{Capacity: 1, RefillPerSec: 1}).
+		Now(clock.Now).
+		Build()
+	if err != nil {
+		t.Fatalf("Build = %v", err)
+	}
+
+	if pol := rl.EffectivePolicy(""); pol.Capacity != 2 || pol.WarnThreshold != 0.5 {
+		t.Fatalf("default policy = %+v", pol)
+	}
+	if pol := rl.EffectivePolicy("/search"); pol.Capacity != 1 {
+		t.Fatalf("route policy = %+v", pol)
+	}
+	rl.AllowRoute("user:a", "/search")
+	if d := rl.AllowRoute("user:a", "/search"); d.Allowed {
+		t.Fatalf("route override not enforced: %+v", d)
+	}
+}
+
+func TestBuilderMode(t *testing.T) {
+	rl, err := Builder().Capacity(10).Refill(1).Mode(ModeObserve).Build()
+	if err != nil {
+		t.Fatalf("Build = %v", err)
+	}
+	if rl.Mode() != ModeObserve {
+		t.Fatalf("mode = %v, want observe", rl.Mode())
+	}
+}
+
+func TestBuilderRejectsBadConfig(t *testing.T) {
+	if _, err := Builder().Capacity(-5).Build(); err == nil {
+		t.Fatal("negative capacity built cleanly")
+	}
+}
@@ -0,0 +1,58 @@
+package signalfence
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BypassHeader is the header checked for a signed exemption token.
+const BypassHeader = "X-SignalFence-Bypass"
+
+// SignBypass mints a bypass token signed with secret under keyID, valid
+// until expiry. Internal tools present the token in BypassHeader to skip
+// rate limiting entirely — a much finer-grained exemption than allowlisting
+// whole IP ranges. Keep expiries short; tokens cannot be revoked before
+// they expire other than by removing the signing key.
+//
+// The token format is "keyID:expiryUnix:signature" where the signature is
+// base64url(HMAC-SHA256("keyID:expiryUnix", secret)).
+func SignBypass(keyID string, secret []byte, expiry time.Time) string {
+	payload := keyID + ":" + strconv.FormatInt(expiry.Unix(), 10)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return payload + ":" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyBypass checks token against the configured bypass keys. It returns
+// the signing key's ID when the token is authentic and unexpired.
+func (rl *RateLimiter) verifyBypass(token string) (string, bool) {
+	parts := strings.SplitN(token, ":", 3)
+	if len(parts) != 3 {
+		return "", false
+	}
+	keyID, expiryStr, sig := parts[0], parts[1], parts[2]
+
+	rl.mu.RLock()
+	secret, ok := rl.cfg.BypassKeys[keyID]
+	rl.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	expiry, err := strconv.ParseInt(expiryStr, 10, 64)
+	if err != nil || time.Now().Unix() > expiry {
+		return "", false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(keyID + ":" + expiryStr))
+	want, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(want, mac.Sum(nil)) {
+		return "", false
+	}
+	return keyID, true
+}
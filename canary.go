@@ -0,0 +1,100 @@
+package signalfence
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// Cohort labels for canary rollouts.
+const (
+	// CohortCanary marks keys that hash into the canary percentage and are
+	// enforced under the canary policy.
+	CohortCanary = "canary"
+	// CohortStable marks keys that stay on the active policy.
+	CohortStable = "stable"
+)
+
+// Canary rolls out a candidate policy to a deterministic percentage of keys
+// on a route. Cohort membership is decided by hashing the key, so a given
+// client always lands in the same cohort while the percentage is unchanged.
+type Canary struct {
+	// Policy is enforced for keys inside the rollout. Zero fields inherit
+	// from the route's active policy.
+	Policy Policy `json:"policy"`
+	// Percent is the share of keys (0-100) placed in the canary cohort.
+	Percent int `json:"percent"`
+}
+
+// CohortStats counts decisions per rollout cohort for one route, so the
+// impact of a canary policy can be measured before full enforcement.
+type CohortStats struct {
+	CanaryAllowed uint64 `json:"canary_allowed"`
+	CanaryBlocked uint64 `json:"canary_blocked"`
+	StableAllowed uint64 `json:"stable_allowed"`
+	StableBlocked uint64 `json:"stable_blocked"`
+}
+
+// cohortCounters is the internal atomic form of CohortStats.
+type cohortCounters struct {
+	canaryAllowed atomic.Uint64
+	canaryBlocked atomic.Uint64
+	stableAllowed atomic.Uint64
+	stableBlocked atomic.Uint64
+}
+
+// canaryStats lazily tracks per-route cohort counters.
+type canaryStats struct {
+	routes sync.Map // route string -> *cohortCounters
+}
+
+func (cs *canaryStats) record(route, cohort string, allowed bool) {
+	v, ok := cs.routes.Load(route)
+	if !ok {
+		v, _ = cs.routes.LoadOrStore(route, &cohortCounters{})
+	}
+	c := v.(*cohortCounters)
+	switch {
+	case cohort == CohortCanary && allowed:
+		c.canaryAllowed.Add(1)
+	case cohort == CohortCanary:
+		c.canaryBlocked.Add(1)
+	case allowed:
+		c.stableAllowed.Add(1)
+	default:
+		c.stableBlocked.Add(1)
+	}
+}
+
+func (cs *canaryStats) snapshot(route string) CohortStats {
+	v, ok := cs.routes.Load(route)
+	if !ok {
+		return CohortStats{}
+	}
+	c := v.(*cohortCounters)
+	return CohortStats{
+		CanaryAllowed: c.canaryAllowed.Load(),
+		CanaryBlocked: c.canaryBlocked.Load(),
+		StableAllowed: c.stableAllowed.Load(),
+		StableBlocked: c.stableBlocked.Load(),
+	}
+}
+
+// inCanary reports whether key hashes into the first percent buckets of a
+// 100-way consistent split.
+func inCanary(key string, percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32()%100) < percent
+}
+
+// CanaryStats returns the accumulated per-cohort decision counts for route.
+func (rl *RateLimiter) CanaryStats(route string) CohortStats {
+	return rl.cohorts.snapshot(route)
+}
@@ -0,0 +1,60 @@
+package signalfence
+
+import "testing"
+
+func TestCanaryCohortIsDeterministic(t *testing.T) {
+	for _, key := range []string{"a", "b", "client-42", "10.0.0.1"} {
+		first := inCanary(key, 50)
+		for i := 0; i < 5; i++ {
+			if inCanary(key, 50) != first {
+				t.Fatalf("cohort for %q changed between calls", key)
+			}
+		}
+	}
+	if inCanary("anything", 0) {
+		t.Fatal("0%% rollout should have no canary members")
+	}
+	if !inCanary("anything", 100) {
+		t.Fatal("100%% rollout should include every key")
+	}
+}
+
+func TestCanaryEnforcesCandidatePolicy(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 10,
+		Canaries: map[string]Canary{
+			"": {Policy: Policy{Capacity: 1}, Percent: 100},
+		},
+	})
+
+	first := rl.Allow("k")
+	if !first.Allowed || first.Cohort != CohortCanary || first.Limit != 1 {
+		t.Fatalf("first = %+v", first)
+	}
+	if d := rl.Allow("k"); d.Allowed {
+		t.Fatalf("second request should be blocked by canary capacity 1, got %+v", d)
+	}
+
+	stats := rl.CanaryStats("")
+	if stats.CanaryAllowed != 1 || stats.CanaryBlocked != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestStableCohortStaysOnActivePolicy(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 10,
+		Canaries: map[string]Canary{
+			"": {Policy: Policy{Capacity: 1}, Percent: 0},
+		},
+	})
+	d := rl.Allow("k")
+	if d.Cohort != CohortStable || d.Limit != 100 {
+		t.Fatalf("decision = %+v", d)
+	}
+	if stats := rl.CanaryStats(""); stats.StableAllowed != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,47 @@
+package signalfence
+
+import "net/http"
+
+// reject answers a blocked request: keys that are over limit but not
+// banned get the configured challenge response when one is set, everything
+// else gets the plain denial. Banned keys never see a challenge — solving
+// a CAPTCHA should not shortcut a blocklist entry.
+func (rl *RateLimiter) reject(w http.ResponseWriter, r *http.Request, route string, d Decision) {
+	rl.mu.RLock()
+	challenge := rl.cfg.ChallengeHandler
+	rl.mu.RUnlock()
+	if challenge != nil && !d.Banned && r != nil {
+		challenge.ServeHTTP(w, r)
+		return
+	}
+	rl.deny(w, r, route, d)
+}
+
+// ChallengePassed restores normal limits for a key that solved a
+// challenge: its bucket is topped up to capacity and any escalation is
+// dropped, so the client resumes as if it had never been throttled. Call
+// it from the challenge verification endpoint:
+//
+//	limiter.ChallengePassed(limiter.Key(r))
+//
+// Bans are deliberately not lifted; use Unban for that.
+func (rl *RateLimiter) ChallengePassed(key string) {
+	rl.Deescalate(key)
+	if b, ok := rl.store.Load(key); ok {
+		// Restore the default policy's limits first: an escalation may
+		// have clamped the bucket's capacity below them.
+		pol := rl.EffectivePolicy("")
+		b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+		now := rl.now()
+		if have := b.Peek(now); have < float64(pol.Capacity) {
+			b.ForceTake(have-float64(pol.Capacity), now)
+		}
+	}
+}
+
+// Key returns the limiter key the configured extractor derives for a
+// request, so challenge endpoints and other out-of-band paths can act on
+// the same identity the middleware limits.
+func (rl *RateLimiter) Key(r *http.Request) string {
+	return rl.keyFunc(r)
+}
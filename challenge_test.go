@@ -0,0 +1,60 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChallengeHandlerServedOverLimit(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     1,
+		RefillPerSec: 0.001,
+		ChallengeHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("solve this"))
+		}),
+	})
+	h := rl.Middleware(http.NotFoundHandler())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.8.8.8:999"
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+	}
+	if rec.Code != http.StatusForbidden || rec.Body.String() != "solve this" {
+		t.Fatalf("over-limit response = %d %q, want the challenge", rec.Code, rec.Body.String())
+	}
+
+	// Banned keys get the plain denial, not a solvable challenge.
+	rl.Ban(rl.Key(req), time.Minute)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("banned response = %d, want 429", rec.Code)
+	}
+}
+
+func TestChallengePassedRestoresLimits(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 3, RefillPerSec: 0.001, Now: clock.Now})
+
+	for i := 0; i < 4; i++ {
+		rl.Allow("k")
+	}
+	rl.Escalate("k", Policy{Capacity: 1}, time.Minute)
+	if rl.Allow("k").Allowed {
+		t.Fatal("drained key allowed before challenge")
+	}
+
+	rl.ChallengePassed("k")
+	if rl.Escalated("k") {
+		t.Fatal("escalation survived the challenge")
+	}
+	if d := rl.Allow("k"); !d.Allowed || d.Remaining != 2 {
+		t.Fatalf("post-challenge decision = %+v, want full bucket", d)
+	}
+}
@@ -0,0 +1,87 @@
+package signalfence
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// chaosRetryAfter is the Retry-After hint attached to injected denials.
+const chaosRetryAfter = time.Second
+
+// chaosState holds the active fault-injection settings.
+type chaosState struct {
+	mu           sync.RWMutex
+	denyRate     float64
+	extraLatency time.Duration
+	rng          *rand.Rand
+}
+
+// WithChaos turns on fault injection for staging environments: denyRate
+// (0..1) is the fraction of otherwise-allowed decisions flipped to denials
+// with a one-second Retry-After, and extraLatency is added to every
+// decision to simulate a slow store. Policies are untouched, so client
+// teams can verify their retry and backoff handling against realistic 429s
+// without changing what production enforces. Calling WithChaos again
+// replaces the previous settings; never enable it on a production limiter.
+func (rl *RateLimiter) WithChaos(denyRate float64, extraLatency time.Duration) {
+	if denyRate < 0 {
+		denyRate = 0
+	}
+	if denyRate > 1 {
+		denyRate = 1
+	}
+	if extraLatency < 0 {
+		extraLatency = 0
+	}
+	rl.chaos.mu.Lock()
+	defer rl.chaos.mu.Unlock()
+	rl.chaos.denyRate = denyRate
+	rl.chaos.extraLatency = extraLatency
+	if rl.chaos.rng == nil {
+		rl.chaos.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+// StopChaos turns fault injection off.
+func (rl *RateLimiter) StopChaos() {
+	rl.chaos.mu.Lock()
+	rl.chaos.denyRate = 0
+	rl.chaos.extraLatency = 0
+	rl.chaos.mu.Unlock()
+}
+
+// ChaosActive reports whether fault injection is currently enabled.
+func (rl *RateLimiter) ChaosActive() bool {
+	rl.chaos.mu.RLock()
+	defer rl.chaos.mu.RUnlock()
+	return rl.chaos.denyRate > 0 || rl.chaos.extraLatency > 0
+}
+
+// injectChaos applies the active chaos settings to a fresh decision. Peeks
+// stay truthful — Explain and Peek must keep reporting real state — and
+// injected denials only ever flip allowed decisions, so chaos never hides
+// a genuine denial.
+func (rl *RateLimiter) injectChaos(d Decision, peek bool) Decision {
+	rl.chaos.mu.RLock()
+	deny, lat := rl.chaos.denyRate, rl.chaos.extraLatency
+	rl.chaos.mu.RUnlock()
+	if deny == 0 && lat == 0 {
+		return d
+	}
+	if lat > 0 {
+		time.Sleep(lat)
+	}
+	if peek || !d.Allowed || deny == 0 {
+		return d
+	}
+	rl.chaos.mu.Lock()
+	roll := rl.chaos.rng.Float64()
+	rl.chaos.mu.Unlock()
+	if roll < deny {
+		d.Allowed = false
+		d.Remaining = 0
+		d.RetryAfter = chaosRetryAfter
+	}
+	return d
+}
@@ -0,0 +1,76 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChaosDenyRate(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 1})
+	defer rl.Close()
+
+	rl.WithChaos(1, 0)
+	if !rl.ChaosActive() {
+		t.Fatal("chaos not active")
+	}
+	d := rl.Allow("k")
+	if d.Allowed || d.RetryAfter != chaosRetryAfter {
+		t.Fatalf("decision = %+v", d)
+	}
+
+	rl.StopChaos()
+	if rl.ChaosActive() {
+		t.Fatal("chaos still active")
+	}
+	if d := rl.Allow("k"); !d.Allowed {
+		t.Fatalf("decision after StopChaos = %+v", d)
+	}
+}
+
+func TestChaosExtraLatency(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 1})
+	defer rl.Close()
+
+	rl.WithChaos(0, 30*time.Millisecond)
+	start := time.Now()
+	if d := rl.Allow("k"); !d.Allowed {
+		t.Fatalf("latency-only chaos denied: %+v", d)
+	}
+	if took := time.Since(start); took < 30*time.Millisecond {
+		t.Fatalf("Allow took %v, want >= 30ms", took)
+	}
+}
+
+func TestChaosPeekUnaffected(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 1})
+	defer rl.Close()
+
+	rl.WithChaos(1, 0)
+	if d := rl.Peek("k", ""); !d.Allowed {
+		t.Fatalf("peek = %+v", d)
+	}
+}
+
+func TestChaosNeverAllowsGenuineDenial(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	defer rl.Close()
+
+	rl.Allow("k")
+	rl.WithChaos(1, 0)
+	if d := rl.Allow("k"); d.Allowed {
+		t.Fatalf("genuine denial flipped: %+v", d)
+	}
+}
+
+func TestChaosClampsRate(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 1})
+	defer rl.Close()
+
+	rl.WithChaos(7, -time.Second)
+	rl.chaos.mu.RLock()
+	deny, lat := rl.chaos.denyRate, rl.chaos.extraLatency
+	rl.chaos.mu.RUnlock()
+	if deny != 1 || lat != 0 {
+		t.Fatalf("denyRate = %g, extraLatency = %v", deny, lat)
+	}
+}
@@ -0,0 +1,73 @@
+package signalfence
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// clampdown holds a temporary global scale applied to every policy.
+type clampdown struct {
+	mu     sync.RWMutex
+	factor float64
+	until  time.Time
+}
+
+// Clampdown temporarily scales every policy's capacity and refill rate by
+// factor (0 < factor < 1) for the next d, reverting automatically
+// afterwards. It is the one-call emergency brake for an active attack:
+//
+//	limiter.Clampdown(0.1, 10*time.Minute)
+//
+// Calling Clampdown again replaces the previous clamp. Factors outside
+// (0, 1) are ignored.
+func (rl *RateLimiter) Clampdown(factor float64, d time.Duration) {
+	if factor <= 0 || factor >= 1 || d <= 0 {
+		return
+	}
+	rl.clamp.mu.Lock()
+	rl.clamp.factor = factor
+	rl.clamp.until = time.Now().Add(d)
+	rl.clamp.mu.Unlock()
+}
+
+// LiftClampdown reverts an active clampdown before its duration elapses.
+func (rl *RateLimiter) LiftClampdown() {
+	rl.clamp.mu.Lock()
+	rl.clamp.until = time.Time{}
+	rl.clamp.mu.Unlock()
+}
+
+// ClampdownActive reports whether a clampdown is currently in effect.
+func (rl *RateLimiter) ClampdownActive() bool {
+	_, active := rl.clampFactor()
+	return active
+}
+
+// clampFactor returns the active clamp factor, if any.
+func (rl *RateLimiter) clampFactor() (float64, bool) {
+	rl.clamp.mu.RLock()
+	defer rl.clamp.mu.RUnlock()
+	if rl.clamp.until.IsZero() || time.Now().After(rl.clamp.until) {
+		return 1, false
+	}
+	return rl.clamp.factor, true
+}
+
+// clampPolicy scales pol by the active clampdown factor.
+func (rl *RateLimiter) clampPolicy(pol Policy) Policy {
+	factor, active := rl.clampFactor()
+	if !active {
+		return pol
+	}
+	return scalePolicy(pol, factor)
+}
+
+// scalePolicy scales a policy's capacity and refill rate by factor.
+// Capacity never drops below one token so scaled clients are throttled,
+// not locked out.
+func scalePolicy(pol Policy, factor float64) Policy {
+	pol.Capacity = int(math.Max(1, math.Floor(float64(pol.Capacity)*factor)))
+	pol.RefillPerSec *= factor
+	return pol
+}
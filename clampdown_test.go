@@ -0,0 +1,60 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClampdownScalesPolicies(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10})
+	rl.Clampdown(0.05, time.Minute)
+
+	d := rl.Allow("k")
+	if d.Limit != 5 {
+		t.Fatalf("clamped limit = %d, want 5", d.Limit)
+	}
+	for i := 0; i < 4; i++ {
+		rl.Allow("k")
+	}
+	if d := rl.Allow("k"); d.Allowed {
+		t.Fatalf("6th request under clamped capacity 5 = %+v", d)
+	}
+}
+
+func TestClampdownNeverZeroesCapacity(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 3, RefillPerSec: 1})
+	rl.Clampdown(0.01, time.Minute)
+	if d := rl.Allow("k"); !d.Allowed || d.Limit != 1 {
+		t.Fatalf("decision = %+v, want capacity floor of 1", d)
+	}
+}
+
+func TestClampdownAutoReverts(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10})
+	rl.Clampdown(0.1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if rl.ClampdownActive() {
+		t.Fatal("clampdown should have expired")
+	}
+	if d := rl.Allow("k"); d.Limit != 100 {
+		t.Fatalf("post-clamp limit = %d, want 100", d.Limit)
+	}
+}
+
+func TestLiftClampdown(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10})
+	rl.Clampdown(0.1, time.Hour)
+	rl.LiftClampdown()
+	if rl.ClampdownActive() {
+		t.Fatal("clampdown should be lifted")
+	}
+}
+
+func TestClampdownRejectsBadFactors(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10})
+	rl.Clampdown(0, time.Hour)
+	rl.Clampdown(1.5, time.Hour)
+	if rl.ClampdownActive() {
+		t.Fatal("invalid factors must be ignored")
+	}
+}
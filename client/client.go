@@ -0,0 +1,64 @@
+// Package client contains helpers for programs calling APIs protected by
+// SignalFence. It parses the limiter's problem+json denials and implements
+// the advised backoff so every SDK behaves the same way when limited.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// ParseProblem decodes the RFC 9457 problem document from a denial
+// response. It reports false when resp is not a problem+json rate limit
+// denial.
+func ParseProblem(resp *http.Response) (*signalfence.Problem, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil, false
+	}
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mt != "application/problem+json" {
+		return nil, false
+	}
+	var p signalfence.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, false
+	}
+	return &p, true
+}
+
+// Backoff returns the wait the server advised in resp: the problem
+// document's retry_after when present, otherwise the Retry-After header,
+// otherwise zero.
+func Backoff(resp *http.Response) time.Duration {
+	if p, ok := ParseProblem(resp); ok && p.RetryAfter > 0 {
+		return time.Duration(p.RetryAfter * float64(time.Second))
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+// Wait sleeps for the backoff advised in resp, returning early with the
+// context's error if ctx is done first. It returns immediately when the
+// response carries no backoff advice.
+func Wait(ctx context.Context, resp *http.Response) error {
+	d := Backoff(resp)
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// denialResponse produces a real middleware denial to parse.
+func denialResponse(t *testing.T) *http.Response {
+	t.Helper()
+	rl := signalfence.NewRateLimiter(signalfence.Config{
+		Capacity:     1,
+		RefillPerSec: 0.5,
+		ProblemJSON:  true,
+	})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func TestParseProblem(t *testing.T) {
+	p, ok := ParseProblem(denialResponse(t))
+	if !ok {
+		t.Fatal("want parsed problem")
+	}
+	if p.Status != http.StatusTooManyRequests || p.RetryAfter <= 0 {
+		t.Fatalf("problem = %+v", p)
+	}
+}
+
+func TestParseProblemRejectsPlainResponses(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(http.StatusOK)
+	if _, ok := ParseProblem(rec.Result()); ok {
+		t.Fatal("200 response is not a problem")
+	}
+}
+
+func TestBackoffPrefersProblemDocument(t *testing.T) {
+	d := Backoff(denialResponse(t))
+	if d <= 0 || d > 5*time.Second {
+		t.Fatalf("backoff = %v", d)
+	}
+}
+
+func TestWaitHonorsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Wait(ctx, denialResponse(t)); err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package clientlimit mirrors SignalFence server policies in the client
+// process so well-behaved callers can self-throttle and never burn a round
+// trip on a request that would come back 429. Policies are fetched from the
+// server's admin config endpoint and enforced locally with the same token
+// bucket algorithm.
+package clientlimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence"
+	"github.com/yourusername/signalfence/core"
+)
+
+// remoteConfig matches the JSON served by GET /admin/config.
+type remoteConfig struct {
+	Default signalfence.Policy            `json:"default"`
+	Routes  map[string]signalfence.Policy `json:"routes"`
+}
+
+// Limiter enforces the server's policies locally. It is safe for
+// concurrent use.
+type Limiter struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	cfg     remoteConfig
+	buckets map[string]*core.Bucket // route -> local bucket
+}
+
+// New returns a limiter that mirrors the policies served at configURL
+// (typically "https://host/admin/config"). Call Refresh before first use,
+// and periodically afterwards to pick up server-side changes.
+func New(configURL string, client *http.Client) *Limiter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Limiter{
+		url:     configURL,
+		client:  client,
+		buckets: make(map[string]*core.Bucket),
+	}
+}
+
+// Refresh fetches the current server config and swaps it in. Existing
+// buckets pick up changed limits on their next use.
+func (l *Limiter) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("clientlimit: config fetch returned %s", resp.Status)
+	}
+	var cfg remoteConfig
+	if err := json.NewDecoder(resp.Body).Decode(&cfg); err != nil {
+		return fmt.Errorf("clientlimit: decoding config: %w", err)
+	}
+
+	l.mu.Lock()
+	l.cfg = cfg
+	l.mu.Unlock()
+	return nil
+}
+
+// policyFor resolves the local policy for route, and the bucket enforcing
+// it.
+func (l *Limiter) bucketFor(route string) (*core.Bucket, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pol, ok := l.cfg.Routes[route]
+	if !ok {
+		pol = l.cfg.Default
+	}
+	if pol.Capacity <= 0 || pol.RefillPerSec <= 0 {
+		return nil, false // no config yet: do not throttle locally
+	}
+	b := l.buckets[route]
+	if b == nil {
+		b = core.NewBucket(float64(pol.Capacity), pol.RefillPerSec)
+		l.buckets[route] = b
+	}
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	return b, true
+}
+
+// Allow reports whether a request to route fits the mirrored policy,
+// consuming a local token if so. Without a fetched config it always allows.
+func (l *Limiter) Allow(route string) bool {
+	b, ok := l.bucketFor(route)
+	if !ok {
+		return true
+	}
+	allowed, _, _ := b.Take(1, time.Now())
+	return allowed
+}
+
+// Wait blocks until a token for route is available (or ctx is done), then
+// consumes it. Without a fetched config it returns immediately.
+func (l *Limiter) Wait(ctx context.Context, route string) error {
+	for {
+		b, ok := l.bucketFor(route)
+		if !ok {
+			return nil
+		}
+		allowed, _, retryAfter := b.Take(1, time.Now())
+		if allowed {
+			return nil
+		}
+		if retryAfter <= 0 {
+			retryAfter = 10 * time.Millisecond
+		}
+		t := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
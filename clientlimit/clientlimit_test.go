@@ -0,0 +1,71 @@
+package clientlimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/signalfence"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	rl := signalfence.NewRateLimiter(signalfence.Config{
+		Capacity:     2,
+		RefillPerSec: 1,
+		Routes: map[string]signalfence.Policy{
+			"/bulk": {Capacity: 1, RefillPerSec: 0.1},
+		},
+	})
+	srv := httptest.NewServer(rl.AdminHandler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLimiterMirrorsServerPolicies(t *testing.T) {
+	srv := newServer(t)
+	l := New(srv.URL+"/admin/config", srv.Client())
+	if err := l.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Default policy: capacity 2.
+	if !l.Allow("/api") || !l.Allow("/api") {
+		t.Fatal("first two requests should pass locally")
+	}
+	if l.Allow("/api") {
+		t.Fatal("third request should be pre-limited locally")
+	}
+
+	// Route override: capacity 1.
+	if !l.Allow("/bulk") {
+		t.Fatal("first /bulk request should pass")
+	}
+	if l.Allow("/bulk") {
+		t.Fatal("second /bulk request should be pre-limited")
+	}
+}
+
+func TestLimiterAllowsEverythingWithoutConfig(t *testing.T) {
+	l := New("http://unused.invalid/admin/config", nil)
+	for i := 0; i < 100; i++ {
+		if !l.Allow("/api") {
+			t.Fatal("limiter must not throttle before a config is fetched")
+		}
+	}
+}
+
+func TestWaitHonorsContext(t *testing.T) {
+	srv := newServer(t)
+	l := New(srv.URL+"/admin/config", srv.Client())
+	if err := l.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.Allow("/bulk") // drain the single token
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, "/bulk"); err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,280 @@
+// Command loadtest hammers a SignalFence-protected endpoint, or the /check
+// decision API, and reports the observed allow/deny ratio — the end-to-end
+// check that a deployed policy behaves the way capacity planning said it
+// would.
+//
+//	loadtest -target http://localhost:8080/api/data -rate 200 -duration 30s
+//	loadtest -target http://localhost:8080/check -check -keys 1000 -pattern burst
+//
+// Keys are synthetic ("load-0" .. "load-N-1"), drawn uniformly with an
+// optional hot fraction concentrated on load-0 to mimic a skewed client
+// population. In -check mode each request POSTs a decision API check; plain
+// mode issues GETs and counts a 429 as denied, optionally carrying the key
+// in a header for deployments keyed on something other than the client IP.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/signalfence/api"
+)
+
+// counters aggregates the run's outcomes across workers.
+type counters struct {
+	allowed  atomic.Uint64
+	denied   atomic.Uint64
+	errors   atomic.Uint64
+	latNanos atomic.Int64
+	maxNanos atomic.Int64
+}
+
+func (c *counters) observe(latency time.Duration) {
+	n := latency.Nanoseconds()
+	c.latNanos.Add(n)
+	for {
+		max := c.maxNanos.Load()
+		if n <= max || c.maxNanos.CompareAndSwap(max, n) {
+			return
+		}
+	}
+}
+
+// pacer emits one tick per request to send, shaped by the pattern:
+// steady spreads the rate evenly, burst concentrates each second's budget
+// into an instantaneous burst, and ramp scales linearly from zero to the
+// full rate over the run.
+func pace(ctx context.Context, pattern string, rate float64, duration time.Duration, ticks chan<- struct{}) {
+	defer close(ticks)
+	const slice = 100 * time.Millisecond
+	start := time.Now()
+	ticker := time.NewTicker(slice)
+	defer ticker.Stop()
+	carry := 0.0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			elapsed := now.Sub(start)
+			if elapsed >= duration {
+				return
+			}
+			var due float64
+			switch pattern {
+			case "steady":
+				due = rate * slice.Seconds()
+			case "burst":
+				// Send the whole second's budget on its first slice.
+				if int(elapsed/slice)%int(time.Second/slice) == 0 {
+					due = rate
+				}
+			case "ramp":
+				due = rate * (elapsed.Seconds() / duration.Seconds()) * slice.Seconds()
+			}
+			carry += due
+			for ; carry >= 1; carry-- {
+				select {
+				case ticks <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// keyPicker draws synthetic keys: hot of the draws land on load-0, the rest
+// spread uniformly across the population.
+type keyPicker struct {
+	mu   sync.Mutex
+	rng  *rand.Rand
+	keys int
+	hot  float64
+}
+
+func (p *keyPicker) pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.hot > 0 && p.rng.Float64() < p.hot {
+		return "load-0"
+	}
+	return fmt.Sprintf("load-%d", p.rng.Intn(p.keys))
+}
+
+// checkOnce POSTs one decision API check and reports whether it was
+// allowed.
+func checkOnce(client *http.Client, target, key, route, token string) (bool, error) {
+	body, err := json.Marshal(api.CheckRequest{ClientID: key, Route: route})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("check: status %d", resp.StatusCode)
+	}
+	var cr api.CheckResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return false, err
+	}
+	return cr.Allowed, nil
+}
+
+// getOnce issues one plain GET and reports whether it was admitted; 429 is
+// a denial, anything else non-2xx is an error.
+func getOnce(client *http.Client, target, key, keyHeader string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return false, err
+	}
+	if keyHeader != "" {
+		req.Header.Set(keyHeader, key)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return false, nil
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("get: status %d", resp.StatusCode)
+	}
+	return true, nil
+}
+
+func main() {
+	var (
+		target      = flag.String("target", "", "URL to hammer (required)")
+		check       = flag.Bool("check", false, "POST decision API checks instead of plain GETs")
+		route       = flag.String("route", "", "route sent with each check (requires -check)")
+		token       = flag.String("token", "", "bearer token sent with each check (requires -check)")
+		keyHeader   = flag.String("key-header", "", "header carrying the synthetic key in plain GET mode")
+		pattern     = flag.String("pattern", "steady", "traffic shape: steady, burst or ramp")
+		rate        = flag.Float64("rate", 100, "target request rate (req/sec)")
+		duration    = flag.Duration("duration", 10*time.Second, "how long to run")
+		keys        = flag.Int("keys", 100, "size of the synthetic key population")
+		hot         = flag.Float64("hot", 0, "fraction of requests concentrated on one hot key (0..1)")
+		workers     = flag.Int("workers", 16, "concurrent senders")
+		expectAllow = flag.Float64("expect-allow", -1, "expected allow ratio (0..1) to compare against; negative skips")
+	)
+	flag.Parse()
+
+	if *target == "" {
+		log.Fatal("-target is required")
+	}
+	switch *pattern {
+	case "steady", "burst", "ramp":
+	default:
+		log.Fatalf("pattern: unknown shape %q", *pattern)
+	}
+	if *rate <= 0 || *duration <= 0 || *keys < 1 || *workers < 1 {
+		log.Fatal("rate, duration, keys and workers must be positive")
+	}
+	if *hot < 0 || *hot > 1 {
+		log.Fatal("hot must be within [0, 1]")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	picker := &keyPicker{
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		keys: *keys,
+		hot:  *hot,
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	ticks := make(chan struct{}, *workers)
+	var stats counters
+
+	go pace(ctx, *pattern, *rate, *duration, ticks)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ticks {
+				key := picker.pick()
+				sent := time.Now()
+				var allowed bool
+				var err error
+				if *check {
+					allowed, err = checkOnce(client, *target, key, *route, *token)
+				} else {
+					allowed, err = getOnce(client, *target, key, *keyHeader)
+				}
+				stats.observe(time.Since(sent))
+				switch {
+				case err != nil:
+					stats.errors.Add(1)
+				case allowed:
+					stats.allowed.Add(1)
+				default:
+					stats.denied.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	report(&stats, time.Since(start), *expectAllow)
+}
+
+// report prints the run summary and, when an expectation was given, the
+// observed-vs-expected allow ratio.
+func report(stats *counters, elapsed time.Duration, expectAllow float64) {
+	allowed := stats.allowed.Load()
+	denied := stats.denied.Load()
+	errors := stats.errors.Load()
+	total := allowed + denied + errors
+	if total == 0 {
+		fmt.Println("no requests sent")
+		return
+	}
+	decided := allowed + denied
+	fmt.Printf("loadtest: %d requests in %s (%.1f req/s)\n",
+		total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	fmt.Printf("allowed: %d  denied: %d  errors: %d\n", allowed, denied, errors)
+	if decided > 0 {
+		fmt.Printf("allow ratio: %.1f%%  deny ratio: %.1f%%\n",
+			100*float64(allowed)/float64(decided), 100*float64(denied)/float64(decided))
+	}
+	fmt.Printf("latency: avg %s, max %s\n",
+		(time.Duration(stats.latNanos.Load()) / time.Duration(total)).Round(time.Microsecond),
+		time.Duration(stats.maxNanos.Load()).Round(time.Microsecond))
+	if expectAllow >= 0 && decided > 0 {
+		observed := float64(allowed) / float64(decided)
+		fmt.Printf("expected allow ratio %.1f%%, observed %.1f%% (delta %+.1f%%)\n",
+			100*expectAllow, 100*observed, 100*(observed-expectAllow))
+		if diff := observed - expectAllow; diff > 0.05 || diff < -0.05 {
+			fmt.Println("WARNING: observed ratio is more than 5 points off expectation")
+			os.Exit(1)
+		}
+	}
+}
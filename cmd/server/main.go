@@ -0,0 +1,290 @@
+// Command server runs SignalFence as a standalone rate limit decision
+// service. Callers POST /check with a client ID on the public listener and
+// receive the decision as JSON; operational endpoints (/admin) are served on
+// a separate internal listener. The server shuts down gracefully on
+// SIGINT/SIGTERM, draining in-flight requests before exiting.
+//
+// Config is discovered in order: the -config flag, the SIGNALFENCE_CONFIG
+// env var, ./signalfence.json, then /etc/signalfence/config.json. Without a
+// file, the default policy comes from SIGNALFENCE_CAPACITY and
+// SIGNALFENCE_REFILL_PER_SEC (or the flags). The effective config is logged
+// at startup; -print-config dumps it and exits, -check-config validates the
+// discovered file and exits.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/signalfence"
+	"github.com/yourusername/signalfence/api"
+)
+
+// envConfig names the env var holding the config file path; the paths below
+// are probed when neither the flag nor the env var is set.
+const envConfig = "SIGNALFENCE_CONFIG"
+
+var defaultConfigPaths = []string{"signalfence.json", "/etc/signalfence/config.json"}
+
+// findConfig resolves the config file path, or "" when none is configured.
+func findConfig(flagPath string) string {
+	if flagPath != "" {
+		return flagPath
+	}
+	if p := os.Getenv(envConfig); p != "" {
+		return p
+	}
+	for _, p := range defaultConfigPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// envPolicy overlays the default policy from env vars, for container
+// deployments that configure via environment instead of a file.
+func envPolicy(cfg *signalfence.Config) {
+	if v := os.Getenv("SIGNALFENCE_CAPACITY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Capacity = n
+		}
+	}
+	if v := os.Getenv("SIGNALFENCE_REFILL_PER_SEC"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.RefillPerSec = f
+		}
+	}
+}
+
+// parseServiceTokens parses comma-separated "service=token" pairs into the
+// token -> service map the API handler expects. Tokens may also come from
+// SIGNALFENCE_SERVICE_TOKENS so they can stay out of process listings.
+func parseServiceTokens(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		service, token, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || service == "" || token == "" {
+			return nil, fmt.Errorf("malformed pair %q, want service=token", pair)
+		}
+		tokens[token] = service
+	}
+	return tokens, nil
+}
+
+// parseTags parses comma-separated "key=value" pairs into StatsD tags.
+func parseTags(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("malformed tag %q, want key=value", pair)
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func main() {
+	var (
+		addr            = flag.String("addr", ":8080", "public listen address (decision API)")
+		internalAddr    = flag.String("internal-addr", ":8081", "internal listen address (admin endpoints)")
+		configPath      = flag.String("config", "", "path to a JSON config file (see signalfence.FileConfig)")
+		printConfig     = flag.Bool("print-config", false, "print the effective config and exit")
+		checkConfig     = flag.Bool("check-config", false, "validate the discovered config file and exit")
+		lintConfig      = flag.Bool("lint-config", false, "lint the discovered config file and exit non-zero on findings")
+		capacity        = flag.Int("capacity", 20, "default bucket capacity")
+		refill          = flag.Float64("refill", 10, "default refill rate (tokens/sec)")
+		readTimeout     = flag.Duration("read-timeout", 5*time.Second, "HTTP read timeout")
+		writeTimeout    = flag.Duration("write-timeout", 10*time.Second, "HTTP write timeout")
+		shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second, "graceful shutdown drain deadline")
+		cleanupIdle     = flag.Duration("cleanup-idle", 10*time.Minute, "expire buckets idle for this long")
+		logLevel        = flag.String("log-level", "info", "minimum access log level (debug, info, warn, error)")
+		logSample       = flag.Float64("log-sample", 1, "fraction of allowed decisions to log; denials always log")
+		metaCapacity    = flag.Int("meta-capacity", 1000, "per-service burst budget on /check itself (0 disables)")
+		metaRefill      = flag.Float64("meta-refill", 500, "per-service refill rate on /check itself (tokens/sec)")
+		promMetrics     = flag.Bool("prom-metrics", false, "count decisions and serve them at /metrics on the internal listener")
+		statsdAddr      = flag.String("statsd-addr", "", "StatsD/Datadog agent address for pushed metrics (empty disables)")
+		statsdPrefix    = flag.String("statsd-prefix", "", "metric name prefix for -statsd-addr (default signalfence)")
+		statsdTags      = flag.String("statsd-tags", "", "comma-separated key=value tags added to every StatsD metric")
+		historyFile     = flag.String("history-file", "", "persist metrics snapshots to this file for /admin/history (empty disables)")
+		historyEvery    = flag.Duration("history-every", time.Minute, "metrics snapshot interval when -history-file is set")
+		serviceTokens   = flag.String("service-tokens", "", "comma-separated service=token pairs; non-empty requires auth on /check")
+		overrides       = flag.String("overrides", "all", "who may use inline capacity overrides in checks: all, admin, off")
+		adminServices   = flag.String("admin-services", "", "comma-separated services trusted with overrides when -overrides=admin")
+	)
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		log.Fatalf("log-level: %v", err)
+	}
+	accessLog := signalfence.NewAccessLogger(os.Stderr, level, *logSample)
+
+	cfg := signalfence.Config{
+		Capacity:     *capacity,
+		RefillPerSec: *refill,
+	}
+	path := findConfig(*configPath)
+	if *checkConfig {
+		if path == "" {
+			log.Fatal("check-config: no config file found")
+		}
+		if _, err := signalfence.LoadConfigFile(path); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("config OK:", path)
+		return
+	}
+	if *lintConfig {
+		if path == "" {
+			log.Fatal("lint-config: no config file found")
+		}
+		fc, err := signalfence.LoadConfigFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		issues := signalfence.LintConfig(fc.Config())
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("config clean:", path)
+		return
+	}
+
+	var fileCfg signalfence.FileConfig
+	if path != "" {
+		fc, err := signalfence.LoadConfigFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fileCfg = fc
+		cfg = fc.Config()
+	} else {
+		envPolicy(&cfg)
+		fileCfg = signalfence.FileConfig{
+			Default: signalfence.Policy{Capacity: cfg.Capacity, RefillPerSec: cfg.RefillPerSec},
+		}
+	}
+
+	if *printConfig {
+		out, err := json.MarshalIndent(fileCfg, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	var prom *signalfence.PromMetrics
+	if *promMetrics {
+		prom = signalfence.NewPromMetrics()
+		cfg.Metrics = prom
+	}
+
+	limiter := signalfence.NewRateLimiter(cfg)
+	if path != "" {
+		fileCfg.Apply(limiter)
+	} else {
+		limiter.StartCleanupElected(*cleanupIdle)
+	}
+	defer limiter.Close()
+
+	if effective, err := json.Marshal(fileCfg); err == nil {
+		if path == "" {
+			path = "(flags/env)"
+		}
+		log.Printf("config from %s: %s", path, effective)
+	}
+
+	rawTokens := *serviceTokens
+	if rawTokens == "" {
+		rawTokens = os.Getenv("SIGNALFENCE_SERVICE_TOKENS")
+	}
+	tokens, err := parseServiceTokens(rawTokens)
+	if err != nil {
+		log.Fatalf("service-tokens: %v", err)
+	}
+	overrideMode, ok := api.ParseOverrideMode(*overrides)
+	if !ok {
+		log.Fatalf("overrides: unknown mode %q", *overrides)
+	}
+	var admins map[string]bool
+	if *adminServices != "" {
+		admins = make(map[string]bool)
+		for _, name := range strings.Split(*adminServices, ",") {
+			admins[strings.TrimSpace(name)] = true
+		}
+	}
+
+	var metrics signalfence.MetricsSink
+	if *statsdAddr != "" {
+		tags, err := parseTags(*statsdTags)
+		if err != nil {
+			log.Fatalf("statsd-tags: %v", err)
+		}
+		statsd, err := signalfence.NewStatsDSink(*statsdAddr, *statsdPrefix, tags)
+		if err != nil {
+			log.Fatalf("statsd: %v", err)
+		}
+		defer statsd.Close()
+		statsd.StartGauges(limiter, 10*time.Second)
+		metrics = statsd
+	}
+
+	var history *signalfence.MetricsHistory
+	if *historyFile != "" {
+		history = &signalfence.MetricsHistory{
+			Path:    *historyFile,
+			Every:   *historyEvery,
+			OnError: func(err error) { log.Printf("history: %v", err) },
+		}
+		history.Start(limiter)
+	}
+
+	srv := newServer(limiter, serverOptions{
+		publicAddr:      *addr,
+		internalAddr:    *internalAddr,
+		readTimeout:     *readTimeout,
+		writeTimeout:    *writeTimeout,
+		shutdownTimeout: *shutdownTimeout,
+		accessLog:       accessLog,
+		metaCapacity:    *metaCapacity,
+		metaRefill:      *metaRefill,
+		serviceTokens:   tokens,
+		overrides:       overrideMode,
+		adminServices:   admins,
+		history:         history,
+		metrics:         metrics,
+		prom:            prom,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := srv.run(ctx); err != nil {
+		log.Fatalf("listen: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/signalfence"
+	"github.com/yourusername/signalfence/api"
+)
+
+// serviceHeader identifies the calling service for the meta-limit on the
+// decision API itself.
+const serviceHeader = "X-SignalFence-Service"
+
+// metaLimit protects /check from a misbehaving caller: each calling service
+// (by its service header, falling back to the peer IP) gets its own budget
+// on a limiter separate from the one making the decisions.
+func metaLimit(meta *signalfence.RateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(serviceHeader)
+		if key != "" {
+			key = "svc:" + key
+		} else {
+			key = signalfence.ClientIP(r)
+		}
+		if d := meta.Allow(key); !d.Allowed {
+			secs := int(d.RetryAfter.Seconds() + 0.999)
+			if secs < 1 {
+				secs = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			http.Error(w, "decision API rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// server wires one limiter to two listeners: the public listener serves only
+// the decision API (/check), while operational surfaces (/admin and
+// /dashboard) live on a separate internal listener that should never be
+// exposed beyond the private network.
+type server struct {
+	limiter         *signalfence.RateLimiter
+	public          *http.Server
+	internal        *http.Server
+	shutdownTimeout time.Duration
+}
+
+// serverOptions carries the listener knobs from flags to newServer.
+type serverOptions struct {
+	publicAddr      string
+	internalAddr    string
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	shutdownTimeout time.Duration
+	accessLog       *signalfence.AccessLogger
+	// metaCapacity and metaRefill configure the per-service limit on the
+	// decision API itself; a zero capacity disables it.
+	metaCapacity int
+	metaRefill   float64
+	// serviceTokens maps bearer tokens to service names; non-empty makes
+	// authentication mandatory on /check.
+	serviceTokens map[string]string
+	// overrides gates inline capacity/refill overrides in checks;
+	// adminServices lists the services trusted with them under "admin".
+	overrides     api.OverrideMode
+	adminServices map[string]bool
+	// history, when set, is served at /admin/history on the internal
+	// listener.
+	history *signalfence.MetricsHistory
+	// metrics, when set, counts every decision the check handler makes.
+	metrics signalfence.MetricsSink
+	// prom, when set, is served at /metrics on the internal listener. The
+	// limiter itself feeds it via Config.Metrics.
+	prom *signalfence.PromMetrics
+}
+
+func newServer(limiter *signalfence.RateLimiter, opts serverOptions) *server {
+	checkHandler := api.NewHandler(limiter)
+	checkHandler.AccessLog = opts.accessLog
+	checkHandler.Metrics = opts.metrics
+	checkHandler.ServiceTokens = opts.serviceTokens
+	checkHandler.Overrides = opts.overrides
+	checkHandler.AdminServices = opts.adminServices
+
+	var check http.Handler = checkHandler
+	if opts.metaCapacity > 0 {
+		meta := signalfence.NewRateLimiter(signalfence.Config{
+			Capacity:     opts.metaCapacity,
+			RefillPerSec: opts.metaRefill,
+		})
+		check = metaLimit(meta, check)
+	}
+
+	publicMux := http.NewServeMux()
+	publicMux.Handle("/check", check)
+
+	internalMux := http.NewServeMux()
+	internalMux.Handle("/admin/", limiter.AdminHandler())
+	internalMux.Handle("/dashboard", limiter.DashboardHandler())
+	if opts.history != nil {
+		internalMux.Handle("/admin/history", opts.history.Handler())
+	}
+	if opts.prom != nil {
+		internalMux.Handle("/metrics", opts.prom.Handler())
+	}
+
+	return &server{
+		limiter: limiter,
+		public: &http.Server{
+			Addr:         opts.publicAddr,
+			Handler:      publicMux,
+			ReadTimeout:  opts.readTimeout,
+			WriteTimeout: opts.writeTimeout,
+		},
+		internal: &http.Server{
+			Addr:         opts.internalAddr,
+			Handler:      internalMux,
+			ReadTimeout:  opts.readTimeout,
+			WriteTimeout: opts.writeTimeout,
+		},
+		shutdownTimeout: opts.shutdownTimeout,
+	}
+}
+
+// run serves both listeners until ctx is cancelled or either listener fails,
+// then drains them within the shutdown timeout.
+func (s *server) run(ctx context.Context) error {
+	errc := make(chan error, 2)
+	go func() { errc <- s.public.ListenAndServe() }()
+	go func() { errc <- s.internal.ListenAndServe() }()
+	log.Printf("signalfence server listening on %s (public), %s (internal)",
+		s.public.Addr, s.internal.Addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	for _, srv := range []*http.Server{s.public, s.internal} {
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("shutdown: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/signalfence"
+)
+
+func TestMetaLimitPerService(t *testing.T) {
+	meta := signalfence.NewRateLimiter(signalfence.Config{Capacity: 2, RefillPerSec: 0.001})
+	h := metaLimit(meta, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	call := func(service string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/check", nil)
+		req.RemoteAddr = "10.0.0.1:999"
+		if service != "" {
+			req.Header.Set(serviceHeader, service)
+		}
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := call("billing"); got != http.StatusOK {
+			t.Fatalf("billing call %d: %d", i, got)
+		}
+	}
+	if got := call("billing"); got != http.StatusTooManyRequests {
+		t.Fatalf("billing over budget: got %d, want 429", got)
+	}
+	// A different service has its own budget; so does an anonymous caller
+	// keyed by peer IP.
+	if got := call("search"); got != http.StatusOK {
+		t.Fatalf("search blocked by billing's budget: %d", got)
+	}
+	if got := call(""); got != http.StatusOK {
+		t.Fatalf("anonymous caller blocked: %d", got)
+	}
+}
@@ -0,0 +1,279 @@
+package signalfence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileConfig is the on-disk form of Config, shared by embedded users and the
+// cmd/server binary so one file drives both deployment styles. The format is
+// JSON using the same field names the admin API exports; KeyFunc cannot be
+// expressed in a file, so the extractor is selected by name instead.
+type FileConfig struct {
+	// Default is the policy applied to routes without an override.
+	Default Policy `json:"default"`
+	// Policies holds named base policies that routes (and other named
+	// policies) can extend, so dozens of routes sharing mostly identical
+	// settings name one base instead of duplicating numbers that drift
+	// apart:
+	//
+	//	"policies": {"strict-base": {"capacity": 5, "refill_per_sec": 1}},
+	//	"routes":   {"/login": {"extends": "strict-base", "capacity": 3}}
+	Policies map[string]FilePolicy `json:"policies,omitempty"`
+	// Routes, Canaries and Shadows mirror the Config fields of the same
+	// names; route policies may extend a named base.
+	Routes   map[string]FilePolicy `json:"routes,omitempty"`
+	Canaries map[string]Canary     `json:"canaries,omitempty"`
+	Shadows  map[string]Policy     `json:"shadows,omitempty"`
+	// KeyClasses maps a key-class prefix ("ip:", "bearer:") to the default
+	// policy for keys of that class; entries may extend a named base. See
+	// Config.KeyClasses for the layering rules.
+	KeyClasses map[string]FilePolicy `json:"key_classes,omitempty"`
+	// ReadDefaults and WriteDefaults split the default policy by method —
+	// GET/HEAD versus everything else — for routes without an explicit
+	// override; both may extend a named base. See Config.ReadDefaults.
+	ReadDefaults  *FilePolicy `json:"read_defaults,omitempty"`
+	WriteDefaults *FilePolicy `json:"write_defaults,omitempty"`
+	// Extractor selects the key extractor using the ParseExtractor spec
+	// grammar: simple names like "client_ip" (the default) or "ip_proxy",
+	// or composed specs like "jwt:sub", "subnet:24" and
+	// "join:header:X-API-Key+path".
+	Extractor string `json:"extractor,omitempty"`
+	// Mode is the starting decision mode: "enforce" (the default),
+	// "observe" or "bypass".
+	Mode string `json:"mode,omitempty"`
+	// CleanupIdle is a duration string ("10m"); buckets idle for longer are
+	// expired by the background sweep. Empty disables the sweep.
+	CleanupIdle string `json:"cleanup_idle,omitempty"`
+	// MetricsRetention is a duration string ("720h"); per-key metrics for
+	// clients not seen within it are purged (see PurgeRetained), keeping
+	// personal data no longer than policy allows. Empty keeps metrics
+	// until process exit.
+	MetricsRetention string `json:"metrics_retention,omitempty"`
+	// PushMetrics, when set, pushes aggregated metrics to an OTLP/HTTP
+	// collector on an interval (see OTLPExporter), for deployments where
+	// nothing scrapes the process.
+	PushMetrics *PushMetricsConfig `json:"push_metrics,omitempty"`
+}
+
+// FilePolicy is the file form of a policy: a Policy plus inheritance.
+// Extends names an entry of FileConfig.Policies whose fields fill any this
+// policy leaves zero; the base may itself extend another base, and the
+// nearest layer always wins.
+type FilePolicy struct {
+	Policy
+	Extends string `json:"extends,omitempty"`
+}
+
+// PushMetricsConfig is the file form of OTLPExporter.
+type PushMetricsConfig struct {
+	// Endpoint is the collector's OTLP/HTTP metrics URL.
+	Endpoint string `json:"endpoint"`
+	// Every is the push interval as a duration string ("1m", the
+	// default).
+	Every string `json:"every,omitempty"`
+	// Headers are added to every push, e.g. an Authorization header.
+	Headers map[string]string `json:"headers,omitempty"`
+	// ServiceName overrides the exported service.name resource attribute.
+	ServiceName string `json:"service_name,omitempty"`
+}
+
+// LoadConfigFile reads and validates a FileConfig from path. Unknown fields
+// are rejected so typos fail loudly at startup instead of silently using
+// defaults.
+func LoadConfigFile(path string) (FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileConfig{}, err
+	}
+	fc, err := ParseConfig(data)
+	if err != nil {
+		return fc, fmt.Errorf("config %s: %w", path, err)
+	}
+	return fc, nil
+}
+
+// ParseConfig decodes and validates a FileConfig from its JSON form, with
+// the same strictness as LoadConfigFile. Policy sources use it to parse
+// documents fetched from places other than the local filesystem.
+func ParseConfig(data []byte) (FileConfig, error) {
+	var fc FileConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return fc, err
+	}
+	if err := fc.validate(); err != nil {
+		return fc, err
+	}
+	return fc, nil
+}
+
+func (fc FileConfig) validate() error {
+	if _, err := ParseExtractor(fc.Extractor); err != nil {
+		return err
+	}
+	if fc.Mode != "" {
+		if _, ok := parseMode(fc.Mode); !ok {
+			return fmt.Errorf("unknown mode %q", fc.Mode)
+		}
+	}
+	if fc.CleanupIdle != "" {
+		if _, err := time.ParseDuration(fc.CleanupIdle); err != nil {
+			return fmt.Errorf("cleanup_idle: %w", err)
+		}
+	}
+	if fc.MetricsRetention != "" {
+		if _, err := time.ParseDuration(fc.MetricsRetention); err != nil {
+			return fmt.Errorf("metrics_retention: %w", err)
+		}
+	}
+	if fc.PushMetrics != nil {
+		if fc.PushMetrics.Endpoint == "" {
+			return fmt.Errorf("push_metrics: endpoint is required")
+		}
+		if fc.PushMetrics.Every != "" {
+			if _, err := time.ParseDuration(fc.PushMetrics.Every); err != nil {
+				return fmt.Errorf("push_metrics.every: %w", err)
+			}
+		}
+	}
+	for route, c := range fc.Canaries {
+		if c.Percent < 0 || c.Percent > 100 {
+			return fmt.Errorf("canary for %q: percent %d out of range", route, c.Percent)
+		}
+	}
+	for name, p := range fc.Policies {
+		if err := fc.checkExtends("policy "+name, p.Extends); err != nil {
+			return err
+		}
+	}
+	for route, p := range fc.Routes {
+		if err := fc.checkExtends("route "+route, p.Extends); err != nil {
+			return err
+		}
+	}
+	for class, p := range fc.KeyClasses {
+		if class == "" || class[len(class)-1] != ':' {
+			return fmt.Errorf("key class %q must be a prefix ending in ':'", class)
+		}
+		if err := fc.checkExtends("key class "+class, p.Extends); err != nil {
+			return err
+		}
+	}
+	if fc.ReadDefaults != nil {
+		if err := fc.checkExtends("read_defaults", fc.ReadDefaults.Extends); err != nil {
+			return err
+		}
+	}
+	if fc.WriteDefaults != nil {
+		if err := fc.checkExtends("write_defaults", fc.WriteDefaults.Extends); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkExtends verifies that an extends chain starting at base only names
+// known policies and never loops back on itself.
+func (fc FileConfig) checkExtends(owner, base string) error {
+	seen := make(map[string]bool)
+	for base != "" {
+		if seen[base] {
+			return fmt.Errorf("%s: extends cycle through %q", owner, base)
+		}
+		seen[base] = true
+		p, ok := fc.Policies[base]
+		if !ok {
+			return fmt.Errorf("%s: extends unknown policy %q", owner, base)
+		}
+		base = p.Extends
+	}
+	return nil
+}
+
+// resolvePolicy flattens a policy's inheritance chain: each layer fills
+// only the fields the layers closer to the route left zero. validate()
+// already established the chain is acyclic and fully resolvable.
+func (fc FileConfig) resolvePolicy(p FilePolicy) Policy {
+	pol := p.Policy
+	for base := p.Extends; base != ""; {
+		parent := fc.Policies[base]
+		pol = mergePolicy(pol, parent.Policy)
+		base = parent.Extends
+	}
+	return pol
+}
+
+// Config converts the file form into a Config ready for NewRateLimiter.
+func (fc FileConfig) Config() Config {
+	cfg := Config{
+		Capacity:      fc.Default.Capacity,
+		RefillPerSec:  fc.Default.RefillPerSec,
+		WarnThreshold: fc.Default.WarnThreshold,
+		Grace:         fc.Default.Grace,
+		Canaries:      fc.Canaries,
+		Shadows:       fc.Shadows,
+	}
+	if len(fc.Routes) > 0 {
+		cfg.Routes = make(map[string]Policy, len(fc.Routes))
+		for route, p := range fc.Routes {
+			cfg.Routes[route] = fc.resolvePolicy(p)
+		}
+	}
+	if len(fc.KeyClasses) > 0 {
+		cfg.KeyClasses = make(map[string]Policy, len(fc.KeyClasses))
+		for class, p := range fc.KeyClasses {
+			cfg.KeyClasses[class] = fc.resolvePolicy(p)
+		}
+	}
+	if fc.ReadDefaults != nil {
+		pol := fc.resolvePolicy(*fc.ReadDefaults)
+		cfg.ReadDefaults = &pol
+	}
+	if fc.WriteDefaults != nil {
+		pol := fc.resolvePolicy(*fc.WriteDefaults)
+		cfg.WriteDefaults = &pol
+	}
+	if fc.Extractor != "" && fc.Extractor != "client_ip" {
+		// validate() already established the spec parses.
+		cfg.KeyFunc, _ = ParseExtractor(fc.Extractor)
+	}
+	return cfg
+}
+
+// Apply configures post-construction settings — mode and background cleanup —
+// on a limiter built from this file.
+func (fc FileConfig) Apply(rl *RateLimiter) {
+	if fc.Mode != "" {
+		if m, ok := parseMode(fc.Mode); ok {
+			rl.SetMode(m)
+		}
+	}
+	if fc.CleanupIdle != "" {
+		if d, err := time.ParseDuration(fc.CleanupIdle); err == nil {
+			rl.StartCleanupElected(d)
+		}
+	}
+	if fc.MetricsRetention != "" {
+		if d, err := time.ParseDuration(fc.MetricsRetention); err == nil {
+			rl.StartRetention(d)
+		}
+	}
+	if fc.PushMetrics != nil {
+		e := &OTLPExporter{
+			Endpoint:    fc.PushMetrics.Endpoint,
+			Headers:     fc.PushMetrics.Headers,
+			ServiceName: fc.PushMetrics.ServiceName,
+		}
+		if fc.PushMetrics.Every != "" {
+			if d, err := time.ParseDuration(fc.PushMetrics.Every); err == nil {
+				e.Every = d
+			}
+		}
+		e.Start(rl)
+	}
+}
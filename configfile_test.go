@@ -0,0 +1,96 @@
+package signalfence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "signalfence.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfig(t, `{
+		"default": {"capacity": 50, "refill_per_sec": 5, "warn_threshold": 0.8},
+		"routes": {"/api/search": {"capacity": 10, "refill_per_sec": 1}},
+		"extractor": "ip",
+		"mode": "observe",
+		"cleanup_idle": "10m"
+	}`)
+	fc, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rl := NewRateLimiter(fc.Config())
+	fc.Apply(rl)
+	defer rl.Close()
+
+	if got := rl.EffectivePolicy(""); got.Capacity != 50 || got.RefillPerSec != 5 {
+		t.Fatalf("default policy = %+v", got)
+	}
+	if got := rl.EffectivePolicy("/api/search"); got.Capacity != 10 {
+		t.Fatalf("route policy = %+v", got)
+	}
+	// The route override inherits the default warn threshold.
+	if got := rl.EffectivePolicy("/api/search"); got.WarnThreshold != 0.8 {
+		t.Fatalf("route warn threshold = %v, want inherited 0.8", got.WarnThreshold)
+	}
+	if rl.Mode() != ModeObserve {
+		t.Fatalf("mode = %v, want observe", rl.Mode())
+	}
+}
+
+func TestConfigPolicyInheritance(t *testing.T) {
+	path := writeConfig(t, `{
+		"default": {"capacity": 100, "refill_per_sec": 50},
+		"policies": {
+			"strict-base": {"capacity": 5, "refill_per_sec": 1, "warn_threshold": 0.5},
+			"strict-docs": {"extends": "strict-base", "docs_url": "https://example.com/limits"}
+		},
+		"routes": {
+			"/login":       {"extends": "strict-base", "capacity": 3},
+			"/api/tokens":  {"extends": "strict-docs"}
+		}
+	}`)
+	fc, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := fc.Config()
+
+	login := cfg.Routes["/login"]
+	if login.Capacity != 3 || login.RefillPerSec != 1 || login.WarnThreshold != 0.5 {
+		t.Fatalf("/login = %+v", login)
+	}
+	tokens := cfg.Routes["/api/tokens"]
+	if tokens.Capacity != 5 || tokens.DocsURL != "https://example.com/limits" {
+		t.Fatalf("/api/tokens = %+v", tokens)
+	}
+}
+
+func TestLoadConfigFileRejectsBadInput(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":   `{"defautl": {"capacity": 5}}`,
+		"bad extractor":   `{"extractor": "geoip"}`,
+		"bad mode":        `{"mode": "yolo"}`,
+		"bad duration":    `{"cleanup_idle": "ten minutes"}`,
+		"canary percent":  `{"canaries": {"": {"policy": {"capacity": 1}, "percent": 250}}}`,
+		"unknown extends": `{"routes": {"/login": {"extends": "nope"}}}`,
+		"extends cycle":   `{"policies": {"a": {"extends": "b"}, "b": {"extends": "a"}}}`,
+	}
+	for name, body := range cases {
+		if _, err := LoadConfigFile(writeConfig(t, body)); err == nil {
+			t.Errorf("%s: config accepted, want error", name)
+		}
+	}
+	if _, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing file: want error")
+	}
+}
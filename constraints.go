@@ -0,0 +1,129 @@
+package signalfence
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Constraint names used in ConstraintResult and the per-constraint
+// headers.
+const (
+	ConstraintRate        = "rate"
+	ConstraintQuota       = "quota"
+	ConstraintConcurrency = "concurrency"
+)
+
+// DefaultQuotaWindow is the quota window applied when a policy sets Quota
+// without a QuotaWindow.
+const DefaultQuotaWindow = 24 * time.Hour
+
+// ConstraintResult is one constraint's sub-decision within a composed
+// policy. The overall Decision denies when any constraint does; the
+// sub-results say which one and with what headroom.
+type ConstraintResult struct {
+	Name       string        `json:"name"`
+	Allowed    bool          `json:"allowed"`
+	Limit      int           `json:"limit"`
+	Remaining  int           `json:"remaining"`
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// quotaWindow is one key's consumption in the current fixed quota window.
+type quotaWindow struct {
+	start time.Time
+	used  float64
+}
+
+// quotaCounters tracks fixed-window quota consumption per bucket key.
+type quotaCounters struct {
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+}
+
+// take consumes cost from key's quota window, rolling the window when it
+// has elapsed, and returns the constraint sub-result. With peek set it
+// only reports.
+func (q *quotaCounters) take(key string, limit int, window time.Duration, cost float64, now time.Time, peek bool) ConstraintResult {
+	if window <= 0 {
+		window = DefaultQuotaWindow
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.windows == nil {
+		q.windows = make(map[string]*quotaWindow)
+	}
+	w, ok := q.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &quotaWindow{start: now}
+		q.windows[key] = w
+	}
+	cr := ConstraintResult{Name: ConstraintQuota, Limit: limit}
+	if w.used+cost <= float64(limit) {
+		cr.Allowed = true
+		if !peek {
+			w.used += cost
+		}
+		cr.Remaining = int(float64(limit) - w.used)
+		return cr
+	}
+	cr.Remaining = int(float64(limit) - w.used)
+	if cr.Remaining < 0 {
+		cr.Remaining = 0
+	}
+	cr.RetryAfter = w.start.Add(window).Sub(now)
+	return cr
+}
+
+// forget drops the quota windows for key's bare bucket and every derived
+// bucket sharing its identity.
+func (q *quotaCounters) forget(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for k := range q.windows {
+		if k == key || strings.HasPrefix(k, key+"|") || strings.HasPrefix(k, key+"#") {
+			delete(q.windows, k)
+		}
+	}
+}
+
+// refund returns cost to key's current quota window, for after-the-fact
+// corrections.
+func (q *quotaCounters) refund(key string, cost float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w, ok := q.windows[key]; ok && w.used >= cost {
+		w.used -= cost
+	}
+}
+
+// slotCounters tracks in-flight requests per key for the concurrency
+// constraint.
+type slotCounters struct {
+	keys sync.Map // key string -> *atomic.Int64
+}
+
+// acquire takes an in-flight slot for key under max, reporting whether one
+// was free and how many are in flight afterwards. Callers that got a slot
+// must release it.
+func (s *slotCounters) acquire(key string, max int) (ok bool, inflight int64) {
+	v, loaded := s.keys.Load(key)
+	if !loaded {
+		v, _ = s.keys.LoadOrStore(key, new(atomic.Int64))
+	}
+	c := v.(*atomic.Int64)
+	n := c.Add(1)
+	if n > int64(max) {
+		c.Add(-1)
+		return false, n - 1
+	}
+	return true, n
+}
+
+// release frees an in-flight slot for key.
+func (s *slotCounters) release(key string) {
+	if v, ok := s.keys.Load(key); ok {
+		v.(*atomic.Int64).Add(-1)
+	}
+}
@@ -0,0 +1,123 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQuotaConstraintDeniesPastWindowBudget(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 10,
+		Now:          clock.Now,
+		Routes: map[string]Policy{
+			"/api": {Capacity: 10, RefillPerSec: 10, Quota: 3, QuotaWindow: time.Hour},
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		d := rl.AllowRoute("k", "/api")
+		if !d.Allowed {
+			t.Fatalf("request %d denied under quota", i)
+		}
+		if len(d.Constraints) != 2 || d.Constraints[1].Name != ConstraintQuota {
+			t.Fatalf("constraints = %+v", d.Constraints)
+		}
+		if got := d.Constraints[1].Remaining; got != 2-i {
+			t.Fatalf("quota remaining = %d, want %d", got, 2-i)
+		}
+	}
+
+	d := rl.AllowRoute("k", "/api")
+	if d.Allowed {
+		t.Fatal("request over quota allowed")
+	}
+	if d.Constraints[0].Name != ConstraintRate || !d.Constraints[0].Allowed {
+		t.Fatalf("rate sub-result = %+v, want allowed", d.Constraints[0])
+	}
+	if q := d.Constraints[1]; q.Allowed || q.RetryAfter <= 0 {
+		t.Fatalf("quota sub-result = %+v", q)
+	}
+	// The quota denial refunded the bucket: rate tokens were not burned.
+	if d.Remaining != 7 {
+		t.Fatalf("rate remaining after quota denial = %d, want 7", d.Remaining)
+	}
+
+	// The quota window rolls over and admits again.
+	clock.Advance(time.Hour)
+	if d := rl.AllowRoute("k", "/api"); !d.Allowed {
+		t.Fatal("request denied after quota window rolled")
+	}
+}
+
+func TestConcurrencyConstraintCapsInflight(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 100,
+		Routes:       map[string]Policy{"/slow": {Capacity: 100, RefillPerSec: 100, MaxConcurrent: 1}},
+	})
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	req.RemoteAddr = "10.9.9.9:999"
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-entered
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second in-flight request status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("X-Concurrency-Limit") != "1" {
+		t.Fatalf("X-Concurrency-Limit = %q", rec.Header().Get("X-Concurrency-Limit"))
+	}
+
+	close(release)
+	wg.Wait()
+
+	// The slot was released; the next request goes through.
+	rec = httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() { h.ServeHTTP(rec, req); close(done) }()
+	<-entered
+	<-done
+	if rec.Code != http.StatusOK {
+		t.Fatalf("post-release request status = %d", rec.Code)
+	}
+}
+
+func TestConstraintsSerializeOnWire(t *testing.T) {
+	d := Decision{
+		Allowed: false,
+		Constraints: []ConstraintResult{
+			{Name: ConstraintRate, Allowed: true, Limit: 10, Remaining: 7},
+			{Name: ConstraintQuota, Allowed: false, Limit: 3, RetryAfter: time.Minute},
+		},
+	}
+	data, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Decision
+	if err := back.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Constraints) != 2 || back.Constraints[1].RetryAfter != time.Minute {
+		t.Fatalf("round-tripped constraints = %+v", back.Constraints)
+	}
+}
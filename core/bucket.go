@@ -0,0 +1,246 @@
+// Package core implements the token bucket algorithm that backs SignalFence.
+package core
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Bucket is a single token bucket. Tokens refill continuously at a fixed
+// rate up to a maximum capacity. A Bucket is safe for concurrent use.
+//
+// The layout is deliberately compact — stores hold millions of buckets.
+// Timestamps are int64 nanoseconds rather than time.Time, and the refill
+// timestamp doubles as the last-access time, since every access refills.
+type Bucket struct {
+	mu           sync.Mutex
+	tokens       float64
+	capacity     float64
+	refillPerSec float64
+	// grace is how far tokens may go negative ("debt"). A client that
+	// slightly overshoots is still admitted and repays the debt from
+	// future refill instead of hitting a hard cliff.
+	grace float64
+	// lastNanos is the Unix nanosecond time of the last refill, which is
+	// also the last time the bucket was touched.
+	lastNanos int64
+	// taken is the cumulative tokens ever consumed, for reconcilers that
+	// report consumption deltas to a cross-region aggregate.
+	taken float64
+}
+
+// NewBucket returns a bucket that starts full with the given capacity and
+// refill rate (tokens per second).
+func NewBucket(capacity, refillPerSec float64) *Bucket {
+	return NewBucketAt(capacity, refillPerSec, time.Now())
+}
+
+// NewBucketAt is NewBucket with an explicit creation time, for callers
+// running on a virtual clock: refill accounting starts from now.
+func NewBucketAt(capacity, refillPerSec float64, now time.Time) *Bucket {
+	return &Bucket{
+		tokens:       capacity,
+		capacity:     capacity,
+		refillPerSec: refillPerSec,
+		lastNanos:    now.UnixNano(),
+	}
+}
+
+// refill credits tokens accrued since the last refill and stamps the
+// access time. Callers must hold mu.
+func (b *Bucket) refill(nowNanos int64) {
+	elapsed := nowNanos - b.lastNanos
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(b.capacity, b.tokens+float64(elapsed)/1e9*b.refillPerSec)
+	b.lastNanos = nowNanos
+}
+
+// Take attempts to remove cost tokens from the bucket at time now. It
+// reports whether the tokens were available, how many tokens remain, and,
+// when the take fails, how long the caller should wait before retrying.
+func (b *Bucket) Take(cost float64, now time.Time) (ok bool, remaining float64, retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now.UnixNano())
+
+	if b.tokens >= cost {
+		b.tokens -= cost
+		b.taken += cost
+		return true, b.tokens, 0
+	}
+	if b.tokens-cost >= -b.grace {
+		// Dip into the grace allowance; the debt is repaid by refill
+		// before any further request can pass.
+		b.tokens -= cost
+		b.taken += cost
+		return true, b.tokens, 0
+	}
+
+	deficit := cost - b.tokens
+	if b.refillPerSec > 0 {
+		retryAfter = time.Duration(deficit / b.refillPerSec * float64(time.Second))
+	}
+	return false, b.tokens, retryAfter
+}
+
+// TakeResult is one outcome from TakeBatch, mirroring Take's returns.
+type TakeResult struct {
+	OK         bool
+	Remaining  float64
+	RetryAfter time.Duration
+}
+
+// TakeBatch attempts the takes in order against the running balance under a
+// single lock acquisition, so a pipeline stage can admit a whole batch of
+// messages without contending on the bucket once per message. Denied takes
+// consume nothing and do not stop later, smaller takes from succeeding.
+func (b *Bucket) TakeBatch(costs []float64, now time.Time) []TakeResult {
+	results := make([]TakeResult, len(costs))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now.UnixNano())
+	for i, cost := range costs {
+		if b.tokens-cost >= -b.grace {
+			b.tokens -= cost
+			b.taken += cost
+			results[i] = TakeResult{OK: true, Remaining: b.tokens}
+			continue
+		}
+		r := TakeResult{Remaining: b.tokens}
+		if b.refillPerSec > 0 {
+			r.RetryAfter = time.Duration((cost - b.tokens) / b.refillPerSec * float64(time.Second))
+		}
+		results[i] = r
+	}
+	return results
+}
+
+// TakeAll takes cost tokens from every bucket or from none. All buckets are
+// locked together — in address order, so concurrent TakeAll calls over
+// overlapping sets cannot deadlock — then refilled and checked, and tokens
+// are deducted only when every bucket can afford the cost. Checking the
+// buckets one Take at a time instead would leak tokens from the early
+// buckets whenever a later one declines. It returns whether the take
+// happened, the smallest remaining balance, and the longest wait until the
+// bucket that declined could afford the cost.
+func TakeAll(buckets []*Bucket, cost float64, now time.Time) (ok bool, minRemaining float64, retryAfter time.Duration) {
+	sorted := make([]*Bucket, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool {
+		return uintptr(unsafe.Pointer(sorted[i])) < uintptr(unsafe.Pointer(sorted[j]))
+	})
+	// Drop duplicates so the same bucket is neither locked nor charged twice.
+	uniq := sorted[:0]
+	for i, b := range sorted {
+		if i == 0 || b != sorted[i-1] {
+			uniq = append(uniq, b)
+		}
+	}
+
+	for _, b := range uniq {
+		b.mu.Lock()
+	}
+	defer func() {
+		for _, b := range uniq {
+			b.mu.Unlock()
+		}
+	}()
+
+	nowNanos := now.UnixNano()
+	ok = true
+	minRemaining = math.Inf(1)
+	for _, b := range uniq {
+		b.refill(nowNanos)
+		if b.tokens-cost < -b.grace {
+			ok = false
+			if b.refillPerSec > 0 {
+				if wait := time.Duration((cost - b.tokens) / b.refillPerSec * float64(time.Second)); wait > retryAfter {
+					retryAfter = wait
+				}
+			}
+		}
+	}
+	for _, b := range uniq {
+		if ok {
+			b.tokens -= cost
+			b.taken += cost
+		}
+		if b.tokens < minRemaining {
+			minRemaining = b.tokens
+		}
+	}
+	if !ok {
+		return false, minRemaining, retryAfter
+	}
+	return true, minRemaining, 0
+}
+
+// ForceTake removes cost tokens unconditionally, allowing the balance to go
+// negative beyond the grace allowance. Byte-accounting callers use it to
+// charge for data that has already been transferred; the resulting debt is
+// repaid by refill before further takes succeed.
+func (b *Bucket) ForceTake(cost float64, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now.UnixNano())
+	b.tokens -= cost
+	b.taken += cost
+}
+
+// EnsureLimits updates the bucket's capacity, refill rate and grace
+// allowance if they differ from the given values, clamping stored tokens to
+// the new capacity. It is a cheap no-op when the limits already match, so
+// callers may invoke it on every request to apply config reloads to live
+// buckets.
+func (b *Bucket) EnsureLimits(capacity, refillPerSec, grace float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.capacity == capacity && b.refillPerSec == refillPerSec && b.grace == grace {
+		return
+	}
+	b.capacity = capacity
+	b.refillPerSec = refillPerSec
+	b.grace = grace
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+}
+
+// Peek returns the number of tokens currently available without consuming
+// any. It counts as an access for idle-expiry purposes.
+func (b *Bucket) Peek(now time.Time) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now.UnixNano())
+	return b.tokens
+}
+
+// Taken returns the cumulative tokens ever consumed from the bucket.
+// Reconcilers diff successive readings to report consumption deltas.
+func (b *Bucket) Taken() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.taken
+}
+
+// Capacity returns the bucket's maximum token count.
+func (b *Bucket) Capacity() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.capacity
+}
+
+// LastAccess returns the last time the bucket was used. Storage backends use
+// this to expire idle buckets.
+func (b *Bucket) LastAccess() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Unix(0, b.lastNanos)
+}
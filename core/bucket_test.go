@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketStartsFull(t *testing.T) {
+	b := NewBucket(10, 1)
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		ok, _, _ := b.Take(1, now)
+		if !ok {
+			t.Fatalf("take %d: want ok", i)
+		}
+	}
+	ok, _, retry := b.Take(1, now)
+	if ok {
+		t.Fatal("11th take: want rejection")
+	}
+	if retry <= 0 {
+		t.Fatalf("retry = %v, want > 0", retry)
+	}
+}
+
+func TestBucketRefills(t *testing.T) {
+	b := NewBucket(5, 2)
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		b.Take(1, now)
+	}
+	if ok, _, _ := b.Take(1, now); ok {
+		t.Fatal("want empty bucket")
+	}
+	// 2 tokens/sec: one second later there should be 2 tokens.
+	later := now.Add(time.Second)
+	if ok, remaining, _ := b.Take(1, later); !ok || remaining < 0.9 {
+		t.Fatalf("after refill: ok=%v remaining=%v", ok, remaining)
+	}
+}
+
+func TestBucketDoesNotOverfill(t *testing.T) {
+	b := NewBucket(3, 100)
+	now := time.Now()
+	if got := b.Peek(now.Add(time.Hour)); got != 3 {
+		t.Fatalf("Peek = %v, want capped at 3", got)
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGraceAllowsDebt(t *testing.T) {
+	b := NewBucket(3, 1)
+	b.EnsureLimits(3, 1, 2)
+	now := time.Now()
+
+	// Capacity 3 plus grace 2: five takes succeed, the sixth does not.
+	for i := 0; i < 5; i++ {
+		if ok, _, _ := b.Take(1, now); !ok {
+			t.Fatalf("take %d: want ok within grace", i)
+		}
+	}
+	if ok, _, _ := b.Take(1, now); ok {
+		t.Fatal("take beyond grace should fail")
+	}
+}
+
+func TestGraceDebtIsRepaidByRefill(t *testing.T) {
+	b := NewBucket(2, 1)
+	b.EnsureLimits(2, 1, 2)
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		b.Take(1, now)
+	}
+	// Tokens are now at -2. One second of refill brings them to -1, so a
+	// cost of 1 still fits above the grace floor of -2.
+	if ok, _, _ := b.Take(1, now.Add(time.Second)); !ok {
+		t.Fatal("one refilled token should fit within remaining grace")
+	}
+	if ok, _, _ := b.Take(1, now.Add(time.Second)); ok {
+		t.Fatal("grace is exhausted again, take should fail")
+	}
+}
+
+func TestZeroGraceKeepsHardCliff(t *testing.T) {
+	b := NewBucket(1, 1)
+	now := time.Now()
+	b.Take(1, now)
+	if ok, _, _ := b.Take(1, now); ok {
+		t.Fatal("without grace an empty bucket must reject")
+	}
+}
@@ -0,0 +1,36 @@
+package core
+
+import "time"
+
+// TokenBucket is the behaviour a store-held bucket must provide. *Bucket
+// is the canonical implementation; the interface exists so custom store
+// backends can return their own bucket types — remote counters, windowed
+// logs — and still plug into the limiter's keying, middleware and
+// metrics.
+type TokenBucket interface {
+	// Take attempts to remove cost tokens at time now, reporting whether
+	// they were available, the remaining balance and, when the take
+	// fails, how long to wait before retrying.
+	Take(cost float64, now time.Time) (ok bool, remaining float64, retryAfter time.Duration)
+	// TakeBatch attempts the takes in order against the running balance
+	// under a single lock acquisition.
+	TakeBatch(costs []float64, now time.Time) []TakeResult
+	// ForceTake removes cost tokens unconditionally, letting the balance
+	// go negative beyond the grace allowance.
+	ForceTake(cost float64, now time.Time)
+	// EnsureLimits updates capacity, refill rate and grace if they differ
+	// from the given values.
+	EnsureLimits(capacity, refillPerSec, grace float64)
+	// Peek returns the tokens currently available without consuming any.
+	Peek(now time.Time) float64
+	// Capacity returns the maximum token count.
+	Capacity() float64
+	// LastAccess returns the last time the bucket was used, for idle
+	// expiry.
+	LastAccess() time.Time
+	// Taken returns the cumulative tokens ever consumed, for consumption
+	// reconcilers.
+	Taken() float64
+}
+
+var _ TokenBucket = (*Bucket)(nil)
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// Property: across any schedule of takes, stored tokens stay within
+// [-grace, capacity]. Random costs, random inter-arrival gaps, virtual
+// time — no sleeps involved.
+func TestQuickTokensStayBounded(t *testing.T) {
+	prop := func(seed int64, cap8 uint8, rate8 uint8, grace8 uint8) bool {
+		rng := rand.New(rand.NewSource(seed))
+		capacity := float64(cap8%50) + 1
+		rate := float64(rate8%20) + 0.5
+		grace := float64(grace8 % 5)
+
+		b := NewBucket(capacity, rate)
+		b.EnsureLimits(capacity, rate, grace)
+
+		now := time.Now()
+		for i := 0; i < 200; i++ {
+			now = now.Add(time.Duration(rng.Intn(500)) * time.Millisecond)
+			cost := float64(rng.Intn(4)) + 0.5
+			b.Take(cost, now)
+			if tokens := b.Peek(now); tokens > capacity || tokens < -grace {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 50}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Property: the total cost admitted over any virtual interval T never
+// exceeds capacity + grace + rate*T — the fundamental token bucket bound.
+func TestQuickAdmissionNeverExceedsBudget(t *testing.T) {
+	prop := func(seed int64) bool {
+		rng := rand.New(rand.NewSource(seed))
+		capacity := float64(rng.Intn(30) + 1)
+		rate := float64(rng.Intn(10)) + 0.5
+		grace := float64(rng.Intn(3))
+
+		b := NewBucket(capacity, rate)
+		b.EnsureLimits(capacity, rate, grace)
+
+		start := time.Now()
+		now := start
+		admitted := 0.0
+		for i := 0; i < 500; i++ {
+			now = now.Add(time.Duration(rng.Intn(200)) * time.Millisecond)
+			cost := float64(rng.Intn(3)) + 1
+			if ok, _, _ := b.Take(cost, now); ok {
+				admitted += cost
+			}
+		}
+		budget := capacity + grace + rate*now.Sub(start).Seconds()
+		return admitted <= budget+1e-6
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 50}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Property: the admission bound also holds under real concurrency.
+func TestConcurrentAdmissionBound(t *testing.T) {
+	const (
+		capacity = 50.0
+		rate     = 100.0
+		workers  = 16
+		perG     = 2000
+	)
+	b := NewBucket(capacity, rate)
+
+	var admitted atomic.Int64
+	start := time.Now()
+	var wg sync.WaitGroup
+	for g := 0; g < workers; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				if ok, _, _ := b.Take(1, time.Now()); ok {
+					admitted.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start).Seconds()
+
+	budget := capacity + rate*elapsed + 1 // +1 for boundary slop
+	if got := float64(admitted.Load()); got > budget {
+		t.Fatalf("admitted %v requests, budget was %v over %.3fs", got, budget, elapsed)
+	}
+}
@@ -0,0 +1,16 @@
+package core
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// The store holds one Bucket per live key, so the struct size is a real
+// memory budget. This pins the packed layout: mutex + five float64 fields +
+// one int64 timestamp.
+func TestBucketSize(t *testing.T) {
+	const want = 56
+	if got := unsafe.Sizeof(Bucket{}); got != want {
+		t.Fatalf("sizeof(Bucket) = %d, want %d — check field packing before growing it", got, want)
+	}
+}
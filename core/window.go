@@ -0,0 +1,63 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// WindowLog is a sliding-window-log limiter for a single key: it admits at
+// most limit events per rolling window by remembering the timestamp of every
+// admitted event. Unlike a token bucket it is exact — "3 per hour" means
+// never more than 3 in any hour-long span — which suits very small limits
+// (password resets, verification emails) where approximation is not
+// acceptable. Memory is O(limit) per key. A WindowLog is safe for
+// concurrent use.
+type WindowLog struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	// times holds the Unix nanosecond timestamps of admitted events inside
+	// the window, oldest first.
+	times []int64
+}
+
+// NewWindowLog returns a log admitting limit events per window.
+func NewWindowLog(limit int, window time.Duration) *WindowLog {
+	return &WindowLog{limit: limit, window: window}
+}
+
+// prune drops timestamps that have aged out of the window ending at
+// nowNanos. Callers must hold mu.
+func (l *WindowLog) prune(nowNanos int64) {
+	cutoff := nowNanos - l.window.Nanoseconds()
+	i := 0
+	for i < len(l.times) && l.times[i] <= cutoff {
+		i++
+	}
+	if i > 0 {
+		l.times = append(l.times[:0], l.times[i:]...)
+	}
+}
+
+// Allow records one event at time now if fewer than limit events remain in
+// the window, reporting whether it was admitted and, if not, how long until
+// the oldest event ages out and a slot frees up.
+func (l *WindowLog) Allow(now time.Time) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	nowNanos := now.UnixNano()
+	l.prune(nowNanos)
+	if len(l.times) < l.limit {
+		l.times = append(l.times, nowNanos)
+		return true, 0
+	}
+	return false, time.Duration(l.times[0] + l.window.Nanoseconds() - nowNanos)
+}
+
+// Active returns how many admitted events remain inside the window at now.
+func (l *WindowLog) Active(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now.UnixNano())
+	return len(l.times)
+}
@@ -0,0 +1,149 @@
+package signalfence
+
+import "net/http"
+
+// DashboardHandler serves a self-contained operational dashboard: a
+// per-route table showing each route's effective policy, block rate and top
+// offenders, with an inline editor that writes policy overrides back
+// through the admin API. It expects AdminHandler mounted under /admin on
+// the same listener:
+//
+//	mux.Handle("/admin/", limiter.AdminHandler())
+//	mux.Handle("/dashboard", limiter.DashboardHandler())
+//
+// Like the admin API, it belongs on a trusted internal listener only — the
+// editor can change enforced limits.
+func (rl *RateLimiter) DashboardHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(dashboardHTML))
+	})
+}
+
+// dashboardHTML is the dashboard page. It is deliberately dependency-free
+// (no CDN scripts) so it works on air-gapped internal networks.
+const dashboardHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SignalFence</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+h1 { font-size: 1.3rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.4rem 0.6rem; text-align: left; font-size: 0.9rem; }
+th { background: #f4f4f4; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+input { width: 5rem; }
+button { margin-left: 0.3rem; }
+.offenders { color: #666; font-size: 0.8rem; }
+#status { margin: 0.6rem 0; color: #666; font-size: 0.85rem; }
+.hot { color: #b00; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>SignalFence routes</h1>
+<div id="status">loading&hellip;</div>
+<table>
+<thead><tr>
+<th>Route</th><th>Capacity</th><th>Refill/s</th><th>Requests</th>
+<th>Denied</th><th>Block rate</th><th>Top offenders</th><th></th>
+</tr></thead>
+<tbody id="routes"></tbody>
+</table>
+<script>
+"use strict";
+const status = (msg) => { document.getElementById("status").textContent = msg; };
+
+async function save(route, row) {
+  const body = {
+    capacity: parseInt(row.querySelector(".cap").value, 10) || 0,
+    refill_per_sec: parseFloat(row.querySelector(".refill").value) || 0,
+  };
+  const resp = await fetch("/admin/routes?route=" + encodeURIComponent(route), {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+  status(resp.ok ? "saved " + route : "save failed: " + await resp.text());
+  load();
+}
+
+async function clearOverride(route) {
+  await fetch("/admin/routes?route=" + encodeURIComponent(route), { method: "DELETE" });
+  status("cleared override for " + route);
+  load();
+}
+
+function cell(cls, text) {
+  const td = document.createElement("td");
+  if (cls) td.className = cls;
+  td.textContent = text;
+  return td;
+}
+
+function inputCell(cls, value) {
+  const td = document.createElement("td");
+  const input = document.createElement("input");
+  input.className = cls;
+  input.value = value;
+  td.appendChild(input);
+  return td;
+}
+
+function render(rows) {
+  const tbody = document.getElementById("routes");
+  tbody.textContent = "";
+  for (const r of rows) {
+    const route = r.route === "" ? "(default)" : r.route;
+    const tr = document.createElement("tr");
+    const rate = (r.block_rate * 100).toFixed(1) + "%";
+    const offenders = (r.offenders || [])
+      .map((o) => o.key + " (" + o.denied + ")").join(", ");
+    // Routes and offender keys are attacker-influenced (request paths,
+    // extracted header/claim values), so every cell is built with
+    // textContent — never markup — or a crafted request becomes script
+    // running against the policy editor.
+    tr.appendChild(cell("", route + (r.overridden ? " *" : "")));
+    tr.appendChild(inputCell("cap", r.policy.capacity));
+    tr.appendChild(inputCell("refill", r.policy.refill_per_sec));
+    tr.appendChild(cell("num", r.requests));
+    tr.appendChild(cell("num", r.denied));
+    tr.appendChild(cell("num" + (r.block_rate > 0.1 ? " hot" : ""), rate));
+    tr.appendChild(cell("offenders", offenders));
+    const actions = document.createElement("td");
+    const saveBtn = document.createElement("button");
+    saveBtn.textContent = "save";
+    saveBtn.onclick = () => save(r.route, tr);
+    const clearBtn = document.createElement("button");
+    clearBtn.textContent = "clear";
+    clearBtn.onclick = () => clearOverride(r.route);
+    actions.append(saveBtn, clearBtn);
+    tr.appendChild(actions);
+    tbody.appendChild(tr);
+  }
+}
+
+async function load() {
+  // Don't clobber an edit in progress with the periodic refresh.
+  if (document.activeElement && document.activeElement.tagName === "INPUT") return;
+  try {
+    const resp = await fetch("/admin/routes");
+    render(await resp.json());
+    status("updated " + new Date().toLocaleTimeString() +
+      " — * overridden; counters need TrackTraffic");
+  } catch (err) {
+    status("load failed: " + err);
+  }
+}
+
+load();
+setInterval(load, 5000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,177 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Decision forwarding headers. The X-RateLimit trio from the middleware
+// carries the client-facing view; these carry the full decision so an
+// internal hop can reconstruct it losslessly.
+const (
+	headerFenceAllowed = "X-Signalfence-Allowed"
+	headerFenceRetry   = "X-Signalfence-Retry-Ms"
+	headerFenceVersion = "X-Signalfence-Version"
+	headerFenceCohort  = "X-Signalfence-Cohort"
+	headerFenceBanned  = "X-Signalfence-Banned"
+	headerFenceMode    = "X-Signalfence-Mode"
+	headerFenceWould   = "X-Signalfence-Wouldblock"
+)
+
+// decisionWire is Decision's serialized form: snake_case names, the retry
+// hint in integer milliseconds, and the mode by name, matching what the
+// admin API exports elsewhere.
+type decisionWire struct {
+	Allowed       bool             `json:"allowed"`
+	Limit         int              `json:"limit"`
+	Remaining     int              `json:"remaining"`
+	RetryAfterMS  int64            `json:"retry_after_ms,omitempty"`
+	ConfigVersion int              `json:"config_version"`
+	Cohort        string           `json:"cohort,omitempty"`
+	Warning       bool             `json:"warning,omitempty"`
+	Banned        bool             `json:"banned,omitempty"`
+	Mode          string           `json:"mode"`
+	WouldBlock    bool             `json:"would_block,omitempty"`
+	Constraints   []constraintWire `json:"constraints,omitempty"`
+}
+
+// constraintWire is ConstraintResult's serialized form, with the retry
+// hint in integer milliseconds like the decision's.
+type constraintWire struct {
+	Name         string `json:"name"`
+	Allowed      bool   `json:"allowed"`
+	Limit        int    `json:"limit"`
+	Remaining    int    `json:"remaining"`
+	RetryAfterMS int64  `json:"retry_after_ms,omitempty"`
+}
+
+func constraintsToWire(crs []ConstraintResult) []constraintWire {
+	if len(crs) == 0 {
+		return nil
+	}
+	out := make([]constraintWire, len(crs))
+	for i, c := range crs {
+		out[i] = constraintWire{
+			Name:         c.Name,
+			Allowed:      c.Allowed,
+			Limit:        c.Limit,
+			Remaining:    c.Remaining,
+			RetryAfterMS: c.RetryAfter.Milliseconds(),
+		}
+	}
+	return out
+}
+
+func constraintsFromWire(crs []constraintWire) []ConstraintResult {
+	if len(crs) == 0 {
+		return nil
+	}
+	out := make([]ConstraintResult, len(crs))
+	for i, c := range crs {
+		out[i] = ConstraintResult{
+			Name:       c.Name,
+			Allowed:    c.Allowed,
+			Limit:      c.Limit,
+			Remaining:  c.Remaining,
+			RetryAfter: time.Duration(c.RetryAfterMS) * time.Millisecond,
+		}
+	}
+	return out
+}
+
+// MarshalJSON serializes the decision in its wire form, so an edge service
+// can forward decisions to internal services or clients as JSON.
+func (d Decision) MarshalJSON() ([]byte, error) {
+	return json.Marshal(decisionWire{
+		Allowed:       d.Allowed,
+		Limit:         d.Limit,
+		Remaining:     d.Remaining,
+		RetryAfterMS:  d.RetryAfter.Milliseconds(),
+		ConfigVersion: d.ConfigVersion,
+		Cohort:        d.Cohort,
+		Warning:       d.Warning,
+		Banned:        d.Banned,
+		Mode:          d.Mode.String(),
+		WouldBlock:    d.WouldBlock,
+		Constraints:   constraintsToWire(d.Constraints),
+	})
+}
+
+// UnmarshalJSON restores a decision from its wire form.
+func (d *Decision) UnmarshalJSON(data []byte) error {
+	var w decisionWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	mode, _ := parseMode(w.Mode)
+	*d = Decision{
+		Allowed:       w.Allowed,
+		Limit:         w.Limit,
+		Remaining:     w.Remaining,
+		RetryAfter:    time.Duration(w.RetryAfterMS) * time.Millisecond,
+		ConfigVersion: w.ConfigVersion,
+		Cohort:        w.Cohort,
+		Warning:       w.Warning,
+		Banned:        w.Banned,
+		Mode:          mode,
+		WouldBlock:    w.WouldBlock,
+		Constraints:   constraintsFromWire(w.Constraints),
+	}
+	return nil
+}
+
+// WriteHeaders stamps the full decision onto a response or proxied
+// request: the client-facing X-RateLimit trio plus the X-Signalfence
+// headers DecisionFromHeaders reads back.
+func (d Decision) WriteHeaders(w http.ResponseWriter) {
+	writeLimitHeaders(w, d)
+	h := w.Header()
+	h[headerFenceAllowed] = []string{boolHeader(d.Allowed)}
+	h[headerFenceVersion] = []string{itoa(d.ConfigVersion)}
+	h[headerFenceMode] = []string{d.Mode.String()}
+	if d.RetryAfter > 0 {
+		h[headerFenceRetry] = []string{strconv.FormatInt(d.RetryAfter.Milliseconds(), 10)}
+	}
+	if d.Cohort != "" {
+		h[headerFenceCohort] = []string{d.Cohort}
+	}
+	if d.Banned {
+		h[headerFenceBanned] = []string{"1"}
+	}
+	if d.WouldBlock {
+		h[headerFenceWould] = []string{"1"}
+	}
+}
+
+// DecisionFromHeaders reconstructs a decision written by WriteHeaders. It
+// reports false when the headers carry no decision.
+func DecisionFromHeaders(h http.Header) (Decision, bool) {
+	allowed := h.Get(headerFenceAllowed)
+	if allowed == "" {
+		return Decision{}, false
+	}
+	var d Decision
+	d.Allowed = allowed == "1"
+	d.Limit, _ = strconv.Atoi(h.Get(headerLimit))
+	d.Remaining, _ = strconv.Atoi(h.Get(headerRemaining))
+	d.ConfigVersion, _ = strconv.Atoi(h.Get(headerFenceVersion))
+	if ms, err := strconv.ParseInt(h.Get(headerFenceRetry), 10, 64); err == nil {
+		d.RetryAfter = time.Duration(ms) * time.Millisecond
+	}
+	d.Cohort = h.Get(headerFenceCohort)
+	d.Warning = h.Get(headerWarning) != ""
+	d.Banned = h.Get(headerFenceBanned) == "1"
+	d.Mode, _ = parseMode(h.Get(headerFenceMode))
+	d.WouldBlock = h.Get(headerFenceWould) == "1"
+	return d, true
+}
+
+// boolHeader renders a bool as the "1"/"0" header form.
+func boolHeader(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
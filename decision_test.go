@@ -0,0 +1,75 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecisionJSONRoundTrip(t *testing.T) {
+	d := Decision{
+		Allowed:       false,
+		Limit:         100,
+		Remaining:     0,
+		RetryAfter:    1500 * time.Millisecond,
+		ConfigVersion: 3,
+		Cohort:        CohortCanary,
+		Warning:       true,
+		Banned:        true,
+		Mode:          ModeObserve,
+		WouldBlock:    true,
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal = %v", err)
+	}
+	var got Decision
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal = %v", err)
+	}
+	if !reflect.DeepEqual(d, got) {
+		t.Fatalf("round trip changed decision:\n in  %+v\n out %+v", d, got)
+	}
+	// The wire names are part of the contract with non-Go consumers.
+	var wire map[string]any
+	json.Unmarshal(data, &wire)
+	if wire["mode"] != "observe" || wire["retry_after_ms"] != float64(1500) {
+		t.Fatalf("wire form = %v", wire)
+	}
+}
+
+func TestDecisionHeaderRoundTrip(t *testing.T) {
+	d := Decision{
+		Allowed:       true,
+		Limit:         10,
+		Remaining:     4,
+		ConfigVersion: 2,
+		Warning:       true,
+		Mode:          ModeEnforce,
+	}
+	rec := httptest.NewRecorder()
+	d.WriteHeaders(rec)
+
+	got, ok := DecisionFromHeaders(rec.Header())
+	if !ok {
+		t.Fatal("headers carried no decision")
+	}
+	if !reflect.DeepEqual(d, got) {
+		t.Fatalf("round trip changed decision:\n in  %+v\n out %+v", d, got)
+	}
+
+	// Denials carry the retry hint at millisecond fidelity.
+	denied := Decision{Limit: 10, RetryAfter: 2500 * time.Millisecond, Banned: true}
+	rec = httptest.NewRecorder()
+	denied.WriteHeaders(rec)
+	got, _ = DecisionFromHeaders(rec.Header())
+	if got.RetryAfter != 2500*time.Millisecond || !got.Banned {
+		t.Fatalf("denial round trip = %+v", got)
+	}
+
+	if _, ok := DecisionFromHeaders(httptest.NewRecorder().Header()); ok {
+		t.Fatal("empty headers produced a decision")
+	}
+}
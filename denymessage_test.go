@@ -0,0 +1,164 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func drainRoute(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = "10.5.5.5:999"
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status after drain = %d, want 429", rec.Code)
+	}
+	return rec
+}
+
+func TestDenyPolicyMessage(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Routes: map[string]Policy{
+			"/login": {
+				Capacity:     1,
+				RefillPerSec: 0.001,
+				Message:      "Login attempts limited",
+				DocsURL:      "https://docs.example.com/limits#login",
+			},
+		},
+	})
+	h := rl.Middleware(http.NotFoundHandler())
+
+	rec := drainRoute(t, h, "/login")
+	if body := rec.Body.String(); !strings.Contains(body, "Login attempts limited") ||
+		!strings.Contains(body, "https://docs.example.com/limits#login") {
+		t.Fatalf("denial body = %q", body)
+	}
+	if link := rec.Header().Get("Link"); link != `<https://docs.example.com/limits#login>; rel="help"` {
+		t.Fatalf("Link header = %q", link)
+	}
+}
+
+func TestDenyPolicyMessageProblemJSON(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		ProblemJSON: true,
+		Routes: map[string]Policy{
+			"/login": {
+				Capacity:     1,
+				RefillPerSec: 0.001,
+				Message:      "Login attempts limited",
+				DocsURL:      "https://docs.example.com/limits#login",
+			},
+		},
+	})
+	h := rl.Middleware(http.NotFoundHandler())
+
+	rec := drainRoute(t, h, "/login")
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Detail != "Login attempts limited" {
+		t.Fatalf("Detail = %q", p.Detail)
+	}
+	if p.Docs != "https://docs.example.com/limits#login" {
+		t.Fatalf("Docs = %q", p.Docs)
+	}
+}
+
+func TestDenyDefaultMessageUnchanged(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	rec := drainRoute(t, rl.Middleware(http.NotFoundHandler()), "/")
+	if body := strings.TrimSpace(rec.Body.String()); body != "429 Too Many Requests" {
+		t.Fatalf("default denial body = %q", body)
+	}
+	if rec.Header().Get("Link") != "" {
+		t.Fatal("Link header set without a DocsURL")
+	}
+}
+
+func TestDeniedMessageFuncLocalizes(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     1,
+		RefillPerSec: 0.001,
+		DeniedMessageFunc: func(r *http.Request, d *Decision) string {
+			if strings.HasPrefix(r.Header.Get("Accept-Language"), "fr") {
+				return "Limite de débit dépassée"
+			}
+			return ""
+		},
+	})
+	h := rl.Middleware(http.NotFoundHandler())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.6.6.6:999"
+	req.Header.Set("Accept-Language", "fr-FR")
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != "Limite de débit dépassée" {
+		t.Fatalf("localized body = %q", body)
+	}
+
+	// Returning "" falls back to the generic body.
+	rec = drainRoute(t, h, "/other")
+	if body := strings.TrimSpace(rec.Body.String()); body != "429 Too Many Requests" {
+		t.Fatalf("fallback body = %q", body)
+	}
+}
+
+func TestDenialCacheHeaders(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:          1,
+		RefillPerSec:      0.001,
+		DenialVary:        []string{"Authorization"},
+		DeniedMessageFunc: func(*http.Request, *Decision) string { return "" },
+	})
+	rec := drainRoute(t, rl.Middleware(http.NotFoundHandler()), "/")
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Fatalf("Cache-Control = %q", cc)
+	}
+	vary := rec.Header().Values("Vary")
+	if len(vary) != 2 || vary[0] != "Accept-Language" || vary[1] != "Authorization" {
+		t.Fatalf("Vary = %v", vary)
+	}
+
+	// Allowed responses are not stamped.
+	ok := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/elsewhere", nil)
+	req.RemoteAddr = "10.7.7.7:999"
+	rl.Middleware(http.NotFoundHandler()).ServeHTTP(ok, req)
+	if ok.Header().Get("Cache-Control") != "" {
+		t.Fatal("Cache-Control set on allowed response")
+	}
+
+	optOut := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001, NoDenialCacheHeaders: true})
+	rec = drainRoute(t, optOut.Middleware(http.NotFoundHandler()), "/")
+	if rec.Header().Get("Cache-Control") != "" {
+		t.Fatal("Cache-Control set despite opt-out")
+	}
+}
+
+func TestEdgeAbsorbDenials(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.5, EdgeAbsorbDenials: true})
+	rec := drainRoute(t, rl.Middleware(http.NotFoundHandler()), "/")
+	sc := rec.Header().Get("Surrogate-Control")
+	if sc != "max-age="+rec.Header().Get("Retry-After") {
+		t.Fatalf("Surrogate-Control = %q, Retry-After = %q", sc, rec.Header().Get("Retry-After"))
+	}
+
+	off := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.5})
+	rec = drainRoute(t, off.Middleware(http.NotFoundHandler()), "/")
+	if rec.Header().Get("Surrogate-Control") != "" {
+		t.Fatal("Surrogate-Control set without opt-in")
+	}
+}
@@ -0,0 +1,54 @@
+package signalfence
+
+import (
+	"sync"
+	"time"
+)
+
+// escalation is a temporary per-key policy override, applied by abuse
+// detectors to clamp down on a single client without touching config.
+type escalation struct {
+	pol   Policy
+	until time.Time
+}
+
+// escalations tracks per-key overrides with lazy expiry.
+type escalations struct {
+	keys sync.Map // key string -> escalation
+}
+
+func (e *escalations) set(key string, pol Policy, ttl time.Duration) {
+	e.keys.Store(key, escalation{pol: pol, until: time.Now().Add(ttl)})
+}
+
+func (e *escalations) get(key string) (Policy, bool) {
+	v, ok := e.keys.Load(key)
+	if !ok {
+		return Policy{}, false
+	}
+	esc := v.(escalation)
+	if time.Now().After(esc.until) {
+		e.keys.Delete(key)
+		return Policy{}, false
+	}
+	return esc.pol, true
+}
+
+// Escalate applies pol to key for the next ttl, overriding the policy that
+// would otherwise be in effect on any route. Zero fields of pol inherit
+// from the overridden policy. Calling Escalate again replaces the previous
+// override.
+func (rl *RateLimiter) Escalate(key string, pol Policy, ttl time.Duration) {
+	rl.escalated.set(key, pol, ttl)
+}
+
+// Deescalate removes any escalation for key before its TTL expires.
+func (rl *RateLimiter) Deescalate(key string) {
+	rl.escalated.keys.Delete(key)
+}
+
+// Escalated reports whether key currently has an active escalation.
+func (rl *RateLimiter) Escalated(key string) bool {
+	_, ok := rl.escalated.get(key)
+	return ok
+}
@@ -0,0 +1,148 @@
+package signalfence
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ExplainStep is one stage of the decision pipeline as Explain walked it.
+// Applied reports whether the stage changed the outcome for this request;
+// Detail says what it saw either way.
+type ExplainStep struct {
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Explanation is the dry run's verdict: the key the extractor produced, the
+// route the path resolved to, the policy left standing after every
+// adjustment, the decision the request would receive right now, and the
+// pipeline stages in evaluation order. FailedConstraint names what denied
+// the request ("ban", "rate", "quota"), or is empty when it would be
+// allowed.
+type Explanation struct {
+	Key              string        `json:"key"`
+	Route            string        `json:"route"`
+	Policy           Policy        `json:"policy"`
+	Decision         Decision      `json:"decision"`
+	FailedConstraint string        `json:"failed_constraint,omitempty"`
+	Steps            []ExplainStep `json:"steps"`
+}
+
+// Explain re-runs key extraction and policy resolution for r without
+// consuming tokens or touching any statistics, and reports what each
+// pipeline stage contributed — the support tool for "why was this request
+// limited?". The decision comes from a peek, so an Explain immediately
+// after a real request shows the state that request left behind.
+func (rl *RateLimiter) Explain(r *http.Request) (*Explanation, error) {
+	if r == nil || r.URL == nil {
+		return nil, errors.New("signalfence: Explain requires a request with a URL")
+	}
+	key := rl.keyFunc(r)
+	route := rl.routeOf(r.URL.Path)
+	exp := &Explanation{Key: key, Route: route}
+	step := func(name string, applied bool, format string, args ...any) {
+		exp.Steps = append(exp.Steps, ExplainStep{Name: name, Applied: applied, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	rl.mu.RLock()
+	custom := rl.cfg.KeyFunc != nil
+	_, overridden := rl.cfg.Routes[route]
+	can, hasCanary := rl.cfg.Canaries[route]
+	flag := rl.cfg.PolicyFlag
+	rl.mu.RUnlock()
+
+	extractor := "client_ip"
+	if custom {
+		extractor = "custom"
+	}
+	step("key", true, "%s extractor -> %q", extractor, key)
+	if route != "" {
+		step("route", true, "%s -> %s", r.URL.Path, route)
+	} else {
+		step("route", false, "%s matched no route; default policy", r.URL.Path)
+	}
+
+	pol := rl.EffectivePolicy(route)
+	step("policy", overridden, "capacity %d, refill %g/s (override: %v)",
+		pol.Capacity, pol.RefillPerSec, overridden)
+	if _, ok := rl.classPolicy(key); ok {
+		pol = rl.effectivePolicyForKey(key, "", route)
+		step("key_class", true, "class %q default: capacity %d, refill %g/s",
+			keyClass(key), pol.Capacity, pol.RefillPerSec)
+	}
+	if _, ok := rl.methodDefault(r.Method); ok {
+		pol = rl.effectivePolicyForKey(key, r.Method, route)
+		kind := "write"
+		if isReadMethod(r.Method) {
+			kind = "read"
+		}
+		step("method_default", true, "%s default for %s: capacity %d, refill %g/s",
+			kind, r.Method, pol.Capacity, pol.RefillPerSec)
+	}
+
+	mode := rl.Mode()
+	step("mode", mode != ModeEnforce, "mode %s", mode)
+
+	left, banned := rl.banRemaining(key)
+	if banned {
+		step("ban", true, "banned for another %s", left)
+	} else {
+		step("ban", false, "not banned")
+	}
+
+	if hasCanary {
+		if inCanary(key, can.Percent) {
+			pol = mergePolicy(can.Policy, pol)
+			step("canary", true, "key in the %d%% canary cohort", can.Percent)
+		} else {
+			step("canary", false, "key in the stable cohort (%d%% canary)", can.Percent)
+		}
+	}
+	if flag != nil {
+		if varPol, flagged := flag(key, route, pol); flagged {
+			pol = mergePolicy(varPol, pol)
+			step("policy_flag", true, "flag variation: capacity %d, refill %g/s", pol.Capacity, pol.RefillPerSec)
+		} else {
+			step("policy_flag", false, "flag returned no variation")
+		}
+	}
+	if sesPol, ok := rl.sessionPolicy(key); ok {
+		pol = mergePolicy(sesPol, pol)
+		step("session", true, "session policy: capacity %d, refill %g/s", pol.Capacity, pol.RefillPerSec)
+	}
+	if trustPol, ok := rl.trustPolicy(key); ok {
+		pol = mergePolicy(trustPol, pol)
+		step("trust", true, "trust level %d: capacity %d, refill %g/s", rl.TrustLevel(key), pol.Capacity, pol.RefillPerSec)
+	}
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+		step("escalation", true, "escalated: capacity %d, refill %g/s", pol.Capacity, pol.RefillPerSec)
+	}
+	if clamped := rl.clampPolicy(pol); clamped != pol {
+		pol = clamped
+		step("clampdown", true, "clamped to capacity %d, refill %g/s", pol.Capacity, pol.RefillPerSec)
+	}
+	if warmed := rl.warmupPolicy(pol); warmed != pol {
+		pol = warmed
+		step("warmup", true, "ramped to capacity %d, refill %g/s", pol.Capacity, pol.RefillPerSec)
+	}
+	exp.Policy = pol
+
+	d := rl.allowRoute(key, r.Method, route, 1, true)
+	exp.Decision = d
+	step("tokens", !d.Allowed, "%d of %d tokens remaining", d.Remaining, d.Limit)
+
+	if d.Banned {
+		exp.FailedConstraint = "ban"
+	} else if !d.Allowed {
+		exp.FailedConstraint = ConstraintRate
+	}
+	for _, c := range d.Constraints {
+		if !c.Allowed {
+			exp.FailedConstraint = c.Name
+		}
+	}
+	return exp, nil
+}
@@ -0,0 +1,123 @@
+package signalfence
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func explainStep(t *testing.T, exp *Explanation, name string) ExplainStep {
+	t.Helper()
+	for _, s := range exp.Steps {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no %q step in %+v", name, exp.Steps)
+	return ExplainStep{}
+}
+
+func TestExplainAllowed(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 1,
+		Routes:       map[string]Policy{"/api/data": {Capacity: 5, RefillPerSec: 1}},
+	})
+	defer rl.Close()
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "5.5.5.5:1"
+	exp, err := rl.Explain(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Key != "5.5.5.5" || exp.Route != "/api/data" {
+		t.Fatalf("key = %q, route = %q", exp.Key, exp.Route)
+	}
+	if !exp.Decision.Allowed || exp.FailedConstraint != "" {
+		t.Fatalf("decision = %+v, failed = %q", exp.Decision, exp.FailedConstraint)
+	}
+	if exp.Policy.Capacity != 5 {
+		t.Fatalf("policy = %+v", exp.Policy)
+	}
+	if s := explainStep(t, exp, "policy"); !s.Applied {
+		t.Fatalf("route override not applied: %+v", s)
+	}
+	if s := explainStep(t, exp, "ban"); s.Applied {
+		t.Fatalf("ban applied: %+v", s)
+	}
+}
+
+func TestExplainConsumesNothing(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001})
+	defer rl.Close()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "5.5.5.5:1"
+	first, err := rl.Explain(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := rl.Explain(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Decision.Remaining != second.Decision.Remaining {
+		t.Fatalf("remaining drifted: %d then %d",
+			first.Decision.Remaining, second.Decision.Remaining)
+	}
+}
+
+func TestExplainRateDenial(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	defer rl.Close()
+
+	rl.Allow("5.5.5.5")
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "5.5.5.5:1"
+	exp, err := rl.Explain(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Decision.Allowed || exp.FailedConstraint != ConstraintRate {
+		t.Fatalf("decision = %+v, failed = %q", exp.Decision, exp.FailedConstraint)
+	}
+	if s := explainStep(t, exp, "tokens"); !s.Applied {
+		t.Fatalf("tokens step not marked: %+v", s)
+	}
+}
+
+func TestExplainBanAndEscalation(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	defer rl.Close()
+
+	rl.Ban("5.5.5.5", time.Minute)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "5.5.5.5:1"
+	exp, err := rl.Explain(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.FailedConstraint != "ban" || !explainStep(t, exp, "ban").Applied {
+		t.Fatalf("failed = %q, steps = %+v", exp.FailedConstraint, exp.Steps)
+	}
+
+	rl.Unban("5.5.5.5")
+	rl.Escalate("5.5.5.5", Policy{Capacity: 2, RefillPerSec: 0.1}, time.Minute)
+	exp, err = rl.Explain(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !explainStep(t, exp, "escalation").Applied || exp.Policy.Capacity != 2 {
+		t.Fatalf("escalation missing: %+v", exp)
+	}
+}
+
+func TestExplainNilRequest(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 1})
+	defer rl.Close()
+
+	if _, err := rl.Explain(nil); err == nil {
+		t.Fatal("want error for nil request")
+	}
+}
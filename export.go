@@ -0,0 +1,65 @@
+package signalfence
+
+import "time"
+
+// BucketExport is the retained state of one token bucket.
+type BucketExport struct {
+	Remaining  float64   `json:"remaining"`
+	Capacity   float64   `json:"capacity"`
+	Taken      float64   `json:"taken"`
+	LastAccess time.Time `json:"last_access"`
+}
+
+// EscalationExport describes an active policy escalation.
+type EscalationExport struct {
+	Policy Policy    `json:"policy"`
+	Until  time.Time `json:"until"`
+}
+
+// KeyExport is everything the limiter retains about one client
+// identifier, in a JSON-serializable form.
+type KeyExport struct {
+	Key           string            `json:"key"`
+	Bucket        *BucketExport     `json:"bucket,omitempty"`
+	Traffic       *TrafficStats     `json:"traffic,omitempty"`
+	SessionPolicy *Policy           `json:"session_policy,omitempty"`
+	TrustLevel    int               `json:"trust_level,omitempty"`
+	Escalation    *EscalationExport `json:"escalation,omitempty"`
+	BannedUntil   *time.Time        `json:"banned_until,omitempty"`
+	AbuseScore    float64           `json:"abuse_score"`
+}
+
+// ExportKeyData gathers every subsystem's retained state about key —
+// bucket accounting, traffic counters, session policy, trust grant,
+// escalation, ban and the derived abuse score — for GDPR subject-access
+// requests. Streamed observability (the access log) is not covered; it
+// lives wherever the log sink put it.
+func (rl *RateLimiter) ExportKeyData(key string) KeyExport {
+	out := KeyExport{Key: key, AbuseScore: rl.AbuseScore(key)}
+	if b, ok := rl.store.Load(key); ok {
+		out.Bucket = &BucketExport{
+			Remaining:  b.Peek(rl.now()),
+			Capacity:   b.Capacity(),
+			Taken:      b.Taken(),
+			LastAccess: b.LastAccess(),
+		}
+	}
+	if s := rl.traffic.snapshot(key); s.Requests > 0 {
+		out.Traffic = &s
+	}
+	if pol, ok := rl.sessionPolicy(key); ok {
+		out.SessionPolicy = &pol
+	}
+	out.TrustLevel = rl.TrustLevel(key)
+	if v, ok := rl.escalated.keys.Load(key); ok {
+		esc := v.(escalation)
+		if time.Now().Before(esc.until) {
+			out.Escalation = &EscalationExport{Policy: esc.pol, Until: esc.until}
+		}
+	}
+	if left, banned := rl.banRemaining(key); banned {
+		until := time.Now().Add(left)
+		out.BannedUntil = &until
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestExportKeyData(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 5, RefillPerSec: 0.001, TrackTraffic: true})
+
+	rl.Allow("k")
+	rl.traffic.record("k", true, 100, 200)
+	rl.Trust("k", 2)
+	rl.Escalate("k", Policy{Capacity: 1}, time.Minute)
+	rl.Ban("k", time.Minute)
+
+	ex := rl.ExportKeyData("k")
+	if ex.Key != "k" {
+		t.Fatalf("Key = %q", ex.Key)
+	}
+	if ex.Bucket == nil || ex.Bucket.Taken != 1 {
+		t.Fatalf("Bucket = %+v", ex.Bucket)
+	}
+	if ex.Traffic == nil || ex.Traffic.BytesIn != 100 {
+		t.Fatalf("Traffic = %+v", ex.Traffic)
+	}
+	if ex.TrustLevel != 2 {
+		t.Fatalf("TrustLevel = %d", ex.TrustLevel)
+	}
+	if ex.Escalation == nil || ex.Escalation.Policy.Capacity != 1 {
+		t.Fatalf("Escalation = %+v", ex.Escalation)
+	}
+	if ex.BannedUntil == nil {
+		t.Fatal("BannedUntil missing")
+	}
+	if ex.AbuseScore != 1 {
+		t.Fatalf("AbuseScore = %v", ex.AbuseScore)
+	}
+	if _, err := json.Marshal(ex); err != nil {
+		t.Fatalf("export not serializable: %v", err)
+	}
+}
+
+func TestExportKeyDataUnknownKey(t *testing.T) {
+	rl := NewRateLimiter(Config{})
+	ex := rl.ExportKeyData("ghost")
+	if ex.Bucket != nil || ex.Traffic != nil || ex.SessionPolicy != nil ||
+		ex.Escalation != nil || ex.BannedUntil != nil || ex.TrustLevel != 0 {
+		t.Fatalf("unknown key export not empty: %+v", ex)
+	}
+}
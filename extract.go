@@ -0,0 +1,58 @@
+package signalfence
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ipPrefix is the interned key-class prefix for IP-derived keys.
+const ipPrefix = "ip:"
+
+// hostOnly strips the port from a RemoteAddr-style address without
+// allocating: "1.2.3.4:56" -> "1.2.3.4", "[::1]:80" -> "::1". Addresses
+// without a port come back unchanged.
+func hostOnly(addr string) string {
+	if addr == "" {
+		return addr
+	}
+	if addr[0] == '[' { // bracketed IPv6 with port
+		if end := strings.IndexByte(addr, ']'); end > 0 {
+			return addr[1:end]
+		}
+		return addr
+	}
+	colon := strings.IndexByte(addr, ':')
+	if colon < 0 {
+		return addr
+	}
+	if strings.IndexByte(addr[colon+1:], ':') >= 0 {
+		return addr // bare IPv6, the colons are part of the address
+	}
+	return addr[:colon]
+}
+
+// ExtractIP returns the key "ip:<client IP>" from RemoteAddr. It performs
+// a single allocation — the key string itself — making it suitable as the
+// per-request hot path extractor.
+func ExtractIP(r *http.Request) string {
+	return ipPrefix + hostOnly(r.RemoteAddr)
+}
+
+// ExtractIPWithProxy is ExtractIP for deployments behind a trusted reverse
+// proxy: it prefers the first address in X-Forwarded-For and falls back to
+// RemoteAddr. Only use it when the proxy strips client-supplied
+// X-Forwarded-For headers, otherwise keys are trivially spoofable.
+func ExtractIPWithProxy(r *http.Request) string {
+	fwd := r.Header.Get("X-Forwarded-For")
+	if fwd == "" {
+		return ExtractIP(r)
+	}
+	if comma := strings.IndexByte(fwd, ','); comma >= 0 {
+		fwd = fwd[:comma]
+	}
+	fwd = strings.TrimSpace(fwd)
+	if fwd == "" {
+		return ExtractIP(r)
+	}
+	return ipPrefix + fwd
+}
@@ -0,0 +1,71 @@
+package signalfence
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostOnly(t *testing.T) {
+	cases := map[string]string{
+		"1.2.3.4:56":        "1.2.3.4",
+		"1.2.3.4":           "1.2.3.4",
+		"[::1]:8080":        "::1",
+		"::1":               "::1",
+		"2001:db8::1":       "2001:db8::1",
+		"[2001:db8::1]:443": "2001:db8::1",
+		"":                  "",
+	}
+	for in, want := range cases {
+		if got := hostOnly(in); got != want {
+			t.Errorf("hostOnly(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExtractIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.1.2.3:4444"
+	if got := ExtractIP(r); got != "ip:10.1.2.3" {
+		t.Fatalf("ExtractIP = %q", got)
+	}
+}
+
+func TestExtractIPWithProxy(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:80"
+
+	if got := ExtractIPWithProxy(r); got != "ip:10.0.0.1" {
+		t.Fatalf("without forwarded header: %q", got)
+	}
+
+	r.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	if got := ExtractIPWithProxy(r); got != "ip:203.0.113.9" {
+		t.Fatalf("with forwarded header: %q", got)
+	}
+
+	r.Header.Set("X-Forwarded-For", " ,")
+	if got := ExtractIPWithProxy(r); got != "ip:10.0.0.1" {
+		t.Fatalf("with empty forwarded value: %q", got)
+	}
+}
+
+func BenchmarkExtractIP(b *testing.B) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.55:61234"
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ExtractIP(r)
+	}
+}
+
+func BenchmarkExtractIPWithProxy(b *testing.B) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.55:61234"
+	r.Header.Set("X-Forwarded-For", "203.0.113.9, 192.0.2.55")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ExtractIPWithProxy(r)
+	}
+}
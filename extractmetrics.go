@@ -0,0 +1,104 @@
+package signalfence
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ExtractorStats counts one extractor's outcomes: how often it keyed a
+// request, and how often it could not and the request fell through to the
+// next candidate.
+type ExtractorStats struct {
+	Hits      uint64 `json:"hits"`
+	Fallbacks uint64 `json:"fallbacks"`
+}
+
+// extractorCounters is the live, atomically updated form of ExtractorStats.
+type extractorCounters struct {
+	hits      atomic.Uint64
+	fallbacks atomic.Uint64
+}
+
+// ExtractorMetrics records per-extractor outcome counters, labeled by
+// extractor name. A silently decaying extractor — a renamed header sending
+// traffic to its IP fallback — shows up as a fallback ratio here instead
+// of being discovered weeks later in incident review.
+type ExtractorMetrics struct {
+	counters sync.Map // name string -> *extractorCounters
+}
+
+// counter returns name's counters, creating them on first use.
+func (m *ExtractorMetrics) counter(name string) *extractorCounters {
+	if c, ok := m.counters.Load(name); ok {
+		return c.(*extractorCounters)
+	}
+	c, _ := m.counters.LoadOrStore(name, &extractorCounters{})
+	return c.(*extractorCounters)
+}
+
+// Snapshot returns the current counters by extractor name.
+func (m *ExtractorMetrics) Snapshot() map[string]ExtractorStats {
+	out := make(map[string]ExtractorStats)
+	m.counters.Range(func(k, v any) bool {
+		c := v.(*extractorCounters)
+		out[k.(string)] = ExtractorStats{Hits: c.hits.Load(), Fallbacks: c.fallbacks.Load()}
+		return true
+	})
+	return out
+}
+
+// NamedExtractor is one candidate in a composite chain. Extract returns
+// the request's key, or "" when this candidate cannot key the request and
+// the next one should run.
+type NamedExtractor struct {
+	Name    string
+	Extract func(*http.Request) string
+}
+
+// HeaderExtractor returns a NamedExtractor keying requests by a header
+// value under the given key-class prefix. It yields "" — falling through
+// to the next candidate — when the header is absent or empty.
+func HeaderExtractor(header, class string) NamedExtractor {
+	prefix := class + ":"
+	return NamedExtractor{
+		Name: "header:" + header,
+		Extract: func(r *http.Request) string {
+			v := r.Header.Get(header)
+			if v == "" {
+				return ""
+			}
+			return prefix + v
+		},
+	}
+}
+
+// ExtractComposite returns a KeyFunc trying candidates in order and using
+// the first that produces a key. Every candidate that passed on a request
+// is counted as a fallback and the one that keyed it as a hit, so m's
+// snapshot shows exactly where traffic is landing. The final candidate
+// should be one that always succeeds, such as ExtractIP; if every
+// candidate passes, the empty key is returned and all are counted as
+// fallbacks.
+func (m *ExtractorMetrics) ExtractComposite(candidates ...NamedExtractor) func(*http.Request) string {
+	return func(r *http.Request) string {
+		for _, c := range candidates {
+			if key := c.Extract(r); key != "" {
+				m.counter(c.Name).hits.Add(1)
+				return key
+			}
+			m.counter(c.Name).fallbacks.Add(1)
+		}
+		return ""
+	}
+}
+
+// Instrument wraps an always-succeeding extractor with hit counting under
+// name, for deployments using a single extractor that still want it on the
+// dashboard next to composite chains.
+func (m *ExtractorMetrics) Instrument(name string, fn func(*http.Request) string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		m.counter(name).hits.Add(1)
+		return fn(r)
+	}
+}
@@ -0,0 +1,61 @@
+package signalfence
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractCompositeCountsHitsAndFallbacks(t *testing.T) {
+	var m ExtractorMetrics
+	keyFn := m.ExtractComposite(
+		HeaderExtractor("X-Api-Key", "key"),
+		NamedExtractor{Name: "ip", Extract: ExtractIP},
+	)
+
+	withHeader := httptest.NewRequest("GET", "/", nil)
+	withHeader.Header.Set("X-Api-Key", "k123")
+	if got := keyFn(withHeader); got != "key:k123" {
+		t.Fatalf("header key = %q", got)
+	}
+
+	bare := httptest.NewRequest("GET", "/", nil)
+	bare.RemoteAddr = "10.3.3.3:999"
+	for i := 0; i < 3; i++ {
+		if got := keyFn(bare); got != "ip:10.3.3.3" {
+			t.Fatalf("fallback key = %q", got)
+		}
+	}
+
+	stats := m.Snapshot()
+	if s := stats["header:X-Api-Key"]; s.Hits != 1 || s.Fallbacks != 3 {
+		t.Fatalf("header stats = %+v", s)
+	}
+	if s := stats["ip"]; s.Hits != 3 || s.Fallbacks != 0 {
+		t.Fatalf("ip stats = %+v", s)
+	}
+}
+
+func TestExtractCompositeExhausted(t *testing.T) {
+	var m ExtractorMetrics
+	keyFn := m.ExtractComposite(HeaderExtractor("X-Api-Key", "key"))
+
+	if got := keyFn(httptest.NewRequest("GET", "/", nil)); got != "" {
+		t.Fatalf("exhausted chain key = %q, want empty", got)
+	}
+	if s := m.Snapshot()["header:X-Api-Key"]; s.Fallbacks != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestInstrumentCountsHits(t *testing.T) {
+	var m ExtractorMetrics
+	keyFn := m.Instrument("client_ip", ClientIP)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.4.4.4:999"
+	keyFn(req)
+	keyFn(req)
+	if s := m.Snapshot()["client_ip"]; s.Hits != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
@@ -0,0 +1,153 @@
+package signalfence
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ParseExtractor resolves an extractor spec from a config file into a
+// KeyFunc, so advanced keying is available from JSON without Go code. The
+// grammar:
+//
+//	client_ip            ClientIP (the default)
+//	ip                   ExtractIP
+//	ip_proxy             ExtractIPWithProxy
+//	path                 the request path (combine with join)
+//	header:<name>        a header value, e.g. "header:X-API-Key"
+//	jwt:<claim>          a claim from the Bearer token's payload, e.g. "jwt:sub"
+//	subnet:<bits>        the client IP masked to a prefix, e.g. "subnet:24"
+//	join:<a>+<b>+...     sub-specs joined with "|", e.g. "join:header:X-API-Key+path"
+//
+// Names registered via RegisterExtractor resolve before the built-in
+// grammar, so applications can expose custom extraction logic to configs.
+//
+// Extractors that can fail for a request — a missing header or token —
+// fall back to the client IP so unauthenticated traffic is still limited.
+// The JWT payload is decoded without signature verification; it selects a
+// bucket, it does not grant anything, and forging someone else's subject
+// only shares their limit.
+func ParseExtractor(spec string) (func(*http.Request) string, error) {
+	if rest, ok := strings.CutPrefix(spec, "join:"); ok {
+		parts := strings.Split(rest, "+")
+		fns := make([]func(*http.Request) string, len(parts))
+		for i, p := range parts {
+			fn, err := parseExtractorAtom(p)
+			if err != nil {
+				return nil, fmt.Errorf("join element %q: %w", p, err)
+			}
+			fns[i] = fn
+		}
+		return func(r *http.Request) string {
+			keys := make([]string, len(fns))
+			for i, fn := range fns {
+				keys[i] = fn(r)
+			}
+			return strings.Join(keys, "|")
+		}, nil
+	}
+	return parseExtractorAtom(spec)
+}
+
+// parseExtractorAtom resolves one non-join element of the spec grammar.
+func parseExtractorAtom(spec string) (func(*http.Request) string, error) {
+	if fn, ok := registeredExtractor(spec); ok {
+		return fn, nil
+	}
+	switch spec {
+	case "", "client_ip":
+		return ClientIP, nil
+	case "ip":
+		return ExtractIP, nil
+	case "ip_proxy":
+		return ExtractIPWithProxy, nil
+	case "path":
+		return func(r *http.Request) string { return r.URL.Path }, nil
+	}
+	if name, ok := strings.CutPrefix(spec, "header:"); ok {
+		if name == "" {
+			return nil, fmt.Errorf("header extractor needs a header name")
+		}
+		prefix := "hdr:"
+		return func(r *http.Request) string {
+			if v := r.Header.Get(name); v != "" {
+				return prefix + v
+			}
+			return ClientIP(r)
+		}, nil
+	}
+	if claim, ok := strings.CutPrefix(spec, "jwt:"); ok {
+		if claim == "" {
+			return nil, fmt.Errorf("jwt extractor needs a claim name")
+		}
+		return jwtExtractor(claim), nil
+	}
+	if bitsStr, ok := strings.CutPrefix(spec, "subnet:"); ok {
+		bits, err := strconv.Atoi(bitsStr)
+		if err != nil || bits < 1 || bits > 128 {
+			return nil, fmt.Errorf("subnet extractor needs a prefix length 1-128, got %q", bitsStr)
+		}
+		return subnetExtractor(bits), nil
+	}
+	return nil, fmt.Errorf("unknown extractor %q", spec)
+}
+
+// jwtExtractor keys requests by a claim in the Bearer token's payload,
+// falling back to the client IP when the token or claim is absent.
+func jwtExtractor(claim string) func(*http.Request) string {
+	prefix := "jwt:" + claim + ":"
+	return func(r *http.Request) string {
+		auth := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok {
+			return ClientIP(r)
+		}
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			return ClientIP(r)
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return ClientIP(r)
+		}
+		var claims map[string]any
+		if json.Unmarshal(payload, &claims) != nil {
+			return ClientIP(r)
+		}
+		switch v := claims[claim].(type) {
+		case string:
+			if v != "" {
+				return prefix + v
+			}
+		case float64:
+			return prefix + strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return ClientIP(r)
+	}
+}
+
+// subnetExtractor keys requests by the client IP masked to a prefix, so a
+// scraper rotating through a /24 shares one bucket. Prefix lengths over 32
+// only apply to IPv6 addresses; IPv4 masks are capped at /32.
+func subnetExtractor(bits int) func(*http.Request) string {
+	return func(r *http.Request) string {
+		host := hostOnly(r.RemoteAddr)
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return ipPrefix + host
+		}
+		maskBits, total := bits, 128
+		if v4 := ip.To4(); v4 != nil {
+			ip, total = v4, 32
+			if maskBits > 32 {
+				maskBits = 32
+			}
+		}
+		masked := ip.Mask(net.CIDRMask(maskBits, total))
+		return "subnet:" + masked.String() + "/" + itoa(maskBits)
+	}
+}
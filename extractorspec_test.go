@@ -0,0 +1,82 @@
+package signalfence
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+)
+
+func bearerToken(payload string) string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	return "Bearer " + b64([]byte(`{"alg":"none"}`)) + "." + b64([]byte(payload)) + ".sig"
+}
+
+func TestParseExtractorSpecs(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/users", nil)
+	req.RemoteAddr = "10.1.2.3:999"
+	req.Header.Set("Authorization", bearerToken(`{"sub":"alice","org":42}`))
+	req.Header.Set("X-Api-Key", "k123")
+
+	cases := map[string]string{
+		"client_ip":                  "10.1.2.3",
+		"":                           "10.1.2.3",
+		"ip":                         "ip:10.1.2.3",
+		"path":                       "/api/users",
+		"header:X-Api-Key":           "hdr:k123",
+		"jwt:sub":                    "jwt:sub:alice",
+		"jwt:org":                    "jwt:org:42",
+		"subnet:24":                  "subnet:10.1.2.0/24",
+		"subnet:16":                  "subnet:10.1.0.0/16",
+		"join:header:X-Api-Key+path": "hdr:k123|/api/users",
+	}
+	for spec, want := range cases {
+		fn, err := ParseExtractor(spec)
+		if err != nil {
+			t.Errorf("ParseExtractor(%q): %v", spec, err)
+			continue
+		}
+		if got := fn(req); got != want {
+			t.Errorf("spec %q key = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestParseExtractorFallsBackToIP(t *testing.T) {
+	bare := httptest.NewRequest("GET", "/", nil)
+	bare.RemoteAddr = "10.1.2.3:999"
+	for _, spec := range []string{"jwt:sub", "header:X-Api-Key"} {
+		fn, err := ParseExtractor(spec)
+		if err != nil {
+			t.Fatalf("ParseExtractor(%q): %v", spec, err)
+		}
+		if got := fn(bare); got != "10.1.2.3" {
+			t.Errorf("spec %q without credentials key = %q, want IP fallback", spec, got)
+		}
+	}
+}
+
+func TestParseExtractorErrors(t *testing.T) {
+	for _, spec := range []string{
+		"bogus", "jwt:", "header:", "subnet:0", "subnet:129", "subnet:x",
+		"join:path+bogus",
+	} {
+		if _, err := ParseExtractor(spec); err == nil {
+			t.Errorf("ParseExtractor(%q) accepted", spec)
+		}
+	}
+}
+
+func TestFileConfigAdvancedExtractor(t *testing.T) {
+	fc, err := ParseConfig([]byte(`{"extractor": "subnet:24"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.2.3:999"
+	if got := fc.Config().KeyFunc(req); got != "subnet:10.1.2.0/24" {
+		t.Fatalf("key = %q", got)
+	}
+	if _, err := ParseConfig([]byte(`{"extractor": "jwt:"}`)); err == nil {
+		t.Fatal("invalid extractor spec accepted")
+	}
+}
@@ -0,0 +1,55 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyFlagVariation(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 0.001,
+		Now:          clock.Now,
+		PolicyFlag: func(key, route string, pol Policy) (Policy, bool) {
+			if key == "user:beta" {
+				return Policy{Capacity: 2}, true
+			}
+			return Policy{}, false
+		},
+	})
+
+	// The beta segment runs at the variation's capacity; zero fields
+	// inherit, so its bucket still refills.
+	d := rl.Allow("user:beta")
+	if d.Limit != 2 || !d.Allowed {
+		t.Fatalf("flagged decision = %+v, want allowed at limit 2", d)
+	}
+	rl.Allow("user:beta")
+	if d := rl.Allow("user:beta"); d.Allowed {
+		t.Fatalf("flagged key exceeded its variation: %+v", d)
+	}
+
+	// Unflagged keys keep the default policy.
+	if d := rl.Allow("user:stable"); d.Limit != 10 || !d.Allowed {
+		t.Fatalf("unflagged decision = %+v, want allowed at limit 10", d)
+	}
+}
+
+func TestPolicyFlagYieldsToEscalation(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 1,
+		PolicyFlag: func(key, route string, pol Policy) (Policy, bool) {
+			return Policy{Capacity: 8}, true
+		},
+	})
+	rl.Escalate("user:abuser", Policy{Capacity: 1}, time.Minute)
+
+	if d := rl.Allow("user:abuser"); d.Limit != 1 {
+		t.Fatalf("escalation lost to flag variation: %+v", d)
+	}
+	if d := rl.Allow("user:ok"); d.Limit != 8 {
+		t.Fatalf("flag variation not applied: %+v", d)
+	}
+}
@@ -0,0 +1,43 @@
+package signalfence
+
+import "time"
+
+// Forecast returns how long until n tokens will be available for key
+// under the default policy, without consuming anything, so schedulers can
+// plan a batch submission instead of probing with repeated Allow calls.
+// Zero means the tokens are available now. A negative result means they
+// never will be — n exceeds the effective capacity (plus grace) or the
+// refill rate is zero — and the batch should be split or dropped.
+//
+// The forecast assumes no competing traffic: it is the earliest possible
+// time, not a reservation. Pair it with Wait for an actual claim.
+func (rl *RateLimiter) Forecast(key string, n int) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	pol := rl.EffectivePolicy("")
+	if sesPol, ok := rl.sessionPolicy(key); ok {
+		pol = mergePolicy(sesPol, pol)
+	}
+	if trustPol, ok := rl.trustPolicy(key); ok {
+		pol = mergePolicy(trustPol, pol)
+	}
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+	if n > pol.Capacity+pol.Grace {
+		return -1
+	}
+	b := rl.bucketFor(key, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	need := float64(n) - b.Peek(rl.now()) - float64(pol.Grace)
+	if need <= 0 {
+		return 0
+	}
+	if pol.RefillPerSec <= 0 {
+		return -1
+	}
+	return time.Duration(need / pol.RefillPerSec * float64(time.Second))
+}
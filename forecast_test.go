@@ -0,0 +1,43 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForecast(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 2, Now: clock.Now})
+
+	if got := rl.Forecast("k", 5); got != 0 {
+		t.Fatalf("full-bucket forecast = %v, want 0", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		rl.Allow("k")
+	}
+	// Empty bucket at 2 tokens/sec: 6 tokens in 3 seconds.
+	if got := rl.Forecast("k", 6); got != 3*time.Second {
+		t.Fatalf("forecast = %v, want 3s", got)
+	}
+	clock.Advance(time.Second)
+	if got := rl.Forecast("k", 6); got != 2*time.Second {
+		t.Fatalf("forecast after refill = %v, want 2s", got)
+	}
+
+	if got := rl.Forecast("k", 11); got >= 0 {
+		t.Fatalf("over-capacity forecast = %v, want negative", got)
+	}
+	if got := rl.Forecast("k", 0); got != 0 {
+		t.Fatalf("zero-token forecast = %v", got)
+	}
+}
+
+func TestForecastHonorsEscalation(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10, Now: clock.Now})
+	rl.Escalate("k", Policy{Capacity: 2}, time.Minute)
+	if got := rl.Forecast("k", 5); got >= 0 {
+		t.Fatalf("forecast past escalated capacity = %v, want negative", got)
+	}
+}
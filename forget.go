@@ -0,0 +1,77 @@
+package signalfence
+
+import (
+	"strings"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// bucketRanger is the optional enumeration side of a store. MemoryStore
+// implements it; Forget uses it to find every derived bucket for a key —
+// route overrides, read/write splits, canaries, shadows and named-policy
+// buckets all live under "key|..." or "key#..." entries.
+type bucketRanger interface {
+	Range(fn func(key string, b core.TokenBucket) bool)
+}
+
+// Forget erases every trace of key from the limiter: all of its buckets —
+// the bare one plus derived ones for route overrides, read/write splits,
+// canaries, shadows, named decision policies and egress throttling —
+// traffic counters, per-route offender tallies, quota windows, violation
+// score, watched bucket series, session policy, trust grant, escalation
+// and ban. It backs GDPR deletion requests, where retained counters are
+// personal data, and manual resets during support escalations. Unlike
+// SessionEnd it also clears penalties — a deletion request must not leave
+// a blocklist entry behind.
+//
+// Derived buckets are found by enumeration when the store supports Range
+// (MemoryStore does). Other stores fall back to deleting the derived keys
+// the limiter can compute from its own config, which covers everything
+// except buckets minted under caller-chosen policy names by the decision
+// API.
+func (rl *RateLimiter) Forget(key string) {
+	rl.forgetBuckets(key)
+	rl.traffic.keys.Delete(key)
+	rl.perRoute.forget(key)
+	rl.quotas.forget(key)
+	rl.violations.forget(key)
+	rl.UnwatchBucket(key)
+	rl.sessions.Delete(key)
+	rl.trusted.Delete(key)
+	rl.escalated.keys.Delete(key)
+	rl.traps.bans.Delete(key)
+}
+
+// forgetBuckets deletes key's bare bucket, its egress bucket, and every
+// derived bucket sharing its identity.
+func (rl *RateLimiter) forgetBuckets(key string) {
+	rl.store.Delete(key)
+	rl.store.Delete(egressPrefix + key)
+
+	if ranger, ok := rl.store.(bucketRanger); ok {
+		var derived []string
+		ranger.Range(func(k string, _ core.TokenBucket) bool {
+			if strings.HasPrefix(k, key+"|") || strings.HasPrefix(k, key+"#") {
+				derived = append(derived, k)
+			}
+			return true
+		})
+		for _, k := range derived {
+			rl.store.Delete(k)
+		}
+		return
+	}
+
+	// No enumeration support: delete the derived keys the current config
+	// implies.
+	rl.mu.RLock()
+	suffixes := make([]string, 0, 2*len(rl.cfg.Routes)+5)
+	for route := range rl.cfg.Routes {
+		suffixes = append(suffixes, "|"+route, "|"+route+"#canary", "|"+route+"#shadow")
+	}
+	rl.mu.RUnlock()
+	suffixes = append(suffixes, "|read", "|write", "|override", "#canary", "#shadow")
+	for _, suffix := range suffixes {
+		rl.store.Delete(key + suffix)
+	}
+}
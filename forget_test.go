@@ -0,0 +1,70 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForgetErasesAllState(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     5,
+		RefillPerSec: 0.001,
+		TrackTraffic: true,
+		Routes:       map[string]Policy{"/login": {Capacity: 2, RefillPerSec: 0.001}},
+	})
+	defer rl.Close()
+
+	rl.Allow("k")
+	rl.AllowRoute("k", "/login") // mints the derived bucket "k|/login"
+	rl.traffic.record("k", true, 10, 10)
+	rl.perRoute.record("/login", "k", false)
+	rl.ReportViolation("k", 0.5)
+	rl.WatchBucket("k")
+	rl.SessionStart("k", Policy{Capacity: 9})
+	rl.Trust("k", 1)
+	rl.Escalate("k", Policy{Capacity: 1}, time.Minute)
+	rl.Ban("k", time.Minute)
+
+	rl.Forget("k")
+
+	// Zero covers the derived route bucket, not just the bare key.
+	if rl.store.Len() != 0 {
+		t.Fatalf("store len = %d after Forget", rl.store.Len())
+	}
+	if s := rl.Traffic("k"); s.Requests != 0 {
+		t.Fatalf("traffic survived Forget: %+v", s)
+	}
+	if off := rl.TopOffenders("/login", 10); len(off) != 0 {
+		t.Fatalf("offender tally survived Forget: %+v", off)
+	}
+	if score := rl.ViolationScore("k"); score != 0 {
+		t.Fatalf("violation score = %g after Forget", score)
+	}
+	if pts := rl.BucketSeries("k"); pts != nil {
+		t.Fatalf("watched series survived Forget: %+v", pts)
+	}
+	if _, ok := rl.sessionPolicy("k"); ok {
+		t.Fatal("session policy survived Forget")
+	}
+	if rl.TrustLevel("k") != 0 {
+		t.Fatal("trust grant survived Forget")
+	}
+	if rl.Escalated("k") {
+		t.Fatal("escalation survived Forget")
+	}
+	if rl.Banned("k") {
+		t.Fatal("ban survived Forget")
+	}
+	// The key starts fresh afterwards.
+	if d := rl.Allow("k"); !d.Allowed || d.Remaining != 4 {
+		t.Fatalf("post-Forget decision = %+v", d)
+	}
+}
+
+func TestStoreDeleteUnknownKey(t *testing.T) {
+	rl := NewRateLimiter(Config{})
+	rl.Forget("never-seen") // must not panic or miscount
+	if rl.store.Len() != 0 {
+		t.Fatalf("store len = %d", rl.store.Len())
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/yourusername/signalfence
+
+go 1.21
@@ -0,0 +1,221 @@
+package signalfence
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Defaults for MetricsHistory's zero fields.
+const (
+	DefaultHistoryEvery     = time.Minute
+	DefaultHistoryRetention = 24 * time.Hour
+)
+
+// historyCompactEvery is how many appends pass between compactions of the
+// history file, bounding its growth past the retention window.
+const historyCompactEvery = 60
+
+// MetricsSnapshot is one point-in-time observation written to the history
+// file: the store size plus the per-route counters the dashboard charts.
+type MetricsSnapshot struct {
+	At     time.Time             `json:"at"`
+	Keys   int                   `json:"keys"`
+	Routes map[string]RouteStats `json:"routes,omitempty"`
+}
+
+// MetricsHistory persists rolling metrics snapshots to an append-only
+// JSON-lines file, so the dashboard can show the last day of history after
+// a process restart instead of starting blank. The format is deliberately
+// an embedded flat file rather than a database: it needs no driver, survives
+// partial writes (a torn last line is skipped on read), and compaction is a
+// rewrite-and-rename. Entries older than the retention window are dropped
+// during periodic compaction. The zero value is not usable; Path is
+// required.
+type MetricsHistory struct {
+	// Path is the history file location.
+	Path string
+	// Every is the snapshot interval. Zero means DefaultHistoryEvery.
+	Every time.Duration
+	// Retention is how far back entries are kept. Zero means
+	// DefaultHistoryRetention.
+	Retention time.Duration
+	// OnError, when set, is called for write and compaction failures.
+	OnError func(error)
+
+	mu      sync.Mutex
+	appends int
+}
+
+func (h *MetricsHistory) every() time.Duration {
+	if h.Every > 0 {
+		return h.Every
+	}
+	return DefaultHistoryEvery
+}
+
+func (h *MetricsHistory) retention() time.Duration {
+	if h.Retention > 0 {
+		return h.Retention
+	}
+	return DefaultHistoryRetention
+}
+
+// Append writes one snapshot, compacting the file on schedule.
+func (h *MetricsHistory) Append(snap MetricsSnapshot) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.OpenFile(h.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(f).Encode(snap)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	h.appends++
+	if h.appends >= historyCompactEvery {
+		h.appends = 0
+		return h.compactLocked(time.Now().Add(-h.retention()))
+	}
+	return nil
+}
+
+// Since returns the recorded snapshots at or after cutoff, oldest first. A
+// missing file is an empty history, not an error.
+func (h *MetricsHistory) Since(cutoff time.Time) ([]MetricsSnapshot, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.readSince(cutoff)
+}
+
+func (h *MetricsHistory) readSince(cutoff time.Time) ([]MetricsSnapshot, error) {
+	f, err := os.Open(h.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []MetricsSnapshot
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		var snap MetricsSnapshot
+		if err := json.Unmarshal(sc.Bytes(), &snap); err != nil {
+			// A torn line from a crash mid-append; skip it.
+			continue
+		}
+		if snap.At.Before(cutoff) {
+			continue
+		}
+		out = append(out, snap)
+	}
+	return out, sc.Err()
+}
+
+// compactLocked rewrites the file keeping only entries at or after cutoff.
+// Callers hold h.mu.
+func (h *MetricsHistory) compactLocked(cutoff time.Time) error {
+	kept, err := h.readSince(cutoff)
+	if err != nil {
+		return err
+	}
+	tmp := h.Path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, snap := range kept {
+		if err := enc.Encode(snap); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, h.Path)
+}
+
+// snapshot observes rl's current metrics.
+func (h *MetricsHistory) snapshot(rl *RateLimiter) MetricsSnapshot {
+	return MetricsSnapshot{
+		At:     time.Now(),
+		Keys:   rl.store.Len(),
+		Routes: rl.RouteSnapshot(),
+	}
+}
+
+// Start compacts any carried-over history once, then records snapshots on
+// the configured interval under the limiter's worker supervision (see
+// Health, worker "metrics-history") until the limiter is closed.
+func (h *MetricsHistory) Start(rl *RateLimiter) {
+	every := h.every()
+	rl.Go("metrics-history", func(ctx context.Context) {
+		h.mu.Lock()
+		if err := h.compactLocked(time.Now().Add(-h.retention())); err != nil && h.OnError != nil {
+			h.OnError(err)
+		}
+		h.mu.Unlock()
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := h.Append(h.snapshot(rl)); err != nil && h.OnError != nil {
+					h.OnError(err)
+				}
+			}
+		}
+	})
+}
+
+// Handler serves the recorded history as JSON for the dashboard. Mount it
+// next to the admin API on a trusted listener:
+//
+//	mux.Handle("/admin/history", history.Handler())
+//
+// GET returns the snapshots within the retention window, oldest first; a
+// "since" query parameter (RFC 3339) narrows the range.
+func (h *MetricsHistory) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		cutoff := time.Now().Add(-h.retention())
+		if s := r.URL.Query().Get("since"); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			cutoff = t
+		}
+		snaps, err := h.Since(cutoff)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if snaps == nil {
+			snaps = []MetricsSnapshot{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snaps)
+	})
+}
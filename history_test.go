@@ -0,0 +1,144 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetricsHistoryAppendAndSince(t *testing.T) {
+	h := &MetricsHistory{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := h.Append(MetricsSnapshot{At: now.Add(time.Duration(i) * time.Minute), Keys: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snaps, err := h.Since(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 || snaps[0].Keys != 0 || snaps[2].Keys != 2 {
+		t.Fatalf("snaps = %+v", snaps)
+	}
+
+	// A narrower cutoff drops the older entries.
+	snaps, err = h.Since(now.Add(90 * time.Second))
+	if err != nil || len(snaps) != 1 || snaps[0].Keys != 2 {
+		t.Fatalf("narrowed snaps = %+v, err %v", snaps, err)
+	}
+
+	// A missing file is an empty history.
+	empty := &MetricsHistory{Path: filepath.Join(t.TempDir(), "none.jsonl")}
+	if snaps, err := empty.Since(now); err != nil || snaps != nil {
+		t.Fatalf("missing file: snaps %v, err %v", snaps, err)
+	}
+}
+
+func TestMetricsHistorySkipsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h := &MetricsHistory{Path: path}
+	now := time.Now()
+	if err := h.Append(MetricsSnapshot{At: now, Keys: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash mid-append: a truncated trailing line.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"at":"2026-`)
+	f.Close()
+
+	snaps, err := h.Since(now.Add(-time.Hour))
+	if err != nil || len(snaps) != 1 {
+		t.Fatalf("snaps = %+v, err %v", snaps, err)
+	}
+}
+
+func TestMetricsHistoryCompaction(t *testing.T) {
+	h := &MetricsHistory{Path: filepath.Join(t.TempDir(), "history.jsonl"), Retention: time.Hour}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := h.Append(MetricsSnapshot{At: old, Keys: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Append(MetricsSnapshot{At: time.Now(), Keys: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	h.mu.Lock()
+	err := h.compactLocked(time.Now().Add(-time.Hour))
+	h.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snaps, err := h.Since(time.Time{})
+	if err != nil || len(snaps) != 1 || snaps[0].Keys != 2 {
+		t.Fatalf("after compaction: snaps = %+v, err %v", snaps, err)
+	}
+}
+
+func TestMetricsHistoryStartRecords(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1, TrackTraffic: true})
+	defer rl.Close()
+	h := &MetricsHistory{
+		Path:  filepath.Join(t.TempDir(), "history.jsonl"),
+		Every: 5 * time.Millisecond,
+	}
+	rl.Allow("k")
+	h.Start(rl)
+
+	waitFor(t, func() bool {
+		snaps, err := h.Since(time.Time{})
+		return err == nil && len(snaps) >= 2
+	})
+	snaps, _ := h.Since(time.Time{})
+	if snaps[0].Keys != 1 {
+		t.Fatalf("snapshot = %+v", snaps[0])
+	}
+	if _, ok := rl.Health()["metrics-history"]; !ok {
+		t.Fatal("history recorder not supervised")
+	}
+}
+
+func TestMetricsHistoryHandler(t *testing.T) {
+	h := &MetricsHistory{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	now := time.Now()
+	h.Append(MetricsSnapshot{At: now.Add(-time.Minute), Keys: 1})
+	h.Append(MetricsSnapshot{At: now, Keys: 2})
+
+	w := httptest.NewRecorder()
+	h.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/admin/history", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var snaps []MetricsSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snaps); err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("snaps = %+v", snaps)
+	}
+
+	since := now.Add(-10 * time.Second).Format(time.RFC3339)
+	w = httptest.NewRecorder()
+	h.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/admin/history?since="+since, nil))
+	snaps = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &snaps); err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || snaps[0].Keys != 2 {
+		t.Fatalf("narrowed snaps = %+v", snaps)
+	}
+
+	w = httptest.NewRecorder()
+	h.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/admin/history?since=bogus", nil))
+	if w.Code != 400 {
+		t.Fatalf("bad since: status = %d", w.Code)
+	}
+}
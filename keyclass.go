@@ -0,0 +1,60 @@
+package signalfence
+
+import "strings"
+
+// keyClass returns key's class prefix including its colon ("ip:",
+// "bearer:"), or "" for unprefixed keys.
+func keyClass(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i+1]
+	}
+	return ""
+}
+
+// classPolicy returns the key-class default configured for key's prefix,
+// if any.
+func (rl *RateLimiter) classPolicy(key string) (Policy, bool) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	if len(rl.cfg.KeyClasses) == 0 {
+		return Policy{}, false
+	}
+	class := keyClass(key)
+	if class == "" {
+		return Policy{}, false
+	}
+	p, ok := rl.cfg.KeyClasses[class]
+	return p, ok
+}
+
+// effectivePolicyForKey layers the policy for one request: the route
+// override wins, a key-class default fills what it leaves zero, a
+// read/write method default fills after that, and the global default fills
+// the rest. EffectivePolicy stays class- and method-blind because it has
+// neither a key nor a request in hand.
+func (rl *RateLimiter) effectivePolicyForKey(key, method, route string) Policy {
+	classPol, hasClass := rl.classPolicy(key)
+	methodPol, hasMethod := rl.methodDefault(method)
+	if !hasClass && !hasMethod {
+		return rl.EffectivePolicy(route)
+	}
+	rl.mu.RLock()
+	def := Policy{
+		Capacity:      rl.cfg.Capacity,
+		RefillPerSec:  rl.cfg.RefillPerSec,
+		WarnThreshold: rl.cfg.WarnThreshold,
+		Grace:         rl.cfg.Grace,
+	}
+	routePol, overridden := rl.cfg.Routes[route]
+	rl.mu.RUnlock()
+	if hasMethod {
+		def = mergePolicy(methodPol, def)
+	}
+	if hasClass {
+		def = mergePolicy(classPol, def)
+	}
+	if overridden {
+		return mergePolicy(routePol, def)
+	}
+	return def
+}
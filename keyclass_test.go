@@ -0,0 +1,76 @@
+package signalfence
+
+import "testing"
+
+func TestKeyClassDefaults(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 5,
+		KeyClasses: map[string]Policy{
+			"ip:":     {Capacity: 2, RefillPerSec: 1},
+			"bearer:": {Capacity: 100},
+		},
+	})
+	defer rl.Close()
+
+	if d := rl.Allow("ip:1.2.3.4"); d.Limit != 2 {
+		t.Fatalf("ip limit = %d, want 2", d.Limit)
+	}
+	// Zero fields of the class policy inherit from the default.
+	if d := rl.Allow("bearer:tok"); d.Limit != 100 || d.Remaining != 99 {
+		t.Fatalf("bearer decision = %+v", d)
+	}
+	if d := rl.Allow("unprefixed"); d.Limit != 10 {
+		t.Fatalf("unprefixed limit = %d, want 10", d.Limit)
+	}
+}
+
+func TestKeyClassRouteOverrideWins(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 5,
+		Routes:       map[string]Policy{"/login": {Capacity: 3}},
+		KeyClasses:   map[string]Policy{"ip:": {Capacity: 50, RefillPerSec: 1}},
+	})
+	defer rl.Close()
+
+	d := rl.AllowRoute("ip:1.2.3.4", "/login")
+	if d.Limit != 3 {
+		t.Fatalf("limit = %d, want route override 3", d.Limit)
+	}
+	// The route override leaves refill zero, so the class default fills it.
+	if got := rl.effectivePolicyForKey("ip:1.2.3.4", "", "/login"); got.RefillPerSec != 1 {
+		t.Fatalf("refill = %g, want class 1", got.RefillPerSec)
+	}
+}
+
+func TestKeyClassReloadAndRollback(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 5,
+		KeyClasses:   map[string]Policy{"ip:": {Capacity: 2, RefillPerSec: 1}},
+	})
+	defer rl.Close()
+
+	rl.Reload(Config{KeyClasses: map[string]Policy{"ip:": {Capacity: 7, RefillPerSec: 1}}})
+	if d := rl.Peek("ip:1.2.3.4", ""); d.Limit != 7 {
+		t.Fatalf("limit after reload = %d, want 7", d.Limit)
+	}
+	if !rl.Rollback() {
+		t.Fatal("rollback failed")
+	}
+	if d := rl.Peek("ip:1.2.3.4", ""); d.Limit != 2 {
+		t.Fatalf("limit after rollback = %d, want 2", d.Limit)
+	}
+}
+
+func TestValidateKeyClasses(t *testing.T) {
+	err := Config{
+		Capacity:     10,
+		RefillPerSec: 5,
+		KeyClasses:   map[string]Policy{"ip": {Capacity: -1}},
+	}.Validate()
+	if err == nil {
+		t.Fatal("want errors for bad key class config")
+	}
+}
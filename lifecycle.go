@@ -0,0 +1,93 @@
+package signalfence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/signalfence/store"
+)
+
+// cleanupStarter is implemented by stores that can sweep idle buckets in
+// the background (*store.MemoryStore does).
+type cleanupStarter interface {
+	StartCleanup(ctx context.Context, maxIdle time.Duration)
+}
+
+// StartCleanup begins background expiry of buckets idle for longer than
+// maxIdle, if the configured store supports it. The sweeps stop when the
+// limiter is closed.
+func (rl *RateLimiter) StartCleanup(maxIdle time.Duration) {
+	if cs, ok := rl.store.(cleanupStarter); ok {
+		cs.StartCleanup(rl.bgCtx, maxIdle)
+	}
+}
+
+// idleCleaner is the subset of *store.MemoryStore the elected cleanup loop
+// needs; any backend with a compatible Cleanup method qualifies.
+type idleCleaner interface {
+	Cleanup(maxIdle time.Duration) int
+}
+
+// Lease cadence for elected cleanup: the lease outlives two missed renewals,
+// and the leader sweeps once per sweepEveryTicks renewals.
+const (
+	cleanupLeaseName = "cleanup"
+	cleanupLeaseTTL  = 15 * time.Second
+	leaseRenewEvery  = 5 * time.Second
+	sweepEveryTicks  = 12
+)
+
+// StartCleanupElected is StartCleanup for deployments where several replicas
+// share one storage backend: the replicas compete for a lease in the store
+// and only the current leader runs the idle sweeps, so the shared backend is
+// not scanned N times over. If the leader dies its lease expires and another
+// replica takes over automatically. Stores without lease support fall back
+// to StartCleanup on every replica.
+func (rl *RateLimiter) StartCleanupElected(maxIdle time.Duration) {
+	ls, canLead := rl.store.(store.LeaderStore)
+	cleaner, canClean := rl.store.(idleCleaner)
+	if !canLead || !canClean {
+		rl.StartCleanup(maxIdle)
+		return
+	}
+	holder := leaseHolderID()
+	rl.Go("cleanup-elected", func(ctx context.Context) {
+		ticker := time.NewTicker(leaseRenewEvery)
+		defer ticker.Stop()
+		ticks := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if !ls.AcquireLease(cleanupLeaseName, holder, cleanupLeaseTTL) {
+				ticks = 0
+				continue
+			}
+			if ticks++; ticks >= sweepEveryTicks {
+				ticks = 0
+				cleaner.Cleanup(maxIdle)
+			}
+		}
+	})
+}
+
+// leaseHolderID returns a unique identity for this replica's lease claims.
+func leaseHolderID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// Close stops the limiter's background tasks. Decisions keep working after
+// Close; it exists so binaries can shut down cleanly.
+func (rl *RateLimiter) Close() error {
+	rl.bgCancel()
+	return nil
+}
@@ -0,0 +1,128 @@
+package signalfence
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Lint rule names, stable identifiers for suppression and tooling.
+const (
+	LintUnreachableRoute = "unreachable-route"
+	LintRefillDominates  = "refill-dominates-capacity"
+	LintLaxSensitive     = "lax-sensitive-route"
+	LintWarnThreshold    = "warn-threshold-range"
+	LintGraceExceeds     = "grace-exceeds-capacity"
+	LintQuotaBelowBurst  = "quota-below-capacity"
+)
+
+// LintIssue is one finding from LintConfig: the rule that fired, the route
+// it fired on ("" for the default policy) and a human-readable explanation.
+type LintIssue struct {
+	Route   string `json:"route,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	route := i.Route
+	if route == "" {
+		route = "(default)"
+	}
+	return route + ": " + i.Rule + ": " + i.Message
+}
+
+// sensitiveSegments are route words that mark authentication-adjacent
+// endpoints — the paths where a lax limit is a credential-stuffing invite.
+var sensitiveSegments = map[string]bool{
+	"login":    true,
+	"signin":   true,
+	"signup":   true,
+	"register": true,
+	"auth":     true,
+	"password": true,
+	"reset":    true,
+	"token":    true,
+	"otp":      true,
+	"2fa":      true,
+}
+
+// isSensitiveRoute reports whether any segment of route names an
+// authentication-adjacent endpoint.
+func isSensitiveRoute(route string) bool {
+	for _, seg := range strings.Split(route, "/") {
+		if sensitiveSegments[strings.ToLower(seg)] {
+			return true
+		}
+	}
+	return false
+}
+
+// LintConfig inspects a config for policies that cannot work as written:
+// route overrides that no request can reach under templating, refill rates
+// that make the burst capacity irrelevant, limits on sensitive paths looser
+// than the default, and out-of-range tuning fields. It complements
+// Config.Validate — Validate rejects configs that cannot run at all, Lint
+// flags ones that run but almost certainly don't do what the author meant.
+// Findings are sorted by route for stable output.
+func LintConfig(cfg Config) []LintIssue {
+	var issues []LintIssue
+	add := func(route, rule, format string, args ...any) {
+		issues = append(issues, LintIssue{Route: route, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	def := Policy{
+		Capacity:      cfg.Capacity,
+		RefillPerSec:  cfg.RefillPerSec,
+		WarnThreshold: cfg.WarnThreshold,
+		Grace:         cfg.Grace,
+	}
+	lintPolicy("", def, add)
+
+	names := make([]string, 0, len(cfg.Routes))
+	for name := range cfg.Routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !cfg.RawRoutes {
+			if templated := TemplateRoute(name); templated != name {
+				add(name, LintUnreachableRoute,
+					"contains variable-looking segments; requests are templated to %q so this override never matches (set RawRoutes to match literal paths)",
+					templated)
+			}
+		}
+		pol := mergePolicy(cfg.Routes[name], def)
+		lintPolicy(name, pol, add)
+		if isSensitiveRoute(name) && def.Capacity > 0 &&
+			(pol.Capacity > def.Capacity || pol.RefillPerSec > def.RefillPerSec) {
+			add(name, LintLaxSensitive,
+				"sensitive path is limited more loosely (capacity %d, refill %g/s) than the default (capacity %d, refill %g/s)",
+				pol.Capacity, pol.RefillPerSec, def.Capacity, def.RefillPerSec)
+		}
+	}
+	return issues
+}
+
+// lintPolicy runs the per-policy checks shared by the default policy and
+// every route override.
+func lintPolicy(route string, pol Policy, add func(route, rule, format string, args ...any)) {
+	if pol.Capacity > 0 && pol.RefillPerSec > float64(pol.Capacity) {
+		add(route, LintRefillDominates,
+			"refill %g/s replaces the full capacity %d in under a second, so the burst cap never binds",
+			pol.RefillPerSec, pol.Capacity)
+	}
+	if pol.WarnThreshold < 0 || pol.WarnThreshold > 1 {
+		add(route, LintWarnThreshold,
+			"warn_threshold %g is outside (0, 1]; warnings will never fire sensibly", pol.WarnThreshold)
+	}
+	if pol.Capacity > 0 && pol.Grace >= pol.Capacity {
+		add(route, LintGraceExceeds,
+			"grace %d is at least the capacity %d, doubling the effective burst", pol.Grace, pol.Capacity)
+	}
+	if pol.Quota > 0 && pol.Quota < pol.Capacity {
+		add(route, LintQuotaBelowBurst,
+			"quota %d is below capacity %d; a single burst exhausts the whole quota window", pol.Quota, pol.Capacity)
+	}
+}
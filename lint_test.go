@@ -0,0 +1,86 @@
+package signalfence
+
+import "testing"
+
+func lintRules(issues []LintIssue) map[string]bool {
+	rules := make(map[string]bool, len(issues))
+	for _, i := range issues {
+		rules[i.Rule] = true
+	}
+	return rules
+}
+
+func TestLintCleanConfig(t *testing.T) {
+	issues := LintConfig(Config{
+		Capacity:     20,
+		RefillPerSec: 10,
+		Routes:       map[string]Policy{"/api/login": {Capacity: 5, RefillPerSec: 1}},
+	})
+	if len(issues) != 0 {
+		t.Fatalf("issues = %v", issues)
+	}
+}
+
+func TestLintUnreachableRoute(t *testing.T) {
+	issues := LintConfig(Config{
+		Capacity:     20,
+		RefillPerSec: 10,
+		Routes:       map[string]Policy{"/api/users/42": {Capacity: 5, RefillPerSec: 1}},
+	})
+	if !lintRules(issues)[LintUnreachableRoute] {
+		t.Fatalf("issues = %v", issues)
+	}
+
+	raw := LintConfig(Config{
+		Capacity:     20,
+		RefillPerSec: 10,
+		RawRoutes:    true,
+		Routes:       map[string]Policy{"/api/users/42": {Capacity: 5, RefillPerSec: 1}},
+	})
+	if lintRules(raw)[LintUnreachableRoute] {
+		t.Fatalf("raw issues = %v", raw)
+	}
+}
+
+func TestLintRefillDominates(t *testing.T) {
+	issues := LintConfig(Config{Capacity: 5, RefillPerSec: 50})
+	if !lintRules(issues)[LintRefillDominates] {
+		t.Fatalf("issues = %v", issues)
+	}
+}
+
+func TestLintLaxSensitiveRoute(t *testing.T) {
+	issues := LintConfig(Config{
+		Capacity:     20,
+		RefillPerSec: 10,
+		Routes: map[string]Policy{
+			"/login":    {Capacity: 500, RefillPerSec: 10},
+			"/api/data": {Capacity: 500, RefillPerSec: 10},
+		},
+	})
+	var laxRoutes []string
+	for _, i := range issues {
+		if i.Rule == LintLaxSensitive {
+			laxRoutes = append(laxRoutes, i.Route)
+		}
+	}
+	if len(laxRoutes) != 1 || laxRoutes[0] != "/login" {
+		t.Fatalf("lax routes = %v (issues %v)", laxRoutes, issues)
+	}
+}
+
+func TestLintPolicyFieldChecks(t *testing.T) {
+	issues := LintConfig(Config{
+		Capacity:      10,
+		RefillPerSec:  1,
+		WarnThreshold: 1.5,
+		Grace:         10,
+		Routes:        map[string]Policy{"/api/data": {Capacity: 100, RefillPerSec: 1, Quota: 50}},
+	})
+	rules := lintRules(issues)
+	for _, want := range []string{LintWarnThreshold, LintGraceExceeds, LintQuotaBelowBurst} {
+		if !rules[want] {
+			t.Fatalf("missing %s in %v", want, issues)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package signalfence
+
+import "net/http"
+
+// isReadMethod reports whether method is a read under the read/write
+// default split: GET and HEAD read, everything else writes.
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// methodDefault returns the read or write default policy for method, if
+// one is configured. An empty method — programmatic Allow calls that have
+// no request in hand — always uses the plain defaults.
+func (rl *RateLimiter) methodDefault(method string) (Policy, bool) {
+	if method == "" {
+		return Policy{}, false
+	}
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	p := rl.cfg.WriteDefaults
+	if isReadMethod(method) {
+		p = rl.cfg.ReadDefaults
+	}
+	if p == nil {
+		return Policy{}, false
+	}
+	return *p, true
+}
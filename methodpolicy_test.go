@@ -0,0 +1,100 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMethodDefaults(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:      10,
+		RefillPerSec:  5,
+		ReadDefaults:  &Policy{Capacity: 100, RefillPerSec: 50},
+		WriteDefaults: &Policy{Capacity: 3},
+	})
+	defer rl.Close()
+
+	if d := rl.AllowMethod("k", http.MethodGet, ""); d.Limit != 100 {
+		t.Fatalf("GET limit = %d, want 100", d.Limit)
+	}
+	if d := rl.AllowMethod("k", http.MethodHead, ""); d.Limit != 100 {
+		t.Fatalf("HEAD limit = %d, want 100", d.Limit)
+	}
+	if d := rl.AllowMethod("k", http.MethodPost, ""); d.Limit != 3 {
+		t.Fatalf("POST limit = %d, want 3", d.Limit)
+	}
+	// Programmatic calls carry no method and use the plain defaults.
+	if d := rl.Allow("k"); d.Limit != 10 {
+		t.Fatalf("Allow limit = %d, want 10", d.Limit)
+	}
+}
+
+func TestMethodDefaultsSeparateBuckets(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:      10,
+		RefillPerSec:  5,
+		WriteDefaults: &Policy{Capacity: 1, RefillPerSec: 0.001},
+	})
+	defer rl.Close()
+
+	if d := rl.AllowMethod("k", http.MethodPost, ""); !d.Allowed {
+		t.Fatalf("first POST denied: %+v", d)
+	}
+	if d := rl.AllowMethod("k", http.MethodPost, ""); d.Allowed {
+		t.Fatalf("second POST allowed: %+v", d)
+	}
+	// Reads draw from their own bucket and stay unaffected.
+	if d := rl.AllowMethod("k", http.MethodGet, ""); !d.Allowed || d.Limit != 10 {
+		t.Fatalf("GET after write exhaustion = %+v", d)
+	}
+}
+
+func TestMethodDefaultsRouteOverrideWins(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:      10,
+		RefillPerSec:  5,
+		Routes:        map[string]Policy{"/api/data": {Capacity: 7}},
+		WriteDefaults: &Policy{Capacity: 3, RefillPerSec: 1},
+	})
+	defer rl.Close()
+
+	d := rl.AllowMethod("k", http.MethodPost, "/api/data")
+	if d.Limit != 7 {
+		t.Fatalf("limit = %d, want route override 7", d.Limit)
+	}
+	// The override leaves refill zero, so the write default fills it.
+	if got := rl.effectivePolicyForKey("k", http.MethodPost, "/api/data"); got.RefillPerSec != 1 {
+		t.Fatalf("refill = %g, want write default 1", got.RefillPerSec)
+	}
+}
+
+func TestMethodDefaultsMiddlewareAndConfigFile(t *testing.T) {
+	path := writeConfig(t, `{
+		"default": {"capacity": 10, "refill_per_sec": 5},
+		"write_defaults": {"capacity": 2, "refill_per_sec": 0.001}
+	}`)
+	fc, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl := NewRateLimiter(fc.Config())
+	defer rl.Close()
+
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	post := httptest.NewRequest("POST", "/api/data", nil)
+	post.RemoteAddr = "6.6.6.6:1"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, post)
+	if got := w.Header().Get("X-Ratelimit-Limit"); got != "2" {
+		t.Fatalf("POST limit header = %q, want 2", got)
+	}
+
+	get := httptest.NewRequest("GET", "/api/data", nil)
+	get.RemoteAddr = "6.6.6.6:1"
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, get)
+	if got := w.Header().Get("X-Ratelimit-Limit"); got != "10" {
+		t.Fatalf("GET limit header = %q, want 10", got)
+	}
+}
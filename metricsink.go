@@ -0,0 +1,172 @@
+package signalfence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsGaugeEvery is how often the limiter reports its store size
+// to the configured sink's SetBuckets.
+const DefaultMetricsGaugeEvery = 10 * time.Second
+
+// MetricsSink receives the limiter's instrumentation events, so embedders
+// can plug in whatever backend their fleet runs without this module taking
+// a vendor dependency. StatsDSink, PromMetrics and NopMetrics are the
+// built-in implementations; set one via Config.Metrics. Implementations
+// must be safe for concurrent use and fast — IncAllowed and IncDenied run
+// on the decision path.
+type MetricsSink interface {
+	// IncAllowed counts one admitted decision on route.
+	IncAllowed(route string)
+	// IncDenied counts one denied decision on route.
+	IncDenied(route string)
+	// ObserveLatency records one request's handling time on route. Only
+	// the HTTP middlewares observe completion; programmatic Allow calls
+	// never report latency.
+	ObserveLatency(route string, d time.Duration)
+	// SetBuckets reports the current number of tracked keys, sampled on
+	// an interval.
+	SetBuckets(n int)
+}
+
+// NopMetrics is a MetricsSink that discards everything — a placeholder for
+// composition and tests.
+type NopMetrics struct{}
+
+func (NopMetrics) IncAllowed(string)                    {}
+func (NopMetrics) IncDenied(string)                     {}
+func (NopMetrics) ObserveLatency(string, time.Duration) {}
+func (NopMetrics) SetBuckets(int)                       {}
+
+// PromMetrics is a MetricsSink that accumulates counters in memory and
+// exposes them in the Prometheus text format, so a scrape target costs no
+// client library. Latency is exported as a summary (sum and count); wire it
+// up and mount the handler on a trusted listener:
+//
+//	prom := signalfence.NewPromMetrics()
+//	limiter := signalfence.NewRateLimiter(signalfence.Config{Metrics: prom})
+//	mux.Handle("/metrics", prom.Handler())
+type PromMetrics struct {
+	mu       sync.Mutex
+	allowed  map[string]uint64
+	denied   map[string]uint64
+	latSum   map[string]float64 // seconds
+	latCount map[string]uint64
+	buckets  int
+}
+
+// NewPromMetrics returns an empty collector.
+func NewPromMetrics() *PromMetrics {
+	return &PromMetrics{
+		allowed:  make(map[string]uint64),
+		denied:   make(map[string]uint64),
+		latSum:   make(map[string]float64),
+		latCount: make(map[string]uint64),
+	}
+}
+
+func (p *PromMetrics) IncAllowed(route string) {
+	p.mu.Lock()
+	p.allowed[route]++
+	p.mu.Unlock()
+}
+
+func (p *PromMetrics) IncDenied(route string) {
+	p.mu.Lock()
+	p.denied[route]++
+	p.mu.Unlock()
+}
+
+func (p *PromMetrics) ObserveLatency(route string, d time.Duration) {
+	p.mu.Lock()
+	p.latSum[route] += d.Seconds()
+	p.latCount[route]++
+	p.mu.Unlock()
+}
+
+func (p *PromMetrics) SetBuckets(n int) {
+	p.mu.Lock()
+	p.buckets = n
+	p.mu.Unlock()
+}
+
+// escapeLabel escapes a label value per the Prometheus text format.
+func escapeLabel(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return r.Replace(v)
+}
+
+// Handler serves the accumulated metrics in the Prometheus text exposition
+// format.
+func (p *PromMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+		writeCounter := func(name string, values map[string]uint64) {
+			fmt.Fprintf(w, "# TYPE %s counter\n", name)
+			for _, route := range sortedRoutes(values) {
+				fmt.Fprintf(w, "%s{route=%q} %d\n", name, escapeLabel(route), values[route])
+			}
+		}
+		writeCounter("signalfence_allowed_total", p.allowed)
+		writeCounter("signalfence_denied_total", p.denied)
+
+		fmt.Fprintf(w, "# TYPE signalfence_latency_seconds summary\n")
+		for _, route := range sortedRoutes(p.latCount) {
+			label := escapeLabel(route)
+			fmt.Fprintf(w, "signalfence_latency_seconds_sum{route=%q} %g\n", label, p.latSum[route])
+			fmt.Fprintf(w, "signalfence_latency_seconds_count{route=%q} %d\n", label, p.latCount[route])
+		}
+
+		fmt.Fprintf(w, "# TYPE signalfence_buckets gauge\n")
+		fmt.Fprintf(w, "signalfence_buckets %d\n", p.buckets)
+	})
+}
+
+func sortedRoutes(m map[string]uint64) []string {
+	routes := make([]string, 0, len(m))
+	for route := range m {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// countDecision reports a non-peek decision to the configured sink.
+func (rl *RateLimiter) countDecision(route string, d Decision) {
+	if rl.metrics == nil {
+		return
+	}
+	if d.Allowed {
+		rl.metrics.IncAllowed(route)
+	} else {
+		rl.metrics.IncDenied(route)
+	}
+}
+
+// startMetricsGauge feeds the sink's SetBuckets from the store size.
+func (rl *RateLimiter) startMetricsGauge() {
+	rl.Go("metrics-gauge", func(ctx context.Context) {
+		ticker := time.NewTicker(DefaultMetricsGaugeEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rl.metrics.SetBuckets(rl.store.Len())
+			}
+		}
+	})
+}
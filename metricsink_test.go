@@ -0,0 +1,121 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	_ MetricsSink = NopMetrics{}
+	_ MetricsSink = (*PromMetrics)(nil)
+	_ MetricsSink = (*StatsDSink)(nil)
+)
+
+// countingSink records calls for wiring tests.
+type countingSink struct {
+	mu        sync.Mutex
+	allowed   map[string]int
+	denied    map[string]int
+	latencies int
+	buckets   int
+}
+
+func newCountingSink() *countingSink {
+	return &countingSink{allowed: make(map[string]int), denied: make(map[string]int)}
+}
+
+func (c *countingSink) IncAllowed(route string) {
+	c.mu.Lock()
+	c.allowed[route]++
+	c.mu.Unlock()
+}
+
+func (c *countingSink) IncDenied(route string) {
+	c.mu.Lock()
+	c.denied[route]++
+	c.mu.Unlock()
+}
+
+func (c *countingSink) ObserveLatency(string, time.Duration) {
+	c.mu.Lock()
+	c.latencies++
+	c.mu.Unlock()
+}
+
+func (c *countingSink) SetBuckets(n int) {
+	c.mu.Lock()
+	c.buckets = n
+	c.mu.Unlock()
+}
+
+func TestLimiterFeedsMetricsSink(t *testing.T) {
+	sink := newCountingSink()
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, Metrics: sink})
+	defer rl.Close()
+
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "4.4.4.4:1"
+	for i := 0; i < 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.allowed["/api/data"] != 2 || sink.denied["/api/data"] != 1 {
+		t.Fatalf("allowed = %v, denied = %v", sink.allowed, sink.denied)
+	}
+	if sink.latencies != 3 {
+		t.Fatalf("latencies = %d", sink.latencies)
+	}
+}
+
+func TestPeekDoesNotCount(t *testing.T) {
+	sink := newCountingSink()
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 1, Metrics: sink})
+	defer rl.Close()
+
+	rl.Peek("k", "")
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.allowed)+len(sink.denied) != 0 {
+		t.Fatalf("peek counted: allowed %v denied %v", sink.allowed, sink.denied)
+	}
+}
+
+func TestPromMetricsExposition(t *testing.T) {
+	p := NewPromMetrics()
+	p.IncAllowed("/api/data")
+	p.IncAllowed("/api/data")
+	p.IncDenied("/api/data")
+	p.ObserveLatency("/api/data", 10*time.Millisecond)
+	p.SetBuckets(7)
+
+	w := httptest.NewRecorder()
+	p.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`signalfence_allowed_total{route="/api/data"} 2`,
+		`signalfence_denied_total{route="/api/data"} 1`,
+		`signalfence_latency_seconds_sum{route="/api/data"} 0.01`,
+		`signalfence_latency_seconds_count{route="/api/data"} 1`,
+		`signalfence_buckets 7`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("exposition missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("escapeLabel = %q", got)
+	}
+}
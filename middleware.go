@@ -0,0 +1,200 @@
+package signalfence
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Canonical header keys, written directly into the header map to skip
+// per-request canonicalization. The wire casing matches what Header.Set
+// would have produced.
+const (
+	headerLimit     = "X-Ratelimit-Limit"
+	headerRemaining = "X-Ratelimit-Remaining"
+	headerWarning   = "X-Ratelimit-Warning"
+	headerRetry     = "Retry-After"
+)
+
+// smallInts caches the decimal form of small numbers so the common header
+// values cost no formatting or allocation.
+var smallInts [256]string
+
+func init() {
+	for i := range smallInts {
+		smallInts[i] = strconv.Itoa(i)
+	}
+}
+
+// itoa is strconv.Itoa with a cache for the small values that dominate
+// header traffic.
+func itoa(n int) string {
+	if n >= 0 && n < len(smallInts) {
+		return smallInts[n]
+	}
+	return strconv.Itoa(n)
+}
+
+// Middleware wraps next with rate limiting. Allowed requests pass through
+// with X-RateLimit headers set; rejected requests receive 429 Too Many
+// Requests and a Retry-After header.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := rl.keyFunc(r)
+		if rl.metrics != nil {
+			start := time.Now()
+			defer func() {
+				rl.metrics.ObserveLatency(rl.routeOf(r.URL.Path), time.Since(start))
+			}()
+		}
+		if token := r.Header.Get(BypassHeader); token != "" {
+			if keyID, ok := rl.verifyBypass(token); ok {
+				if rl.cfg.OnBypass != nil {
+					rl.cfg.OnBypass(keyID, key, r.URL.Path)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if rl.isTrap(r.URL.Path) {
+			rl.Ban(key, rl.trapBanDuration())
+		}
+		route := rl.routeOf(r.URL.Path)
+		d := rl.AllowMethod(key, r.Method, route)
+		// The concurrency constraint lives here rather than in AllowRoute
+		// because only the middleware observes request completion.
+		if mc := rl.EffectivePolicy(route).MaxConcurrent; mc > 0 && d.Allowed {
+			slotOK, inflight := rl.slots.acquire(key, mc)
+			cr := ConstraintResult{Name: ConstraintConcurrency, Allowed: slotOK, Limit: mc, Remaining: mc - int(inflight)}
+			if slotOK {
+				defer rl.slots.release(key)
+			} else {
+				cr.RetryAfter = time.Second
+				if rl.Mode() == ModeObserve {
+					d.WouldBlock = true
+				} else {
+					d.Allowed = false
+					d.RetryAfter = cr.RetryAfter
+				}
+			}
+			d.Constraints = append(d.Constraints, cr)
+		}
+		writeLimitHeaders(w, d)
+		if rl.cfg.TrackTraffic {
+			cw := &byteCountWriter{ResponseWriter: w}
+			if d.Allowed {
+				next.ServeHTTP(cw, r)
+			} else {
+				rl.reject(cw, r, route, d)
+			}
+			rl.traffic.record(key, d.Allowed, declaredBytes(r), cw.n)
+			rl.perRoute.record(route, key, d.Allowed)
+			return
+		}
+		if !d.Allowed {
+			rl.reject(w, r, route, d)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// declaredBytes is the request body size as declared, or zero when unknown.
+func declaredBytes(r *http.Request) uint64 {
+	if r.ContentLength > 0 {
+		return uint64(r.ContentLength)
+	}
+	return 0
+}
+
+// byteCountWriter counts response bytes for traffic accounting.
+type byteCountWriter struct {
+	http.ResponseWriter
+	n uint64
+}
+
+func (w *byteCountWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += uint64(n)
+	return n, err
+}
+
+// Flush forwards to the underlying writer when it supports streaming.
+func (w *byteCountWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeLimitHeaders stamps the X-RateLimit headers for a decision.
+func writeLimitHeaders(w http.ResponseWriter, d Decision) {
+	h := w.Header()
+	h[headerLimit] = []string{itoa(d.Limit)}
+	h[headerRemaining] = []string{itoa(d.Remaining)}
+	if d.Warning {
+		h[headerWarning] = []string{"approaching limit"}
+	}
+	for _, c := range d.Constraints {
+		switch c.Name {
+		case ConstraintQuota:
+			h["X-Quota-Limit"] = []string{itoa(c.Limit)}
+			h["X-Quota-Remaining"] = []string{itoa(c.Remaining)}
+		case ConstraintConcurrency:
+			h["X-Concurrency-Limit"] = []string{itoa(c.Limit)}
+			h["X-Concurrency-Remaining"] = []string{itoa(c.Remaining)}
+		}
+	}
+}
+
+// deny writes the 429 response for a blocked decision, with a Retry-After
+// hint and either a problem+json or plain text body per the config. The
+// body comes from DeniedMessageFunc when it yields one, then the route
+// policy's Message, then the generic text; a DocsURL adds a Link
+// rel="help" header. r may be nil for callers without a request in hand.
+func (rl *RateLimiter) deny(w http.ResponseWriter, r *http.Request, route string, d Decision) {
+	retry := int(math.Ceil(d.RetryAfter.Seconds()))
+	if retry < 1 {
+		retry = 1
+	}
+	w.Header()[headerRetry] = []string{itoa(retry)}
+	if !rl.cfg.NoDenialCacheHeaders {
+		h := w.Header()
+		h["Cache-Control"] = []string{"no-store"}
+		if rl.cfg.DeniedMessageFunc != nil {
+			h.Add("Vary", "Accept-Language")
+		}
+		for _, v := range rl.cfg.DenialVary {
+			h.Add("Vary", v)
+		}
+	}
+	if rl.cfg.EdgeAbsorbDenials {
+		w.Header()["Surrogate-Control"] = []string{"max-age=" + itoa(retry)}
+	}
+	pol := rl.EffectivePolicy(route)
+	msg := pol.Message
+	if rl.cfg.DeniedMessageFunc != nil && r != nil {
+		if localized := rl.cfg.DeniedMessageFunc(r, &d); localized != "" {
+			msg = localized
+		}
+	}
+	if pol.DocsURL != "" {
+		w.Header().Set("Link", "<"+pol.DocsURL+`>; rel="help"`)
+	}
+	if rl.cfg.ProblemJSON {
+		p := NewProblem(route, d)
+		if msg != "" {
+			p.Detail = msg
+		}
+		p.Docs = pol.DocsURL
+		writeProblemDoc(w, p)
+		return
+	}
+	if msg == "" {
+		msg = "429 Too Many Requests"
+	} else if pol.DocsURL != "" {
+		msg += "; see " + pol.DocsURL
+	}
+	http.Error(w, msg, http.StatusTooManyRequests)
+}
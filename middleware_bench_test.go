@@ -0,0 +1,52 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func BenchmarkMiddlewareAllowed(b *testing.B) {
+	rl := NewRateLimiter(Config{Capacity: 1 << 30, RefillPerSec: 1 << 20})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+	rec := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(rec, req)
+	}
+}
+
+func BenchmarkMiddlewareDenied(b *testing.B) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func TestMiddlewareHeaderWireFormat(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 500, RefillPerSec: 10})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	// Get canonicalizes, so this covers both the casing and the value —
+	// including values above the small-int cache.
+	if got := rec.Header().Get("X-RateLimit-Limit"); got != "500" {
+		t.Fatalf("limit header = %q", got)
+	}
+	if got := rec.Header().Get("X-RateLimit-Remaining"); got != "499" {
+		t.Fatalf("remaining header = %q", got)
+	}
+}
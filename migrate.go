@@ -0,0 +1,28 @@
+package signalfence
+
+import "github.com/yourusername/signalfence/core"
+
+// Migrate carries a client's limiter state from oldKey to newKey, for key
+// rotation: when a session or API token is reissued mid-window the client
+// keeps its earned quota — and its debt — instead of starting from a fresh
+// bucket. The bucket is shared between the two keys afterwards, so traffic
+// still arriving under the old credential during the rotation overlap
+// drains the same budget; the old entry ages out with the idle sweep.
+// Active bans and escalations follow the identity too, so rotating a token
+// does not shed a penalty. Migrating onto a key that already has a bucket
+// is a no-op for the bucket (the established state wins) but still carries
+// penalties over.
+func (rl *RateLimiter) Migrate(oldKey, newKey string) {
+	if oldKey == newKey || oldKey == "" || newKey == "" {
+		return
+	}
+	if b, ok := rl.store.Load(oldKey); ok {
+		rl.store.Get(newKey, func() core.TokenBucket { return b })
+	}
+	if left, banned := rl.banRemaining(oldKey); banned {
+		rl.Ban(newKey, left)
+	}
+	if v, ok := rl.escalated.keys.Load(oldKey); ok {
+		rl.escalated.keys.Store(newKey, v)
+	}
+}
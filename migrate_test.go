@@ -0,0 +1,50 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrateCarriesBucketState(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001, Now: clock.Now})
+
+	for i := 0; i < 7; i++ {
+		rl.Allow("token:old")
+	}
+	rl.Migrate("token:old", "token:new")
+
+	if d := rl.Peek("token:new", ""); d.Remaining != 3 {
+		t.Fatalf("remaining after migrate = %d, want 3", d.Remaining)
+	}
+	// The keys share one bucket during the rotation overlap.
+	rl.Allow("token:old")
+	if d := rl.Peek("token:new", ""); d.Remaining != 2 {
+		t.Fatalf("remaining after overlap request = %d, want 2", d.Remaining)
+	}
+}
+
+func TestMigrateKeepsEstablishedBucket(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001})
+	for i := 0; i < 5; i++ {
+		rl.Allow("token:new")
+	}
+	rl.Allow("token:old")
+	rl.Migrate("token:old", "token:new")
+	if d := rl.Peek("token:new", ""); d.Remaining != 5 {
+		t.Fatalf("established bucket overwritten: remaining = %d, want 5", d.Remaining)
+	}
+}
+
+func TestMigrateCarriesPenalties(t *testing.T) {
+	rl := NewRateLimiter(Config{})
+	rl.Ban("token:old", time.Minute)
+	rl.Escalate("token:old", Policy{Capacity: 1}, time.Minute)
+	rl.Migrate("token:old", "token:new")
+	if !rl.Banned("token:new") {
+		t.Fatal("ban did not follow migration")
+	}
+	if !rl.Escalated("token:new") {
+		t.Fatal("escalation did not follow migration")
+	}
+}
@@ -0,0 +1,51 @@
+package signalfence
+
+// Mode controls how decisions are acted on across all routes. It is meant
+// for load tests and emergencies: Observe keeps evaluating and recording
+// but never blocks; Bypass skips evaluation entirely.
+type Mode int32
+
+const (
+	// ModeEnforce is the normal mode: denials block requests.
+	ModeEnforce Mode = iota
+	// ModeObserve evaluates every request and records the outcome, but
+	// would-be denials are let through and flagged on the decision.
+	ModeObserve
+	// ModeBypass lets everything through without touching any bucket.
+	ModeBypass
+)
+
+// String returns the lowercase mode name used in the admin API and metrics.
+func (m Mode) String() string {
+	switch m {
+	case ModeObserve:
+		return "observe"
+	case ModeBypass:
+		return "bypass"
+	default:
+		return "enforce"
+	}
+}
+
+// parseMode maps an admin API mode name back to a Mode.
+func parseMode(s string) (Mode, bool) {
+	switch s {
+	case "enforce":
+		return ModeEnforce, true
+	case "observe":
+		return ModeObserve, true
+	case "bypass":
+		return ModeBypass, true
+	}
+	return ModeEnforce, false
+}
+
+// SetMode switches the limiter's decision mode at runtime.
+func (rl *RateLimiter) SetMode(m Mode) {
+	rl.mode.Store(int32(m))
+}
+
+// Mode returns the current decision mode.
+func (rl *RateLimiter) Mode() Mode {
+	return Mode(rl.mode.Load())
+}
@@ -0,0 +1,62 @@
+package signalfence
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestObserveModeNeverBlocks(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	rl.SetMode(ModeObserve)
+
+	rl.Allow("k")
+	d := rl.Allow("k")
+	if !d.Allowed || !d.WouldBlock || d.Mode != ModeObserve {
+		t.Fatalf("decision = %+v, want suppressed denial", d)
+	}
+
+	// Bans are suppressed too.
+	rl.Ban("banned", DefaultTrapBanDuration)
+	if d := rl.Allow("banned"); !d.Allowed || !d.WouldBlock {
+		t.Fatalf("banned decision = %+v", d)
+	}
+}
+
+func TestBypassModeSkipsBuckets(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	rl.SetMode(ModeBypass)
+	for i := 0; i < 10; i++ {
+		if d := rl.Allow("k"); !d.Allowed || d.Mode != ModeBypass {
+			t.Fatalf("decision %d = %+v", i, d)
+		}
+	}
+	// Back to enforce: the bucket was never drained.
+	rl.SetMode(ModeEnforce)
+	if d := rl.Allow("k"); !d.Allowed {
+		t.Fatalf("post-bypass decision = %+v", d)
+	}
+}
+
+func TestModeAdminEndpoint(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10})
+	h := rl.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/mode", strings.NewReader(`{"mode":"observe"}`)))
+	if rec.Code != 200 || rl.Mode() != ModeObserve {
+		t.Fatalf("status=%d mode=%v", rec.Code, rl.Mode())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/mode", nil))
+	if !strings.Contains(rec.Body.String(), `"observe"`) {
+		t.Fatalf("body = %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/mode", strings.NewReader(`{"mode":"nope"}`)))
+	if rec.Code != 400 {
+		t.Fatalf("unknown mode status = %d", rec.Code)
+	}
+}
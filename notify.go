@@ -0,0 +1,162 @@
+package signalfence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Ban event actions, carried in BanEvent.Action.
+const (
+	BanActionBan   = "ban"
+	BanActionUnban = "unban"
+)
+
+// BanEvent describes a blocklist change delivered to registered notifiers.
+type BanEvent struct {
+	// Key is the blocklisted key.
+	Key string `json:"key"`
+	// Action is BanActionBan or BanActionUnban.
+	Action string `json:"action"`
+	// Duration is the ban length; zero for unbans.
+	Duration time.Duration `json:"-"`
+	// Until is when the ban expires; zero for unbans.
+	Until time.Time `json:"until,omitempty"`
+	// At is when the action happened.
+	At time.Time `json:"at"`
+}
+
+// BanNotifier receives ban and unban events. Notifiers run off the request
+// path, one event's notifiers in order, so a slow webhook delays later
+// notifiers for that event but never the caller of Ban or Unban.
+type BanNotifier func(ev BanEvent)
+
+// banNotifiers holds the registered notifier callbacks.
+type banNotifiers struct {
+	mu  sync.RWMutex
+	fns []BanNotifier
+}
+
+// NotifyBans registers fn to be called for every explicit Ban and Unban —
+// including trap hits, which ban through the same path — so security teams
+// hear about blocklist changes in real time:
+//
+//	limiter.NotifyBans(signalfence.SlackNotifier(hookURL))
+//
+// Bans that lapse on their own do not produce events. A panicking notifier
+// is recovered and does not stop the others.
+func (rl *RateLimiter) NotifyBans(fn BanNotifier) {
+	rl.notifiers.mu.Lock()
+	defer rl.notifiers.mu.Unlock()
+	rl.notifiers.fns = append(rl.notifiers.fns, fn)
+}
+
+// notifyBan dispatches ev to the registered notifiers asynchronously.
+func (rl *RateLimiter) notifyBan(ev BanEvent) {
+	rl.notifiers.mu.RLock()
+	fns := rl.notifiers.fns
+	rl.notifiers.mu.RUnlock()
+	if len(fns) == 0 {
+		return
+	}
+	go func() {
+		for _, fn := range fns {
+			func() {
+				defer func() { recover() }()
+				fn(ev)
+			}()
+		}
+	}()
+}
+
+// notifyClient bounds notifier deliveries so a dead endpoint cannot pile up
+// goroutines behind default-client timeouts.
+var notifyClient = &http.Client{Timeout: 5 * time.Second}
+
+// postJSON delivers one notifier payload, draining the response so the
+// connection is reused. Delivery is fire-and-forget; failures are dropped.
+func postJSON(url string, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	resp, err := notifyClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// WebhookNotifier returns a notifier that POSTs each event as JSON to url.
+// The body is the BanEvent wire form plus a duration_ms field:
+//
+//	{"key":"1.2.3.4","action":"ban","until":"…","at":"…","duration_ms":600000}
+func WebhookNotifier(url string) BanNotifier {
+	return func(ev BanEvent) {
+		postJSON(url, struct {
+			BanEvent
+			DurationMS int64 `json:"duration_ms"`
+		}{ev, ev.Duration.Milliseconds()})
+	}
+}
+
+// SlackNotifier returns a notifier that posts a one-line message to a Slack
+// incoming-webhook URL.
+func SlackNotifier(webhookURL string) BanNotifier {
+	return func(ev BanEvent) {
+		var text string
+		if ev.Action == BanActionBan {
+			text = fmt.Sprintf("signalfence banned %q for %s (until %s)",
+				ev.Key, ev.Duration, ev.Until.Format(time.RFC3339))
+		} else {
+			text = fmt.Sprintf("signalfence unbanned %q", ev.Key)
+		}
+		postJSON(webhookURL, struct {
+			Text string `json:"text"`
+		}{text})
+	}
+}
+
+// pagerDutyEndpoint is the PagerDuty Events API v2 enqueue URL; a variable
+// so tests can point it at a local server.
+var pagerDutyEndpoint = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyNotifier returns a notifier that sends PagerDuty Events API v2
+// events for routingKey: bans trigger an incident and unbans resolve it,
+// deduplicated per limiter key so repeated bans of the same key re-trigger
+// the existing incident instead of opening new ones.
+func PagerDutyNotifier(routingKey string) BanNotifier {
+	return func(ev BanEvent) {
+		action := "trigger"
+		if ev.Action == BanActionUnban {
+			action = "resolve"
+		}
+		type payload struct {
+			Summary   string `json:"summary"`
+			Source    string `json:"source"`
+			Severity  string `json:"severity"`
+			Timestamp string `json:"timestamp"`
+		}
+		postJSON(pagerDutyEndpoint, struct {
+			RoutingKey  string  `json:"routing_key"`
+			EventAction string  `json:"event_action"`
+			DedupKey    string  `json:"dedup_key"`
+			Payload     payload `json:"payload"`
+		}{
+			RoutingKey:  routingKey,
+			EventAction: action,
+			DedupKey:    "signalfence-ban-" + ev.Key,
+			Payload: payload{
+				Summary:   fmt.Sprintf("signalfence %s: %s", ev.Action, ev.Key),
+				Source:    "signalfence",
+				Severity:  "warning",
+				Timestamp: ev.At.Format(time.RFC3339),
+			},
+		})
+	}
+}
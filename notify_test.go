@@ -0,0 +1,125 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func waitForEvents(t *testing.T, ch chan BanEvent) BanEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("no ban event delivered")
+		return BanEvent{}
+	}
+}
+
+func TestNotifyBansDeliversBanAndUnban(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	events := make(chan BanEvent, 4)
+	rl.NotifyBans(func(ev BanEvent) { events <- ev })
+
+	rl.Ban("1.2.3.4", time.Minute)
+	ev := waitForEvents(t, events)
+	if ev.Action != BanActionBan || ev.Key != "1.2.3.4" {
+		t.Fatalf("event = %+v", ev)
+	}
+	if ev.Duration != time.Minute || ev.Until.Before(ev.At) {
+		t.Fatalf("ban timing = %+v", ev)
+	}
+
+	rl.Unban("1.2.3.4")
+	ev = waitForEvents(t, events)
+	if ev.Action != BanActionUnban || ev.Key != "1.2.3.4" {
+		t.Fatalf("event = %+v", ev)
+	}
+
+	// Unbanning a key that was never banned is a no-op, not an event.
+	rl.Unban("5.6.7.8")
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifyBansRecoversPanickingNotifier(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	events := make(chan BanEvent, 1)
+	rl.NotifyBans(func(BanEvent) { panic("flaky sink") })
+	rl.NotifyBans(func(ev BanEvent) { events <- ev })
+
+	rl.Ban("k", time.Minute)
+	if ev := waitForEvents(t, events); ev.Key != "k" {
+		t.Fatalf("event = %+v", ev)
+	}
+}
+
+func TestWebhookNotifierPayload(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+	}))
+	defer srv.Close()
+
+	WebhookNotifier(srv.URL)(BanEvent{
+		Key:      "k",
+		Action:   BanActionBan,
+		Duration: time.Minute,
+		Until:    time.Now().Add(time.Minute),
+		At:       time.Now(),
+	})
+
+	var got struct {
+		Key        string `json:"key"`
+		Action     string `json:"action"`
+		DurationMS int64  `json:"duration_ms"`
+	}
+	if err := json.Unmarshal(<-bodies, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Key != "k" || got.Action != BanActionBan || got.DurationMS != 60000 {
+		t.Fatalf("payload = %+v", got)
+	}
+}
+
+func TestPagerDutyNotifierTriggersAndResolves(t *testing.T) {
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+	}))
+	defer srv.Close()
+	old := pagerDutyEndpoint
+	pagerDutyEndpoint = srv.URL
+	defer func() { pagerDutyEndpoint = old }()
+
+	n := PagerDutyNotifier("rk-123")
+	n(BanEvent{Key: "k", Action: BanActionBan, At: time.Now()})
+	n(BanEvent{Key: "k", Action: BanActionUnban, At: time.Now()})
+
+	var ev struct {
+		RoutingKey  string `json:"routing_key"`
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+	}
+	if err := json.Unmarshal(<-bodies, &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.RoutingKey != "rk-123" || ev.EventAction != "trigger" || ev.DedupKey != "signalfence-ban-k" {
+		t.Fatalf("trigger = %+v", ev)
+	}
+	if err := json.Unmarshal(<-bodies, &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.EventAction != "resolve" || ev.DedupKey != "signalfence-ban-k" {
+		t.Fatalf("resolve = %+v", ev)
+	}
+}
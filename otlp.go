@@ -0,0 +1,226 @@
+package signalfence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultOTLPEvery is the push interval when OTLPExporter.Every is zero.
+const DefaultOTLPEvery = time.Minute
+
+// OTLPExporter pushes aggregated metrics to an OTLP/HTTP metrics endpoint
+// on an interval, for deployments where nothing scrapes the process. Of
+// the push protocols, OTLP is the one with a JSON encoding, which keeps
+// this dependency-free; a collector can re-export to Prometheus remote
+// write or anywhere else. Exported metrics: signalfence.keys (gauge),
+// signalfence.requests and signalfence.denied (cumulative sums, labelled
+// by route; they need TrackTraffic). The zero value is not usable;
+// Endpoint is required.
+type OTLPExporter struct {
+	// Endpoint is the collector's metrics URL, typically
+	// "http://collector:4318/v1/metrics".
+	Endpoint string
+	// Every is the push interval. Zero means DefaultOTLPEvery.
+	Every time.Duration
+	// Headers are added to every push, e.g. an Authorization header for
+	// an authenticated collector.
+	Headers map[string]string
+	// ServiceName is the resource's service.name attribute. Empty means
+	// "signalfence".
+	ServiceName string
+	// HTTPClient overrides http.DefaultClient.
+	HTTPClient *http.Client
+	// OnError, when set, is called for failed pushes; the next interval
+	// retries with fresh data.
+	OnError func(error)
+
+	start time.Time // cumulative sum start, set on first push
+}
+
+// OTLP/HTTP JSON wire form, the subset this exporter emits. Proto JSON
+// renders 64-bit integers as strings, hence the string-typed numbers.
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	AsInt             string          `json:"asInt"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int             `json:"aggregationTemporality"` // 2 = cumulative
+	IsMonotonic            bool            `json:"isMonotonic"`
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+}
+
+type otlpScopeMetrics struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpPayload struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+func otlpAttr(key, value string) otlpAttribute {
+	a := otlpAttribute{Key: key}
+	a.Value.StringValue = value
+	return a
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// payload builds one push's body from rl's current aggregates.
+func (e *OTLPExporter) payload(rl *RateLimiter, now time.Time) otlpPayload {
+	nowNano := unixNano(now)
+	startNano := unixNano(e.start)
+
+	metrics := []otlpMetric{{
+		Name: "signalfence.keys",
+		Gauge: &otlpGauge{DataPoints: []otlpDataPoint{
+			{TimeUnixNano: nowNano, AsInt: strconv.Itoa(rl.store.Len())},
+		}},
+	}}
+
+	routes := rl.RouteSnapshot()
+	names := make([]string, 0, len(routes))
+	for name := range routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var requests, denied []otlpDataPoint
+	for _, name := range names {
+		stats := routes[name]
+		attrs := []otlpAttribute{otlpAttr("route", name)}
+		requests = append(requests, otlpDataPoint{
+			Attributes:        attrs,
+			StartTimeUnixNano: startNano,
+			TimeUnixNano:      nowNano,
+			AsInt:             strconv.FormatUint(stats.Requests, 10),
+		})
+		denied = append(denied, otlpDataPoint{
+			Attributes:        attrs,
+			StartTimeUnixNano: startNano,
+			TimeUnixNano:      nowNano,
+			AsInt:             strconv.FormatUint(stats.Denied, 10),
+		})
+	}
+	for _, m := range []struct {
+		name   string
+		points []otlpDataPoint
+	}{
+		{"signalfence.requests", requests},
+		{"signalfence.denied", denied},
+	} {
+		if len(m.points) == 0 {
+			continue
+		}
+		metrics = append(metrics, otlpMetric{
+			Name: m.name,
+			Sum:  &otlpSum{AggregationTemporality: 2, IsMonotonic: true, DataPoints: m.points},
+		})
+	}
+
+	service := e.ServiceName
+	if service == "" {
+		service = "signalfence"
+	}
+	var p otlpPayload
+	p.ResourceMetrics = make([]otlpResourceMetrics, 1)
+	p.ResourceMetrics[0].Resource.Attributes = []otlpAttribute{otlpAttr("service.name", service)}
+	p.ResourceMetrics[0].ScopeMetrics = make([]otlpScopeMetrics, 1)
+	p.ResourceMetrics[0].ScopeMetrics[0].Scope.Name = "signalfence"
+	p.ResourceMetrics[0].ScopeMetrics[0].Metrics = metrics
+	return p
+}
+
+// Push sends one snapshot immediately.
+func (e *OTLPExporter) Push(ctx context.Context, rl *RateLimiter) error {
+	if e.start.IsZero() {
+		e.start = time.Now()
+	}
+	body, err := json.Marshal(e.payload(rl, time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	client := e.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp push %s: status %d", e.Endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// Start pushes on the configured interval under the limiter's worker
+// supervision (see Health, worker "otlp-export") until the limiter is
+// closed.
+func (e *OTLPExporter) Start(rl *RateLimiter) {
+	every := e.Every
+	if every <= 0 {
+		every = DefaultOTLPEvery
+	}
+	rl.Go("otlp-export", func(ctx context.Context) {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.Push(ctx, rl); err != nil && e.OnError != nil {
+					e.OnError(err)
+				}
+			}
+		}
+	})
+}
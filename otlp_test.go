@@ -0,0 +1,121 @@
+package signalfence
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// decodeOTLP pulls the flat metric list out of a captured push body.
+func decodeOTLP(t *testing.T, body []byte) []otlpMetric {
+	t.Helper()
+	var p otlpPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.ResourceMetrics) != 1 || len(p.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("payload shape = %+v", p)
+	}
+	return p.ResourceMetrics[0].ScopeMetrics[0].Metrics
+}
+
+func TestOTLPPushExportsAggregates(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+	}))
+	defer srv.Close()
+
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001, TrackTraffic: true})
+	hitRoute(t, rl, "1.1.1.1:1", "/api/data", 3) // 1 allowed, 2 denied
+
+	e := &OTLPExporter{Endpoint: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}}
+	if err := e.Push(context.Background(), rl); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer x" {
+		t.Fatalf("Authorization = %q", auth)
+	}
+
+	metrics := decodeOTLP(t, <-bodies)
+	byName := make(map[string]otlpMetric, len(metrics))
+	for _, m := range metrics {
+		byName[m.Name] = m
+	}
+	if g := byName["signalfence.keys"].Gauge; g == nil || g.DataPoints[0].AsInt != "1" {
+		t.Fatalf("keys gauge = %+v", byName["signalfence.keys"])
+	}
+	reqs := byName["signalfence.requests"].Sum
+	if reqs == nil || !reqs.IsMonotonic || reqs.AggregationTemporality != 2 {
+		t.Fatalf("requests sum = %+v", reqs)
+	}
+	dp := reqs.DataPoints[0]
+	if dp.AsInt != "3" || dp.Attributes[0].Value.StringValue != "/api/data" {
+		t.Fatalf("requests point = %+v", dp)
+	}
+	if denied := byName["signalfence.denied"].Sum; denied == nil || denied.DataPoints[0].AsInt != "2" {
+		t.Fatalf("denied sum = %+v", byName["signalfence.denied"])
+	}
+}
+
+func TestOTLPPushReportsBadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	e := &OTLPExporter{Endpoint: srv.URL}
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	if err := e.Push(context.Background(), rl); err == nil {
+		t.Fatal("502 push reported no error")
+	}
+}
+
+func TestOTLPStartPushesOnInterval(t *testing.T) {
+	pushes := make(chan struct{}, 8)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushes <- struct{}{}
+	}))
+	defer srv.Close()
+
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	defer rl.Close()
+	(&OTLPExporter{Endpoint: srv.URL, Every: 5 * time.Millisecond}).Start(rl)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-pushes:
+		case <-time.After(2 * time.Second):
+			t.Fatal("no push within deadline")
+		}
+	}
+	if _, ok := rl.Health()["otlp-export"]; !ok {
+		t.Fatal("exporter not supervised")
+	}
+}
+
+func TestParseConfigPushMetrics(t *testing.T) {
+	fc, err := ParseConfig([]byte(`{
+		"default": {"capacity": 10, "refill_per_sec": 5},
+		"push_metrics": {"endpoint": "http://collector:4318/v1/metrics", "every": "30s"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc.PushMetrics.Endpoint != "http://collector:4318/v1/metrics" {
+		t.Fatalf("endpoint = %q", fc.PushMetrics.Endpoint)
+	}
+
+	if _, err := ParseConfig([]byte(`{"default": {"capacity": 1}, "push_metrics": {}}`)); err == nil {
+		t.Fatal("missing endpoint accepted")
+	}
+	if _, err := ParseConfig([]byte(`{"default": {"capacity": 1}, "push_metrics": {"endpoint": "x", "every": "soon"}}`)); err == nil {
+		t.Fatal("bad every accepted")
+	}
+}
@@ -0,0 +1,82 @@
+package signalfence
+
+import (
+	"sync"
+	"time"
+)
+
+// Pacer emits ticks at the rate key's policy permits, like a time.Ticker
+// driven by the bucket refill. Each tick corresponds to one admitted token,
+// so a job runner that does one unit of work per tick emits at exactly the
+// permitted rate instead of polling Allow in a loop:
+//
+//	p := limiter.Pace("worker:emails")
+//	defer p.Stop()
+//	for range p.C {
+//		sendNext()
+//	}
+type Pacer struct {
+	// C delivers one tick per admitted token. It is closed by Stop and when
+	// the limiter is closed.
+	C    <-chan time.Time
+	done chan struct{}
+	once sync.Once
+}
+
+// Stop releases the pacer's goroutine and closes C. A token already taken
+// for an undelivered tick is forfeited. Stop is idempotent.
+func (p *Pacer) Stop() {
+	p.once.Do(func() { close(p.done) })
+}
+
+// Pace returns a Pacer ticking at the rate the default policy (plus any
+// escalation, clampdown and warm-up scaling) grants key. The bucket is
+// shared with Allow, so paced work and request traffic draw from the same
+// budget. The channel is unbuffered: if the consumer stalls, the pacer
+// holds at most one taken token and the rate never overshoots.
+func (rl *RateLimiter) Pace(key string) *Pacer {
+	c := make(chan time.Time)
+	p := &Pacer{C: c, done: make(chan struct{})}
+	go func() {
+		defer close(c)
+		for {
+			ok, retry := rl.paceTake(key)
+			if !ok {
+				if retry <= 0 {
+					retry = time.Millisecond
+				}
+				select {
+				case <-time.After(retry):
+					continue
+				case <-p.done:
+					return
+				case <-rl.bgCtx.Done():
+					return
+				}
+			}
+			select {
+			case c <- rl.now():
+			case <-p.done:
+				return
+			case <-rl.bgCtx.Done():
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// paceTake consumes one token for key under the resolved default policy,
+// reporting the wait until the next token when the bucket is empty.
+func (rl *RateLimiter) paceTake(key string) (bool, time.Duration) {
+	pol := rl.EffectivePolicy("")
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+	b := rl.bucketFor(key, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	ok, _, retry := b.Take(1, rl.now())
+	return ok, retry
+}
@@ -0,0 +1,65 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaceEmitsAtPermittedRate(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 100})
+	p := rl.Pace("worker")
+	defer p.Stop()
+
+	// One burst token plus 9 refills at 100/s: 10 ticks need >= ~90ms.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		select {
+		case <-p.C:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("tick %d never arrived", i)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("10 ticks in %v; pacing is not happening", elapsed)
+	}
+}
+
+func TestPaceStopClosesChannel(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 5, RefillPerSec: 1000})
+	p := rl.Pace("worker")
+	<-p.C
+	p.Stop()
+	p.Stop() // idempotent
+
+	select {
+	case _, open := <-p.C:
+		if open {
+			// A tick may already be in flight; the next receive must see
+			// the close.
+			if _, open = <-p.C; open {
+				t.Fatal("channel still open after Stop")
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after Stop")
+	}
+}
+
+func TestPaceStopsWithLimiter(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 1000})
+	p := rl.Pace("worker")
+	<-p.C
+	rl.Close()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case _, open := <-p.C:
+			if !open {
+				return
+			}
+		case <-deadline:
+			t.Fatal("pacer kept running after limiter close")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package signalfence
+
+import (
+	"context"
+	"time"
+)
+
+// PolicySource delivers policy snapshots pushed from a central control
+// plane. Implementations wrap whatever transport the plane speaks — a
+// long-poll HTTP endpoint, a gRPC stream, a message bus subscription. Next
+// blocks until a new snapshot is available and returns it; it returns
+// ctx.Err() when ctx ends, and the transport error when the source fails.
+type PolicySource interface {
+	Next(ctx context.Context) (Config, error)
+}
+
+// Follower backoff bounds: after a source failure the follower retries at
+// followRetryMin, doubling per consecutive failure up to followRetryMax.
+const (
+	followRetryMin = time.Second
+	followRetryMax = 30 * time.Second
+)
+
+// FollowPolicies applies snapshots from src to the limiter as they arrive,
+// in a background goroutine that runs until the limiter is closed. Each
+// snapshot goes through Reload, so it bumps the config version and is held
+// in the rollback history like any other reload.
+//
+// Source failures never degrade enforcement: the limiter keeps serving the
+// last snapshot that applied cleanly while the follower retries with
+// exponential backoff, resetting the backoff after the next success. If
+// OnError is non-nil it is called with each source error.
+func (rl *RateLimiter) FollowPolicies(src PolicySource, onError func(error)) {
+	rl.Go("policy-follower", func(ctx context.Context) {
+		retry := followRetryMin
+		for {
+			cfg, err := src.Next(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				select {
+				case <-time.After(retry):
+				case <-ctx.Done():
+					return
+				}
+				if retry *= 2; retry > followRetryMax {
+					retry = followRetryMax
+				}
+				continue
+			}
+			retry = followRetryMin
+			rl.Reload(cfg)
+		}
+	})
+}
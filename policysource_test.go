@@ -0,0 +1,91 @@
+package signalfence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// chanSource is a PolicySource fed by a test: each value is either a
+// snapshot or an error.
+type chanSource struct {
+	updates chan Config
+	errs    chan error
+}
+
+func (s *chanSource) Next(ctx context.Context) (Config, error) {
+	select {
+	case cfg := <-s.updates:
+		return cfg, nil
+	case err := <-s.errs:
+		return Config{}, err
+	case <-ctx.Done():
+		return Config{}, ctx.Err()
+	}
+}
+
+func waitVersion(t *testing.T, rl *RateLimiter, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if rl.ConfigVersion() >= want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("config version stuck at %d, want %d", rl.ConfigVersion(), want)
+}
+
+func TestFollowPoliciesAppliesSnapshots(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	defer rl.Close()
+
+	src := &chanSource{updates: make(chan Config), errs: make(chan error)}
+	rl.FollowPolicies(src, nil)
+
+	src.updates <- Config{Capacity: 50, RefillPerSec: 5}
+	waitVersion(t, rl, 2)
+	if pol := rl.EffectivePolicy(""); pol.Capacity != 50 || pol.RefillPerSec != 5 {
+		t.Fatalf("snapshot not applied: %+v", pol)
+	}
+
+	src.updates <- Config{
+		Capacity:     50,
+		RefillPerSec: 5,
+		Routes:       map[string]Policy{"/search": {Capacity: 3, RefillPerSec: 1}},
+	}
+	waitVersion(t, rl, 3)
+	if pol := rl.EffectivePolicy("/search"); pol.Capacity != 3 {
+		t.Fatalf("route override not applied: %+v", pol)
+	}
+}
+
+func TestFollowPoliciesKeepsLastKnownGood(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	defer rl.Close()
+
+	errs := make(chan error, 1)
+	src := &chanSource{updates: make(chan Config), errs: make(chan error)}
+	rl.FollowPolicies(src, func(err error) { errs <- err })
+
+	src.updates <- Config{Capacity: 50, RefillPerSec: 5}
+	waitVersion(t, rl, 2)
+
+	// A source failure is reported but the applied policy survives.
+	src.errs <- errors.New("control plane unreachable")
+	select {
+	case err := <-errs:
+		if err == nil || err.Error() != "control plane unreachable" {
+			t.Fatalf("onError got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("source error was not reported")
+	}
+	if pol := rl.EffectivePolicy(""); pol.Capacity != 50 {
+		t.Fatalf("last-known-good policy lost: %+v", pol)
+	}
+	if rl.ConfigVersion() != 2 {
+		t.Fatalf("config version changed on failure: %d", rl.ConfigVersion())
+	}
+}
@@ -0,0 +1,73 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ProblemTypeRateLimited identifies rate limit denials in RFC 9457 problem
+// documents.
+const ProblemTypeRateLimited = "https://signalfence.dev/problems/rate-limited"
+
+// Problem is an RFC 9457 problem document describing a denial. Besides the
+// standard fields it carries machine-readable rate limit state so SDKs in
+// any language can implement consistent backoff.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+
+	// Limit and Remaining mirror the X-RateLimit headers.
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+	// RetryAfter is the advised wait in seconds, with sub-second precision
+	// the Retry-After header cannot express.
+	RetryAfter float64 `json:"retry_after"`
+	// Reset is the Unix time at which the client may retry.
+	Reset int64 `json:"reset"`
+	// Policy is the route whose policy produced the denial; empty means
+	// the default policy.
+	Policy string `json:"policy,omitempty"`
+	// Banned is true when the key is blocklisted rather than out of
+	// tokens.
+	Banned bool `json:"banned"`
+	// Docs links the limit's documentation when the policy configures a
+	// DocsURL.
+	Docs string `json:"docs,omitempty"`
+}
+
+// NewProblem builds the problem document for a denial on route.
+func NewProblem(route string, d Decision) Problem {
+	detail := "request rate limit exceeded"
+	if d.Banned {
+		detail = "client is temporarily blocklisted"
+	}
+	return Problem{
+		Type:       ProblemTypeRateLimited,
+		Title:      http.StatusText(http.StatusTooManyRequests),
+		Status:     http.StatusTooManyRequests,
+		Detail:     detail,
+		Limit:      d.Limit,
+		Remaining:  d.Remaining,
+		RetryAfter: d.RetryAfter.Seconds(),
+		Reset:      time.Now().Add(d.RetryAfter).Unix(),
+		Policy:     route,
+		Banned:     d.Banned,
+	}
+}
+
+// WriteProblem writes the problem document for a denial on route with the
+// application/problem+json content type.
+func WriteProblem(w http.ResponseWriter, route string, d Decision) {
+	writeProblemDoc(w, NewProblem(route, d))
+}
+
+// writeProblemDoc writes an already-built (possibly customized) problem
+// document.
+func writeProblemDoc(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(p)
+}
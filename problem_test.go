@@ -0,0 +1,39 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemJSONDenial(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001, ProblemJSON: true})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("content type = %q", ct)
+	}
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != ProblemTypeRateLimited || p.Status != 429 || p.Limit != 1 {
+		t.Fatalf("problem = %+v", p)
+	}
+	if p.RetryAfter <= 0 || p.Reset == 0 {
+		t.Fatalf("problem lacks backoff fields: %+v", p)
+	}
+	if p.Policy != "/api" {
+		t.Fatalf("policy = %q", p.Policy)
+	}
+}
@@ -0,0 +1,45 @@
+package signalfence
+
+import (
+	"net/http"
+	"sync"
+)
+
+// extractorRegistry holds application-registered named extractors, checked
+// by ParseExtractor before its built-in grammar.
+var extractorRegistry struct {
+	mu  sync.RWMutex
+	fns map[string]func(*http.Request) string
+}
+
+// RegisterExtractor makes fn available to config files under name, so
+// config-driven deployments can use custom extraction logic:
+//
+//	signalfence.RegisterExtractor("mycorp-session", sessionKey)
+//
+// then `"extractor": "mycorp-session"` in the config file. Registered names
+// take precedence over the built-in grammar and are usable inside join
+// specs. Register at startup, before configs are loaded; it panics on an
+// empty name or nil fn, like http.Handle on bad input.
+func RegisterExtractor(name string, fn func(*http.Request) string) {
+	if name == "" {
+		panic("signalfence: RegisterExtractor with empty name")
+	}
+	if fn == nil {
+		panic("signalfence: RegisterExtractor with nil extractor")
+	}
+	extractorRegistry.mu.Lock()
+	defer extractorRegistry.mu.Unlock()
+	if extractorRegistry.fns == nil {
+		extractorRegistry.fns = make(map[string]func(*http.Request) string)
+	}
+	extractorRegistry.fns[name] = fn
+}
+
+// registeredExtractor looks up a name registered via RegisterExtractor.
+func registeredExtractor(name string) (func(*http.Request) string, bool) {
+	extractorRegistry.mu.RLock()
+	defer extractorRegistry.mu.RUnlock()
+	fn, ok := extractorRegistry.fns[name]
+	return fn, ok
+}
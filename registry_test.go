@@ -0,0 +1,52 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterExtractor(t *testing.T) {
+	RegisterExtractor("test-session", func(r *http.Request) string {
+		return "session:" + r.Header.Get("X-Session")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Session", "s42")
+
+	fn, err := ParseExtractor("test-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fn(req); got != "session:s42" {
+		t.Fatalf("registered extractor key = %q", got)
+	}
+
+	// Registered names work inside join specs and from config files.
+	fn, err = ParseExtractor("join:test-session+path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fn(req); got != "session:s42|/" {
+		t.Fatalf("join with registered extractor key = %q", got)
+	}
+	if _, err := ParseConfig([]byte(`{"extractor": "test-session"}`)); err != nil {
+		t.Fatalf("config with registered extractor rejected: %v", err)
+	}
+}
+
+func TestRegisterExtractorPanics(t *testing.T) {
+	for name, call := range map[string]func(){
+		"empty name": func() { RegisterExtractor("", ClientIP) },
+		"nil fn":     func() { RegisterExtractor("x", nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			call()
+		}()
+	}
+}
@@ -0,0 +1,70 @@
+package signalfence
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReloadAndRollback(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 20, RefillPerSec: 10})
+	if v := rl.ConfigVersion(); v != 1 {
+		t.Fatalf("initial version = %d, want 1", v)
+	}
+
+	rl.Reload(Config{Capacity: 5, RefillPerSec: 1})
+	if got := rl.EffectivePolicy(""); got.Capacity != 5 || got.RefillPerSec != 1 {
+		t.Fatalf("after reload: %+v", got)
+	}
+	if v := rl.ConfigVersion(); v != 2 {
+		t.Fatalf("version after reload = %d, want 2", v)
+	}
+
+	if !rl.Rollback() {
+		t.Fatal("rollback should succeed")
+	}
+	if got := rl.EffectivePolicy(""); got.Capacity != 20 || got.RefillPerSec != 10 {
+		t.Fatalf("after rollback: %+v", got)
+	}
+	if rl.Rollback() {
+		t.Fatal("second rollback should fail: history exhausted")
+	}
+}
+
+func TestDecisionCarriesConfigVersion(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 3, RefillPerSec: 1})
+	rl.Reload(Config{Capacity: 4, RefillPerSec: 1})
+	if d := rl.Allow("k"); d.ConfigVersion != 2 {
+		t.Fatalf("ConfigVersion = %d, want 2", d.ConfigVersion)
+	}
+}
+
+func TestReloadAppliesToExistingBuckets(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	rl.Allow("k") // create the bucket under the old limits
+	rl.Reload(Config{Capacity: 2, RefillPerSec: 1})
+	rl.Allow("k")
+	rl.Allow("k")
+	if d := rl.Allow("k"); d.Allowed {
+		t.Fatalf("want rejection under reloaded capacity 2, got %+v", d)
+	}
+}
+
+func TestRollbackEndpoint(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 20, RefillPerSec: 10})
+	rl.Reload(Config{Capacity: 1, RefillPerSec: 1})
+
+	rec := httptest.NewRecorder()
+	rl.AdminHandler().ServeHTTP(rec, httptest.NewRequest("POST", "/admin/config/rollback", nil))
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got := rl.EffectivePolicy(""); got.Capacity != 20 {
+		t.Fatalf("after rollback: %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	rl.AdminHandler().ServeHTTP(rec, httptest.NewRequest("POST", "/admin/config/rollback", nil))
+	if rec.Code != 409 {
+		t.Fatalf("exhausted rollback status = %d, want 409", rec.Code)
+	}
+}
@@ -0,0 +1,109 @@
+package signalfence
+
+import (
+	"context"
+	"net/http"
+)
+
+// CostFunc maps a finished request to its true token cost, from whatever
+// the response revealed: status, observed body size, or the request
+// itself. It runs after the handler returns.
+type CostFunc func(r *http.Request, status int, bytesWritten int64) float64
+
+// CostPerBytes returns a CostFunc charging one token per n response bytes,
+// with a minimum of one token per request.
+func CostPerBytes(n int64) CostFunc {
+	return func(_ *http.Request, _ int, written int64) float64 {
+		cost := float64(written) / float64(n)
+		if cost < 1 {
+			return 1
+		}
+		return cost
+	}
+}
+
+// costCtxKey carries the per-request cost state through the context.
+type costCtxKey struct{}
+
+// requestCost is the mutable per-request state MiddlewareCost threads to
+// the handler: the admission decision and an optional explicit cost.
+type requestCost struct {
+	decision Decision
+	set      bool
+	cost     float64
+}
+
+// SetCost records an explicit token cost for the current request,
+// overriding the middleware's CostFunc — a handler that knows it just
+// served an expensive query can price it directly. It must be called
+// before the handler returns, and reports false when the request did not
+// pass through MiddlewareCost.
+func SetCost(r *http.Request, cost float64) bool {
+	c, ok := r.Context().Value(costCtxKey{}).(*requestCost)
+	if !ok {
+		return false
+	}
+	c.set, c.cost = true, cost
+	return true
+}
+
+// DecisionFrom returns the admission Decision for the current request. It
+// reports false when the request did not pass through MiddlewareCost.
+func DecisionFrom(r *http.Request) (Decision, bool) {
+	c, ok := r.Context().Value(costCtxKey{}).(*requestCost)
+	if !ok {
+		return Decision{}, false
+	}
+	return c.decision, true
+}
+
+// costWriter counts response bytes and remembers the status for CostFunc.
+type costWriter struct {
+	byteCountWriter
+	status int
+}
+
+func (w *costWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// MiddlewareCost wraps next with rate limiting whose token cost is settled
+// after the response: requests are admitted at a cost of one token, and
+// once the handler returns the true cost — an explicit SetCost value, or
+// otherwise costOf applied to the observed response — is charged
+// retroactively, so heavy responses count more even when the request
+// looked cheap up front. Costs above one are force-taken into debt, which
+// refill repays before further requests pass; costs of one or below charge
+// nothing extra. A nil costOf charges per CostPerBytes(1024).
+func (rl *RateLimiter) MiddlewareCost(costOf CostFunc, next http.Handler) http.Handler {
+	if costOf == nil {
+		costOf = CostPerBytes(1024)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := rl.keyFunc(r)
+		route := rl.routeOf(r.URL.Path)
+		d := rl.AllowRoute(key, route)
+		writeLimitHeaders(w, d)
+		if !d.Allowed {
+			rl.reject(w, r, route, d)
+			return
+		}
+
+		rc := &requestCost{decision: d}
+		cw := &costWriter{byteCountWriter: byteCountWriter{ResponseWriter: w}, status: http.StatusOK}
+		next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), costCtxKey{}, rc)))
+
+		cost := rc.cost
+		if !rc.set {
+			cost = costOf(r, cw.status, int64(cw.n))
+		}
+		// One token was paid at admission; charge the remainder.
+		if extra := cost - 1; extra > 0 {
+			rl.charge(key, route, extra)
+		}
+		if rl.cfg.TrackTraffic {
+			rl.traffic.record(key, true, declaredBytes(r), cw.n)
+		}
+	})
+}
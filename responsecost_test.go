@@ -0,0 +1,84 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareCostChargesObservedBytes(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001, Now: clock.Now})
+
+	body := strings.Repeat("x", 3*1024)
+	h := rl.MiddlewareCost(CostPerBytes(1024), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+
+	req := httptest.NewRequest("GET", "/report", nil)
+	req.RemoteAddr = "10.0.0.1:999"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	// A 3 KiB response at one token per KiB costs 3: one at admission,
+	// two charged after the fact.
+	if got := rl.Peek("10.0.0.1", "/report").Remaining; got != 7 {
+		t.Fatalf("remaining = %d, want 7", got)
+	}
+}
+
+func TestMiddlewareCostHandlerOverride(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 0.001, Now: clock.Now})
+
+	h := rl.MiddlewareCost(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d, ok := DecisionFrom(r); !ok || !d.Allowed {
+			t.Errorf("DecisionFrom = %+v, %v", d, ok)
+		}
+		if !SetCost(r, 5) {
+			t.Error("SetCost outside middleware?")
+		}
+		w.Write([]byte("tiny"))
+	}))
+
+	req := httptest.NewRequest("GET", "/expensive", nil)
+	req.RemoteAddr = "10.0.0.2:999"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	// The handler priced the request at 5 despite the tiny body.
+	if got := rl.Peek("10.0.0.2", "/expensive").Remaining; got != 5 {
+		t.Fatalf("remaining = %d, want 5", got)
+	}
+
+	// Outside the middleware both helpers decline gracefully.
+	plain := httptest.NewRequest("GET", "/", nil)
+	if SetCost(plain, 1) {
+		t.Error("SetCost succeeded without middleware")
+	}
+	if _, ok := DecisionFrom(plain); ok {
+		t.Error("DecisionFrom succeeded without middleware")
+	}
+}
+
+func TestMiddlewareCostDenies(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001, Now: clock.Now})
+
+	h := rl.MiddlewareCost(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.3:999"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("drained bucket status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("denial missing Retry-After")
+	}
+}
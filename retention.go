@@ -0,0 +1,52 @@
+package signalfence
+
+import (
+	"context"
+	"time"
+)
+
+// PurgeRetained drops per-key traffic counters for keys not seen within
+// retention, returning how many were purged. Counters are the only
+// observability state the limiter holds per client: the access log streams
+// to its sink, and abuse scores are derived on demand, so purging counters
+// is what bounds how long personal data is retained here. Bans,
+// escalations and trust grants are functional state with their own expiry
+// and are not touched.
+func (rl *RateLimiter) PurgeRetained(retention time.Duration) int {
+	cutoff := time.Now().Add(-retention).UnixNano()
+	purged := 0
+	rl.traffic.keys.Range(func(k, v any) bool {
+		if v.(*trafficCounters).lastSeen.Load() < cutoff {
+			rl.traffic.keys.Delete(k)
+			purged++
+		}
+		return true
+	})
+	return purged
+}
+
+// StartRetention begins background purging of per-key metrics older than
+// retention, sweeping at a quarter of the window so nothing outlives it by
+// much. The sweeps stop when the limiter is closed. Configure it from a
+// file with FileConfig.MetricsRetention.
+func (rl *RateLimiter) StartRetention(retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	every := retention / 4
+	if every < time.Second {
+		every = time.Second
+	}
+	rl.Go("retention", func(ctx context.Context) {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rl.PurgeRetained(retention)
+			}
+		}
+	})
+}
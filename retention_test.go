@@ -0,0 +1,35 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPurgeRetained(t *testing.T) {
+	rl := NewRateLimiter(Config{TrackTraffic: true})
+	rl.traffic.record("old", true, 0, 0)
+	rl.traffic.keys.Range(func(_, v any) bool {
+		v.(*trafficCounters).lastSeen.Store(time.Now().Add(-time.Hour).UnixNano())
+		return true
+	})
+	rl.traffic.record("fresh", true, 0, 0)
+
+	if purged := rl.PurgeRetained(30 * time.Minute); purged != 1 {
+		t.Fatalf("purged = %d, want 1", purged)
+	}
+	if s := rl.Traffic("old"); s.Requests != 0 {
+		t.Fatal("stale counters survived the purge")
+	}
+	if s := rl.Traffic("fresh"); s.Requests != 1 {
+		t.Fatal("fresh counters purged")
+	}
+}
+
+func TestMetricsRetentionConfig(t *testing.T) {
+	if _, err := ParseConfig([]byte(`{"metrics_retention": "720h"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseConfig([]byte(`{"metrics_retention": "soon"}`)); err == nil {
+		t.Fatal("invalid retention accepted")
+	}
+}
@@ -0,0 +1,163 @@
+package signalfence
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// maxRouteOffenders caps how many distinct denied keys each route tracks,
+// so offender accounting stays bounded under key churn. A key denied after
+// the cap is reached is simply not ranked.
+const maxRouteOffenders = 256
+
+// RouteStats aggregates per-route admission counters for the dashboard's
+// route table. Requires TrackTraffic, like the per-key counters.
+type RouteStats struct {
+	Requests uint64 `json:"requests"`
+	Denied   uint64 `json:"denied"`
+}
+
+// BlockRate is the fraction of the route's requests that were denied.
+func (s RouteStats) BlockRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Denied) / float64(s.Requests)
+}
+
+// Offender is one key's denial count on a route.
+type Offender struct {
+	Key    string `json:"key"`
+	Denied uint64 `json:"denied"`
+}
+
+// routeCounters is one route's counters plus its denied-key tally.
+type routeCounters struct {
+	requests  atomic.Uint64
+	denied    atomic.Uint64
+	mu        sync.Mutex
+	offenders map[string]uint64
+}
+
+// routeTrafficStats tracks counters per route.
+type routeTrafficStats struct {
+	routes sync.Map // route string -> *routeCounters
+}
+
+func (rs *routeTrafficStats) record(route, key string, allowed bool) {
+	v, ok := rs.routes.Load(route)
+	if !ok {
+		v, _ = rs.routes.LoadOrStore(route, &routeCounters{})
+	}
+	c := v.(*routeCounters)
+	c.requests.Add(1)
+	if allowed {
+		return
+	}
+	c.denied.Add(1)
+	c.mu.Lock()
+	if c.offenders == nil {
+		c.offenders = make(map[string]uint64)
+	}
+	if _, seen := c.offenders[key]; seen || len(c.offenders) < maxRouteOffenders {
+		c.offenders[key]++
+	}
+	c.mu.Unlock()
+}
+
+// forget removes key from every route's offender tally. The aggregate
+// request and denial counters stay — they are not attributable to a key.
+func (rs *routeTrafficStats) forget(key string) {
+	rs.routes.Range(func(_, v any) bool {
+		c := v.(*routeCounters)
+		c.mu.Lock()
+		delete(c.offenders, key)
+		c.mu.Unlock()
+		return true
+	})
+}
+
+// RouteStats returns the accumulated counters for route. All zeros means
+// the route has not been seen (or traffic tracking is disabled).
+func (rl *RateLimiter) RouteStats(route string) RouteStats {
+	v, ok := rl.perRoute.routes.Load(route)
+	if !ok {
+		return RouteStats{}
+	}
+	c := v.(*routeCounters)
+	return RouteStats{Requests: c.requests.Load(), Denied: c.denied.Load()}
+}
+
+// RouteSnapshot returns the counters for every route seen since startup.
+func (rl *RateLimiter) RouteSnapshot() map[string]RouteStats {
+	out := make(map[string]RouteStats)
+	rl.perRoute.routes.Range(func(k, v any) bool {
+		c := v.(*routeCounters)
+		out[k.(string)] = RouteStats{Requests: c.requests.Load(), Denied: c.denied.Load()}
+		return true
+	})
+	return out
+}
+
+// TopOffenders returns up to n keys with the most denials on route, most
+// denied first.
+func (rl *RateLimiter) TopOffenders(route string, n int) []Offender {
+	v, ok := rl.perRoute.routes.Load(route)
+	if !ok || n <= 0 {
+		return nil
+	}
+	c := v.(*routeCounters)
+	c.mu.Lock()
+	all := make([]Offender, 0, len(c.offenders))
+	for key, denied := range c.offenders {
+		all = append(all, Offender{Key: key, Denied: denied})
+	}
+	c.mu.Unlock()
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Denied != all[j].Denied {
+			return all[i].Denied > all[j].Denied
+		}
+		return all[i].Key < all[j].Key
+	})
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+// SetRoutePolicy installs or replaces the policy override for route at
+// runtime. The change goes through Reload, so it is versioned and Rollback
+// reverts it like any other config change.
+func (rl *RateLimiter) SetRoutePolicy(route string, pol Policy) {
+	rl.mu.RLock()
+	cfg := rl.cfg
+	rl.mu.RUnlock()
+	routes := make(map[string]Policy, len(cfg.Routes)+1)
+	for r, p := range cfg.Routes {
+		routes[r] = p
+	}
+	routes[route] = pol
+	cfg.Routes = routes
+	rl.Reload(cfg)
+}
+
+// ClearRoutePolicy removes route's override, dropping it back to the
+// default policy. Routes without an override are left untouched.
+func (rl *RateLimiter) ClearRoutePolicy(route string) {
+	rl.mu.RLock()
+	cfg := rl.cfg
+	_, had := cfg.Routes[route]
+	rl.mu.RUnlock()
+	if !had {
+		return
+	}
+	routes := make(map[string]Policy, len(cfg.Routes))
+	for r, p := range cfg.Routes {
+		if r != route {
+			routes[r] = p
+		}
+	}
+	cfg.Routes = routes
+	rl.Reload(cfg)
+}
@@ -0,0 +1,152 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func hitRoute(t *testing.T, rl *RateLimiter, addr, path string, n int) {
+	t.Helper()
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < n; i++ {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = addr
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func TestRouteStatsCountsAndOffenders(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, TrackTraffic: true})
+
+	hitRoute(t, rl, "1.1.1.1:1", "/api/data", 5) // 2 allowed, 3 denied
+	hitRoute(t, rl, "2.2.2.2:1", "/api/data", 3) // 2 allowed, 1 denied
+
+	stats := rl.RouteStats("/api/data")
+	if stats.Requests != 8 || stats.Denied != 4 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if rate := stats.BlockRate(); rate != 0.5 {
+		t.Fatalf("BlockRate = %v", rate)
+	}
+
+	top := rl.TopOffenders("/api/data", 5)
+	if len(top) != 2 || top[0].Key != "1.1.1.1" || top[0].Denied != 3 {
+		t.Fatalf("TopOffenders = %+v", top)
+	}
+	if got := rl.TopOffenders("/api/data", 1); len(got) != 1 {
+		t.Fatalf("capped TopOffenders = %+v", got)
+	}
+	if rl.TopOffenders("/unseen", 5) != nil {
+		t.Fatal("unseen route returned offenders")
+	}
+}
+
+func TestSetRoutePolicyIsVersionedAndRevertible(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	before := rl.ConfigVersion()
+
+	rl.SetRoutePolicy("/api/data", Policy{Capacity: 3, RefillPerSec: 1})
+	if rl.ConfigVersion() == before {
+		t.Fatal("SetRoutePolicy did not bump the config version")
+	}
+	if got := rl.EffectivePolicy("/api/data").Capacity; got != 3 {
+		t.Fatalf("Capacity = %d", got)
+	}
+
+	if !rl.Rollback() {
+		t.Fatal("Rollback failed")
+	}
+	if got := rl.EffectivePolicy("/api/data").Capacity; got != 10 {
+		t.Fatalf("Capacity after rollback = %d", got)
+	}
+
+	rl.SetRoutePolicy("/api/data", Policy{Capacity: 3, RefillPerSec: 1})
+	rl.ClearRoutePolicy("/api/data")
+	if got := rl.EffectivePolicy("/api/data").Capacity; got != 10 {
+		t.Fatalf("Capacity after clear = %d", got)
+	}
+	// Clearing a route without an override must not burn a config version.
+	v := rl.ConfigVersion()
+	rl.ClearRoutePolicy("/never-set")
+	if rl.ConfigVersion() != v {
+		t.Fatal("ClearRoutePolicy of unset route bumped the version")
+	}
+}
+
+func TestAdminRoutesEndpoint(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, TrackTraffic: true})
+	h := rl.AdminHandler()
+	hitRoute(t, rl, "1.1.1.1:1", "/api/data", 3)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/routes", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var rows []struct {
+		Route     string  `json:"route"`
+		Policy    Policy  `json:"policy"`
+		Requests  uint64  `json:"requests"`
+		BlockRate float64 `json:"block_rate"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, row := range rows {
+		if row.Route == "/api/data" {
+			found = true
+			if row.Requests != 3 || row.Policy.Capacity != 2 {
+				t.Fatalf("row = %+v", row)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("no /api/data row in %+v", rows)
+	}
+
+	// The inline editor's write path.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/admin/routes?route=/api/data",
+		strings.NewReader(`{"capacity": 5, "refill_per_sec": 1}`)))
+	if w.Code != 204 {
+		t.Fatalf("PUT status = %d: %s", w.Code, w.Body)
+	}
+	if got := rl.EffectivePolicy("/api/data").Capacity; got != 5 {
+		t.Fatalf("Capacity after PUT = %d", got)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/routes?route=/api/data", nil))
+	if w.Code != 204 {
+		t.Fatalf("DELETE status = %d", w.Code)
+	}
+	if got := rl.EffectivePolicy("/api/data").Capacity; got != 2 {
+		t.Fatalf("Capacity after DELETE = %d", got)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/admin/routes",
+		strings.NewReader(`{"capacity": 5}`)))
+	if w.Code != 400 {
+		t.Fatalf("PUT without route: status = %d", w.Code)
+	}
+}
+
+func TestDashboardHandlerServesPage(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	w := httptest.NewRecorder()
+	rl.DashboardHandler().ServeHTTP(w, httptest.NewRequest("GET", "/dashboard", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "/admin/routes") {
+		t.Fatal("page does not reference the admin routes API")
+	}
+}
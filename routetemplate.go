@@ -0,0 +1,108 @@
+package signalfence
+
+// DefaultMaxTrackedRoutes is the cap on distinct routes the limiter tracks
+// when Config.MaxTrackedRoutes is zero. Routes beyond the cap collapse to
+// RouteOverflow.
+const DefaultMaxTrackedRoutes = 1000
+
+// RouteOverflow is the route that paths beyond the tracked-route cap are
+// folded into. It still gets the default policy; seeing it grow in metrics
+// means either a crawler probing random paths or a templating gap.
+const RouteOverflow = "{overflow}"
+
+// TemplateRoute collapses a path's variable segments into "{id}", so
+// "/api/users/7f3c.../orders/42" becomes "/api/users/{id}/orders/{id}" and
+// every user shares one route instead of minting one per UUID. Segments are
+// collapsed when they are purely numeric, UUID-shaped, or long hex strings
+// (16+ chars), which covers serial IDs, UUIDs and content hashes.
+func TemplateRoute(path string) string {
+	out := make([]byte, 0, len(path))
+	for i := 0; i < len(path); {
+		if path[i] == '/' {
+			out = append(out, '/')
+			i++
+			continue
+		}
+		j := i
+		for j < len(path) && path[j] != '/' {
+			j++
+		}
+		if isVariableSegment(path[i:j]) {
+			out = append(out, "{id}"...)
+		} else {
+			out = append(out, path[i:j]...)
+		}
+		i = j
+	}
+	return string(out)
+}
+
+// isVariableSegment reports whether a path segment looks like an
+// identifier rather than a route word.
+func isVariableSegment(s string) bool {
+	if s == "" {
+		return false
+	}
+	numeric, hexLike := true, true
+	hyphens := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F':
+			numeric = false
+		case c == '-':
+			numeric = false
+			hyphens++
+		default:
+			return false
+		}
+	}
+	if numeric {
+		return true
+	}
+	// UUID: 36 chars, 4 hyphens in the standard positions.
+	if len(s) == 36 && hyphens == 4 &&
+		s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-' {
+		return true
+	}
+	// Long hex: content hashes, session tokens and the like.
+	return hexLike && hyphens == 0 && len(s) >= 16
+}
+
+// routeOf resolves a request path to the route used for policy lookup and
+// metrics: templated unless RawRoutes is set, and folded into RouteOverflow
+// once the distinct-route cap is reached. Routes with a configured policy
+// or canary never overflow — their cardinality is bounded by the config.
+func (rl *RateLimiter) routeOf(path string) string {
+	route := path
+	if !rl.cfg.RawRoutes {
+		route = TemplateRoute(path)
+	}
+	max := rl.cfg.MaxTrackedRoutes
+	if max == 0 {
+		max = DefaultMaxTrackedRoutes
+	}
+	if max < 0 {
+		return route
+	}
+	rl.mu.RLock()
+	_, configured := rl.cfg.Routes[route]
+	if !configured {
+		_, configured = rl.cfg.Canaries[route]
+	}
+	rl.mu.RUnlock()
+	if configured {
+		return route
+	}
+	if _, ok := rl.routeSet.Load(route); ok {
+		return route
+	}
+	if rl.routeCount.Load() >= int64(max) {
+		return RouteOverflow
+	}
+	if _, loaded := rl.routeSet.LoadOrStore(route, struct{}{}); !loaded {
+		rl.routeCount.Add(1)
+	}
+	return route
+}
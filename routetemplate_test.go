@@ -0,0 +1,55 @@
+package signalfence
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTemplateRoute(t *testing.T) {
+	cases := map[string]string{
+		"/api/users/42":          "/api/users/{id}",
+		"/api/users/42/orders/7": "/api/users/{id}/orders/{id}",
+		"/api/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8": "/api/users/{id}",
+		"/blobs/deadbeefdeadbeef":                         "/blobs/{id}",
+		"/api/users":                                      "/api/users",
+		"/health":                                         "/health",
+		"/v2/status":                                      "/v2/status",
+		"/":                                               "/",
+		"":                                                "",
+	}
+	for path, want := range cases {
+		if got := TemplateRoute(path); got != want {
+			t.Errorf("TemplateRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRouteOfCapsCardinality(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		MaxTrackedRoutes: 3,
+		Routes:           map[string]Policy{"/configured": {Capacity: 1}},
+	})
+	for i := 0; i < 3; i++ {
+		path := fmt.Sprintf("/distinct-%c", 'a'+i)
+		if got := rl.routeOf(path); got != path {
+			t.Fatalf("routeOf(%q) = %q under cap", path, got)
+		}
+	}
+	if got := rl.routeOf("/one-too-many"); got != RouteOverflow {
+		t.Fatalf("routeOf over cap = %q, want %q", got, RouteOverflow)
+	}
+	// Already-seen routes and configured routes stay stable past the cap.
+	if got := rl.routeOf("/distinct-a"); got != "/distinct-a" {
+		t.Fatalf("seen route folded: %q", got)
+	}
+	if got := rl.routeOf("/configured"); got != "/configured" {
+		t.Fatalf("configured route folded: %q", got)
+	}
+}
+
+func TestRouteOfRawOptOut(t *testing.T) {
+	rl := NewRateLimiter(Config{RawRoutes: true, MaxTrackedRoutes: -1})
+	if got := rl.routeOf("/api/users/42"); got != "/api/users/42" {
+		t.Fatalf("RawRoutes still templated: %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package signalfence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// DefaultMaxKeyLength is the extracted-key length limit applied when
+// Config.MaxKeyLength is zero. Keys are map entries in the store and
+// fields in every log line and metric, so an unbounded client-controlled
+// header must not become an unbounded key.
+const DefaultMaxKeyLength = 512
+
+// SanitizeKey returns key with control characters stripped and, when the
+// result still exceeds max, replaced by a fixed-width hash. The hash keeps
+// the key-class prefix (the part up to the first ':') when there is a
+// short one, so hashed keys still classify in metrics, and it is
+// deterministic, so an oversized client keys the same bucket on every
+// request. Well-formed keys pass through without allocating.
+func SanitizeKey(key string, max int) string {
+	if max <= 0 {
+		max = DefaultMaxKeyLength
+	}
+	clean := key
+	if hasControlBytes(key) {
+		clean = strings.Map(func(r rune) rune {
+			if r < 0x20 || r == 0x7f {
+				return -1
+			}
+			return r
+		}, key)
+	}
+	if len(clean) <= max {
+		return clean
+	}
+	prefix := ""
+	if i := strings.IndexByte(clean, ':'); i >= 0 && i < 32 {
+		prefix = clean[:i+1]
+	}
+	sum := sha256.Sum256([]byte(clean))
+	return prefix + "sha256:" + hex.EncodeToString(sum[:16])
+}
+
+// hasControlBytes reports whether s contains bytes SanitizeKey strips,
+// keeping the common case allocation-free.
+func hasControlBytes(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/signalfence/core"
+	"github.com/yourusername/signalfence/store"
+)
+
+func TestSanitizeKey(t *testing.T) {
+	clean := "ip:10.1.1.1"
+	if got := SanitizeKey(clean, 0); got != clean {
+		t.Fatalf("clean key changed: %q", got)
+	}
+	if got := SanitizeKey("user:al\x00ice\r\n", 0); got != "user:alice" {
+		t.Fatalf("control chars: %q", got)
+	}
+
+	huge := "key:" + strings.Repeat("A", 100*1024)
+	got := SanitizeKey(huge, 0)
+	if len(got) > DefaultMaxKeyLength {
+		t.Fatalf("oversized key not capped: %d bytes", len(got))
+	}
+	if !strings.HasPrefix(got, "key:sha256:") {
+		t.Fatalf("class prefix lost: %q", got)
+	}
+	if again := SanitizeKey(huge, 0); again != got {
+		t.Fatalf("hash not deterministic: %q vs %q", again, got)
+	}
+	if other := SanitizeKey(huge+"B", 0); other == got {
+		t.Fatal("distinct oversized keys collided")
+	}
+}
+
+func TestSanitizeKeyCustomMax(t *testing.T) {
+	if got := SanitizeKey("short", 3); !strings.HasPrefix(got, "sha256:") {
+		t.Fatalf("key over custom max not hashed: %q", got)
+	}
+	if got := SanitizeKey("ok", 3); got != "ok" {
+		t.Fatalf("key under custom max changed: %q", got)
+	}
+}
+
+func TestLimiterSanitizesExtractedKeys(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     1,
+		RefillPerSec: 0.001,
+		KeyFunc: func(r *http.Request) string {
+			return "auth:" + r.Header.Get("Authorization")
+		},
+	})
+
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", strings.Repeat("x", 100*1024))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d", rec.Code)
+	}
+	// Same oversized header keys the same bucket, so it is now drained.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d; hashed key did not stick", rec.Code)
+	}
+	rl.store.(*store.MemoryStore).Range(func(key string, _ core.TokenBucket) bool {
+		if len(key) > DefaultMaxKeyLength {
+			t.Errorf("store holds oversized key (%d bytes)", len(key))
+		}
+		return true
+	})
+}
@@ -0,0 +1,34 @@
+package signalfence
+
+// SessionStart pre-warms a full bucket for key under pol, for login flows
+// that want a fresh session's limits in place — and its bucket allocated —
+// before its first request arrives. Zero fields of pol inherit from the
+// default policy, and the policy stays bound to the key until SessionEnd,
+// so sessions can run custom limits without a Routes entry. Starting a
+// session for a key that already has a bucket only updates its limits;
+// accumulated consumption is kept.
+func (rl *RateLimiter) SessionStart(key string, pol Policy) {
+	rl.sessions.Store(key, pol)
+	pol = mergePolicy(pol, rl.EffectivePolicy(""))
+	b := rl.bucketFor(key, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+}
+
+// SessionEnd tears down key's limiter state on logout: its session policy
+// is dropped, its bucket is deleted outright instead of lingering until
+// idle cleanup, and any trust grant is revoked. Bans and escalations
+// survive deliberately — logging out must not shed a penalty.
+func (rl *RateLimiter) SessionEnd(key string) {
+	rl.sessions.Delete(key)
+	rl.Trust(key, 0)
+	rl.store.Delete(key)
+}
+
+// sessionPolicy returns the policy bound to key by SessionStart, if any.
+func (rl *RateLimiter) sessionPolicy(key string) (Policy, bool) {
+	v, ok := rl.sessions.Load(key)
+	if !ok {
+		return Policy{}, false
+	}
+	return v.(Policy), true
+}
@@ -0,0 +1,44 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStartPrewarmsBucket(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 5, RefillPerSec: 0.001})
+
+	rl.SessionStart("session:a", Policy{Capacity: 50})
+	if rl.store.Len() != 1 {
+		t.Fatalf("store len = %d after SessionStart", rl.store.Len())
+	}
+	if d := rl.Allow("session:a"); d.Remaining != 49 {
+		t.Fatalf("pre-warmed remaining = %d, want 49", d.Remaining)
+	}
+
+	// Re-starting an existing session keeps its consumption.
+	rl.SessionStart("session:a", Policy{Capacity: 50})
+	if d := rl.Peek("session:a", ""); d.Remaining != 49 {
+		t.Fatalf("remaining after re-start = %d, want 49", d.Remaining)
+	}
+}
+
+func TestSessionEndDeletesBucket(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 5, RefillPerSec: 0.001})
+	rl.Trust("session:a", 1)
+	for i := 0; i < 3; i++ {
+		rl.Allow("session:a")
+	}
+	rl.Ban("session:a", time.Minute)
+
+	rl.SessionEnd("session:a")
+	if rl.store.Len() != 0 {
+		t.Fatalf("store len = %d after SessionEnd", rl.store.Len())
+	}
+	if rl.TrustLevel("session:a") != 0 {
+		t.Fatal("trust grant survived SessionEnd")
+	}
+	if !rl.Banned("session:a") {
+		t.Fatal("ban did not survive SessionEnd")
+	}
+}
@@ -0,0 +1,75 @@
+package signalfence
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ShadowStats compares the active policy's decisions with a shadow
+// candidate's would-be decisions for one route. The candidate never blocks
+// traffic; its outcomes are only recorded so block rates can be compared on
+// live traffic before promoting it.
+type ShadowStats struct {
+	ActiveAllowed uint64 `json:"active_allowed"`
+	ActiveBlocked uint64 `json:"active_blocked"`
+	ShadowAllowed uint64 `json:"shadow_allowed"`
+	ShadowBlocked uint64 `json:"shadow_blocked"`
+}
+
+type shadowCounters struct {
+	activeAllowed atomic.Uint64
+	activeBlocked atomic.Uint64
+	shadowAllowed atomic.Uint64
+	shadowBlocked atomic.Uint64
+}
+
+type shadowStats struct {
+	routes sync.Map // route string -> *shadowCounters
+}
+
+func (ss *shadowStats) record(route string, activeAllowed, shadowAllowed bool) {
+	v, ok := ss.routes.Load(route)
+	if !ok {
+		v, _ = ss.routes.LoadOrStore(route, &shadowCounters{})
+	}
+	c := v.(*shadowCounters)
+	if activeAllowed {
+		c.activeAllowed.Add(1)
+	} else {
+		c.activeBlocked.Add(1)
+	}
+	if shadowAllowed {
+		c.shadowAllowed.Add(1)
+	} else {
+		c.shadowBlocked.Add(1)
+	}
+}
+
+func (ss *shadowStats) snapshot(route string) ShadowStats {
+	v, ok := ss.routes.Load(route)
+	if !ok {
+		return ShadowStats{}
+	}
+	c := v.(*shadowCounters)
+	return ShadowStats{
+		ActiveAllowed: c.activeAllowed.Load(),
+		ActiveBlocked: c.activeBlocked.Load(),
+		ShadowAllowed: c.shadowAllowed.Load(),
+		ShadowBlocked: c.shadowBlocked.Load(),
+	}
+}
+
+// evalShadow runs the shadow candidate policy for bucketKey without
+// affecting the live decision.
+func (rl *RateLimiter) evalShadow(bucketKey string, pol Policy) bool {
+	b := rl.bucketFor(bucketKey+"#shadow", pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	ok, _, _ := b.Take(1, rl.now())
+	return ok
+}
+
+// ShadowStats returns the accumulated active-vs-candidate decision counts
+// for route.
+func (rl *RateLimiter) ShadowStats(route string) ShadowStats {
+	return rl.shadows.snapshot(route)
+}
@@ -0,0 +1,35 @@
+package signalfence
+
+import "testing"
+
+func TestShadowPolicyNeverBlocks(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 10,
+		Shadows: map[string]Policy{
+			"": {Capacity: 1, RefillPerSec: 0.001},
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		if d := rl.Allow("k"); !d.Allowed {
+			t.Fatalf("request %d blocked despite shadow-only candidate", i)
+		}
+	}
+
+	stats := rl.ShadowStats("")
+	if stats.ActiveAllowed != 5 || stats.ActiveBlocked != 0 {
+		t.Fatalf("active counts = %+v", stats)
+	}
+	if stats.ShadowAllowed != 1 || stats.ShadowBlocked != 4 {
+		t.Fatalf("shadow counts = %+v, want 1 allowed / 4 blocked under capacity 1", stats)
+	}
+}
+
+func TestShadowStatsEmptyWithoutCandidate(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 5, RefillPerSec: 1})
+	rl.Allow("k")
+	if got := rl.ShadowStats(""); got != (ShadowStats{}) {
+		t.Fatalf("stats = %+v, want zero", got)
+	}
+}
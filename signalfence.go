@@ -0,0 +1,827 @@
+// Package signalfence is a lightweight rate limiter for Go HTTP services
+// built on the token bucket algorithm. Each client key gets a bucket of
+// tokens; requests consume tokens and tokens refill over time. When a bucket
+// is empty the request is rejected with 429 Too Many Requests and a
+// Retry-After hint.
+package signalfence
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+	"github.com/yourusername/signalfence/store"
+)
+
+// Policy describes the token bucket parameters applied to a key.
+type Policy struct {
+	// Capacity is the maximum burst size (bucket capacity in tokens).
+	Capacity int `json:"capacity"`
+	// RefillPerSec is how many tokens are added per second.
+	RefillPerSec float64 `json:"refill_per_sec"`
+	// WarnThreshold is the fraction of capacity (0 < t <= 1) that, once
+	// consumed, marks allowed decisions with a warning so clients can back
+	// off before they start being blocked. Zero disables warnings.
+	WarnThreshold float64 `json:"warn_threshold,omitempty"`
+	// Grace is a small negative-token allowance: a client that slightly
+	// overshoots an empty bucket is admitted up to Grace tokens into debt,
+	// which is repaid from future refill. Zero keeps the hard cliff.
+	Grace int `json:"grace,omitempty"`
+	// Quota is an additional fixed-window cap evaluated alongside the
+	// token bucket — "100 req/s burstable, but at most 10000 per day".
+	// Zero means no quota; the window is QuotaWindow, defaulting to 24h.
+	// Each constraint reports its own ConstraintResult in the decision.
+	Quota int `json:"quota,omitempty"`
+	// QuotaWindow is the fixed window Quota is measured over.
+	QuotaWindow time.Duration `json:"quota_window,omitempty"`
+	// MaxConcurrent caps the key's in-flight requests through the
+	// middleware. Zero means unlimited. Programmatic Allow calls cannot
+	// observe request completion and ignore it.
+	MaxConcurrent int `json:"max_concurrent,omitempty"`
+	// Message replaces the generic denial body for this policy, e.g.
+	// "Login attempts limited". Empty keeps the default.
+	Message string `json:"message,omitempty"`
+	// DocsURL is included in denials as a Link rel="help" header and in
+	// the problem document, pointing clients at the limit's documentation.
+	DocsURL string `json:"docs_url,omitempty"`
+}
+
+// Config configures a RateLimiter.
+type Config struct {
+	// Capacity, RefillPerSec, WarnThreshold and Grace form the default
+	// policy applied to every route that has no explicit override.
+	Capacity      int
+	RefillPerSec  float64
+	WarnThreshold float64
+	Grace         int
+
+	// KeyFunc extracts the rate limit key from a request. When nil, the
+	// client IP taken from RemoteAddr is used.
+	KeyFunc func(*http.Request) string
+
+	// Routes maps a request path to a policy overriding the defaults.
+	Routes map[string]Policy
+
+	// KeyClasses maps a key-class prefix including its colon ("ip:",
+	// "bearer:") to a policy overriding the defaults for keys of that
+	// class, so anonymous IP traffic can run conservative limits while
+	// authenticated keys get generous ones without per-route duplication.
+	// A route override still wins; class fields only fill what it leaves
+	// zero. Keys without a class prefix use the plain defaults.
+	KeyClasses map[string]Policy
+
+	// ReadDefaults and WriteDefaults split the default policy by method:
+	// ReadDefaults covers GET and HEAD, WriteDefaults every other method,
+	// so read-heavy traffic is not limited by what the write paths can
+	// bear. Route overrides and key-class defaults still win; zero fields
+	// inherit from the plain defaults. Only the middleware and AllowMethod
+	// know the method — programmatic Allow calls use the plain defaults.
+	ReadDefaults  *Policy
+	WriteDefaults *Policy
+
+	// Canaries maps a route ("" for the default policy) to a percentage
+	// rollout of a candidate policy. Keys hashing into the canary cohort
+	// are enforced under the canary policy; the rest stay on the active
+	// one, and decisions are counted per cohort.
+	Canaries map[string]Canary
+
+	// Shadows maps a route ("" for the default policy) to a candidate
+	// policy evaluated in shadow mode: it never blocks, but its would-be
+	// decisions are recorded for comparison with the active policy.
+	Shadows map[string]Policy
+
+	// Store is the bucket storage backend. When nil an in-memory store is
+	// used.
+	Store store.Store
+
+	// MaxConfigHistory is how many previous config versions are kept for
+	// rollback after hot reloads. Zero means the default of 10.
+	MaxConfigHistory int
+
+	// Now overrides the limiter's time source for bucket accounting.
+	// Tests use it to drive deterministic virtual-time schedules; nil
+	// means time.Now.
+	Now func() time.Time
+
+	// TrapBanDuration is how long a key that touches a trap path (see
+	// AddTrap) stays blocklisted. Zero means DefaultTrapBanDuration.
+	TrapBanDuration time.Duration
+
+	// ProblemJSON makes the middleware answer denials with an RFC 9457
+	// application/problem+json document instead of a plain text body.
+	ProblemJSON bool
+
+	// BypassKeys holds HMAC secrets by key ID for verifying signed bypass
+	// tokens (see SignBypass). Multiple entries allow key rotation: add
+	// the new key, reissue tokens, then drop the old key.
+	BypassKeys map[string][]byte
+
+	// OnBypass, when set, is called whenever a valid bypass token is used,
+	// for audit logging.
+	OnBypass func(keyID, clientKey, route string)
+
+	// TrackTraffic enables per-key request and byte accounting in the
+	// middlewares, retrievable via Traffic and TrafficSnapshot. It is
+	// opt-in because it adds a counter lookup and response-size counting
+	// to every request.
+	TrackTraffic bool
+
+	// OnWarning, when set, is called for every allowed decision that
+	// crossed the policy's WarnThreshold. It runs synchronously on the
+	// request path, so it must be fast.
+	OnWarning func(key, route string, d Decision)
+
+	// PolicyFlag, when set, lets a feature-flag provider (LaunchDarkly, an
+	// OpenFeature client, a homegrown segment service) substitute a policy
+	// variation for a key and route, enabling gradual limit changes
+	// targeted at specific customer segments. It runs after the route and
+	// canary policies are resolved; returning false keeps the resolved
+	// policy, and zero fields of a returned variation inherit from it.
+	// Escalations, clampdown and warm-up still apply on top. The hook runs
+	// synchronously on the request path, so it should answer from the
+	// provider's local flag cache.
+	PolicyFlag func(key, route string, pol Policy) (Policy, bool)
+
+	// MaxKeyLength caps the length of extracted keys. Keys over the cap
+	// are replaced with a fixed-width hash and control characters are
+	// always stripped, so a client sending a 100KB Authorization header
+	// cannot plant a 100KB map key in the store or garbage in log lines.
+	// Zero means DefaultMaxKeyLength; negative disables sanitization for
+	// extractors that already guarantee well-formed keys.
+	MaxKeyLength int
+
+	// RawRoutes disables automatic path templating in the middlewares.
+	// By default "/api/users/42" and "/api/users/7f3c…" both resolve to
+	// the route "/api/users/{id}" (see TemplateRoute), keeping route
+	// cardinality bounded by the API's shape rather than its traffic.
+	RawRoutes bool
+
+	// MaxTrackedRoutes caps how many distinct routes the middlewares
+	// track; paths beyond the cap fold into RouteOverflow. Zero means
+	// DefaultMaxTrackedRoutes, negative disables the cap.
+	MaxTrackedRoutes int
+
+	// DeniedMessageFunc, when set, produces the denial body for a blocked
+	// request — typically localizing it from Accept-Language. Returning
+	// "" falls back to the policy's Message and then the generic body. It
+	// takes precedence over Policy.Message but not over the response
+	// format: under ProblemJSON the text lands in the problem Detail.
+	DeniedMessageFunc func(r *http.Request, d *Decision) string
+
+	// NoDenialCacheHeaders suppresses the Cache-Control: no-store header
+	// stamped on every denial. The default exists because CDNs have
+	// cached 429s and served them to innocent clients; only opt out when
+	// an edge layer manages caching of denials itself.
+	NoDenialCacheHeaders bool
+
+	// DenialVary lists extra header names added to Vary on denials, for
+	// deployments keying on a request header a cache must not fold
+	// across. Accept-Language is added automatically when
+	// DeniedMessageFunc is set.
+	DenialVary []string
+
+	// EdgeAbsorbDenials makes denials carry a Surrogate-Control header
+	// with a max-age equal to the Retry-After, telling a CDN to serve the
+	// 429 from the edge until the client may retry. Browsers and shared
+	// caches still see no-store; surrogates honoring Surrogate-Control
+	// strip it before forwarding. Opt-in, because it is only correct when
+	// the CDN's cache key includes the client identity the limiter keys
+	// on.
+	EdgeAbsorbDenials bool
+
+	// ScoreFunc replaces the built-in AbuseScore scoring. It receives the
+	// gathered signals and returns a score, clamped to [0, 1] by the
+	// caller.
+	ScoreFunc func(key string, sig AbuseSignals) float64
+
+	// AbuseSignals registers named extra signal sources — error-ratio
+	// trackers, anomaly detectors — sampled into AbuseSignals.Extra on
+	// every AbuseScore call. Values should be on a 0-to-1 scale to mix
+	// well with the built-in scoring.
+	AbuseSignals map[string]func(key string) float64
+
+	// ChallengeHandler, when set, answers over-limit requests instead of
+	// the 429 denial — typically serving a CAPTCHA or proof-of-work page.
+	// Banned keys still get the plain denial. The verification endpoint
+	// calls ChallengePassed to restore the solver's limits.
+	ChallengeHandler http.Handler
+
+	// Metrics, when set, receives the limiter's instrumentation events —
+	// per-route allow/deny counters, middleware latency, store size. See
+	// MetricsSink for the built-in implementations. It is fixed at
+	// construction; Reload does not swap it.
+	Metrics MetricsSink
+
+	// BucketSampleEvery is the sampling interval for watched buckets (see
+	// WatchBucket); BucketSampleHistory is how many samples each watched
+	// key's ring keeps. Zero means DefaultBucketSampleEvery and
+	// DefaultBucketSampleHistory.
+	BucketSampleEvery   time.Duration
+	BucketSampleHistory int
+
+	// Violations tunes how externally reported violations (see
+	// ReportViolation) decay, escalate and ban. The zero value uses the
+	// documented defaults.
+	Violations ViolationConfig
+
+	// Trace samples decisions into an in-memory ring for debugging (see
+	// TraceConfig and Traces). The zero value disables sampling.
+	Trace TraceConfig
+
+	// TrustLevels maps trust levels (see Trust) to policy overrides for
+	// keys granted them — e.g. level 1 after login, level 2 after MFA,
+	// each with progressively generous limits. Zero fields inherit from
+	// the policy being overridden.
+	TrustLevels map[int]Policy
+}
+
+// Decision describes the outcome of a rate limit check.
+type Decision struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+	// Limit is the bucket capacity the check was made against.
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long the client should wait before retrying. It is
+	// zero when the request was allowed.
+	RetryAfter time.Duration
+	// ConfigVersion is the config version the decision was made under.
+	// Versions start at 1 and increase on every reload or rollback.
+	ConfigVersion int
+	// Cohort is CohortCanary or CohortStable when a canary rollout is
+	// configured for the route, and empty otherwise.
+	Cohort string
+	// Warning is true for allowed decisions that crossed the policy's
+	// WarnThreshold, signalling the client is close to being limited.
+	Warning bool
+	// Banned is true when the denial came from the blocklist rather than
+	// an empty bucket; RetryAfter then holds the remaining ban time.
+	Banned bool
+	// Mode is the decision mode the limiter was in (see Mode).
+	Mode Mode
+	// WouldBlock is true when ModeObserve suppressed a denial: the request
+	// was let through but would have been blocked under ModeEnforce.
+	WouldBlock bool
+	// Constraints holds the per-constraint sub-results when the policy
+	// composes several (quota, concurrency) next to the token bucket. It
+	// is nil for plain single-constraint policies.
+	Constraints []ConstraintResult
+}
+
+// RateLimiter applies token bucket rate limiting keyed by client identity.
+type RateLimiter struct {
+	mu      sync.RWMutex
+	cfg     Config
+	keyFunc func(*http.Request) string
+	store   store.Store
+	now     func() time.Time
+	metrics MetricsSink
+
+	// version counts config generations; history holds previous configs
+	// (oldest first) for rollback, capped at maxHistory entries.
+	version    int
+	history    []Config
+	maxHistory int
+
+	// bgCtx governs background tasks (cleanup); Close cancels it.
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
+
+	// routeSet holds the distinct routes seen so far, capped at the
+	// configured maximum; routeCount is its size.
+	routeSet   sync.Map // route string -> struct{}
+	routeCount atomic.Int64
+
+	cohorts    canaryStats
+	shadows    shadowStats
+	traffic    trafficStats
+	perRoute   routeTrafficStats
+	waits      sync.Map // key string -> *waitQueue
+	trusted    sync.Map // key string -> int (trust level)
+	sessions   sync.Map // key string -> Policy (session override)
+	quotas     quotaCounters
+	slots      slotCounters
+	workers    sync.Map // name string -> *workerState
+	notifiers  banNotifiers
+	violations violationCounters
+	sampler    bucketSampler
+	traces     traceBuffer
+	escalated  escalations
+	traps      trapList
+	blocked    blockSet
+	mode       atomic.Int32
+	clamp      clampdown
+	chaos      chaosState
+	warm       warmup
+}
+
+// NewRateLimiter returns a limiter for the given config. Zero-value fields
+// are filled with defaults: capacity 20, refill 10 tokens/sec, client IP
+// keys, and an in-memory store.
+func NewRateLimiter(cfg Config) *RateLimiter {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = 20
+	}
+	if cfg.RefillPerSec <= 0 {
+		cfg.RefillPerSec = 10
+	}
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = ClientIP
+	}
+	if cfg.MaxKeyLength >= 0 {
+		maxKey := cfg.MaxKeyLength
+		if maxKey == 0 {
+			maxKey = DefaultMaxKeyLength
+		}
+		inner := keyFunc
+		keyFunc = func(r *http.Request) string {
+			return SanitizeKey(inner(r), maxKey)
+		}
+	}
+	st := cfg.Store
+	if st == nil {
+		st = store.NewMemoryStore()
+	}
+	maxHistory := cfg.MaxConfigHistory
+	if maxHistory <= 0 {
+		maxHistory = 10
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	rl := &RateLimiter{
+		cfg:        cfg,
+		keyFunc:    keyFunc,
+		store:      st,
+		now:        now,
+		metrics:    cfg.Metrics,
+		version:    1,
+		maxHistory: maxHistory,
+		bgCtx:      bgCtx,
+		bgCancel:   bgCancel,
+	}
+	if rl.metrics != nil {
+		rl.startMetricsGauge()
+	}
+	return rl
+}
+
+// Reload hot-swaps the limiter's policies: the default Capacity and
+// RefillPerSec plus the Routes table are replaced from cfg, while the
+// extractor and store are kept. The previous config is retained for
+// Rollback and the config version is bumped. Existing buckets pick up the
+// new limits on their next request.
+func (rl *RateLimiter) Reload(cfg Config) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = rl.cfg.Capacity
+	}
+	if cfg.RefillPerSec <= 0 {
+		cfg.RefillPerSec = rl.cfg.RefillPerSec
+	}
+	rl.pushHistory()
+	rl.cfg.Capacity = cfg.Capacity
+	rl.cfg.RefillPerSec = cfg.RefillPerSec
+	rl.cfg.WarnThreshold = cfg.WarnThreshold
+	rl.cfg.Grace = cfg.Grace
+	rl.cfg.Routes = cfg.Routes
+	rl.cfg.KeyClasses = cfg.KeyClasses
+	rl.cfg.ReadDefaults = cfg.ReadDefaults
+	rl.cfg.WriteDefaults = cfg.WriteDefaults
+	rl.cfg.Canaries = cfg.Canaries
+	rl.cfg.Shadows = cfg.Shadows
+	rl.version++
+}
+
+// Rollback restores the most recently replaced config version. It reports
+// whether a previous version was available.
+func (rl *RateLimiter) Rollback() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if len(rl.history) == 0 {
+		return false
+	}
+	prev := rl.history[len(rl.history)-1]
+	rl.history = rl.history[:len(rl.history)-1]
+	rl.cfg.Capacity = prev.Capacity
+	rl.cfg.RefillPerSec = prev.RefillPerSec
+	rl.cfg.WarnThreshold = prev.WarnThreshold
+	rl.cfg.Grace = prev.Grace
+	rl.cfg.Routes = prev.Routes
+	rl.cfg.KeyClasses = prev.KeyClasses
+	rl.cfg.ReadDefaults = prev.ReadDefaults
+	rl.cfg.WriteDefaults = prev.WriteDefaults
+	rl.cfg.Canaries = prev.Canaries
+	rl.cfg.Shadows = prev.Shadows
+	rl.version++
+	return true
+}
+
+// ConfigVersion returns the current config version.
+func (rl *RateLimiter) ConfigVersion() int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.version
+}
+
+// pushHistory records the current config for rollback. Callers must hold mu.
+func (rl *RateLimiter) pushHistory() {
+	rl.history = append(rl.history, rl.cfg)
+	if len(rl.history) > rl.maxHistory {
+		rl.history = rl.history[1:]
+	}
+}
+
+// ClientIP is the default key extractor: the host portion of RemoteAddr,
+// without a key-class prefix. Prefer ExtractIP when mixing extractors, so
+// IP keys are namespaced away from other key classes.
+func ClientIP(r *http.Request) string {
+	return hostOnly(r.RemoteAddr)
+}
+
+// EffectivePolicy returns the policy enforced for the given route after
+// merging defaults with any per-route override.
+func (rl *RateLimiter) EffectivePolicy(route string) Policy {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	def := Policy{
+		Capacity:      rl.cfg.Capacity,
+		RefillPerSec:  rl.cfg.RefillPerSec,
+		WarnThreshold: rl.cfg.WarnThreshold,
+		Grace:         rl.cfg.Grace,
+	}
+	if p, ok := rl.cfg.Routes[route]; ok {
+		return mergePolicy(p, def)
+	}
+	return def
+}
+
+// Allow checks and consumes one token for key against the default policy.
+func (rl *RateLimiter) Allow(key string) Decision {
+	return rl.AllowRoute(key, "")
+}
+
+// AllowRoute checks and consumes one token for key against the policy in
+// effect for route.
+func (rl *RateLimiter) AllowRoute(key, route string) Decision {
+	return rl.allowRoute(key, "", route, 1, false)
+}
+
+// AllowMethod is AllowRoute with the request method in hand, so the
+// ReadDefaults/WriteDefaults split applies. An empty method behaves like
+// AllowRoute. The middleware uses it; callers outside HTTP handling
+// usually have no meaningful method to pass.
+func (rl *RateLimiter) AllowMethod(key, method, route string) Decision {
+	return rl.allowRoute(key, method, route, 1, false)
+}
+
+// AllowCost is AllowRoute for operations that should count as more than one
+// unit: it consumes cost tokens in a single atomic take, so an expensive
+// request either fits entirely or is rejected.
+func (rl *RateLimiter) AllowCost(key, route string, cost float64) Decision {
+	return rl.allowRoute(key, "", route, cost, false)
+}
+
+// Peek reports the decision key would receive on route without consuming
+// any tokens. Canary and shadow statistics and the OnWarning hook are not
+// touched, so peeking is free of side effects beyond refreshing the
+// bucket's last-access time.
+func (rl *RateLimiter) Peek(key, route string) Decision {
+	return rl.allowRoute(key, "", route, 1, true)
+}
+
+func (rl *RateLimiter) allowRoute(key, method, route string, cost float64, peek bool) Decision {
+	if !peek && rl.tracingEnabled() {
+		sampled := rl.traceSampled()
+		start := time.Now()
+		d := rl.injectChaos(rl.decideRoute(key, method, route, cost, peek), peek)
+		rl.recordTrace(key, route, d, time.Since(start), sampled)
+		rl.countDecision(route, d)
+		return d
+	}
+	d := rl.injectChaos(rl.decideRoute(key, method, route, cost, peek), peek)
+	if !peek {
+		rl.countDecision(route, d)
+	}
+	return d
+}
+
+func (rl *RateLimiter) decideRoute(key, method, route string, cost float64, peek bool) Decision {
+	pol := rl.effectivePolicyForKey(key, method, route)
+	version := rl.ConfigVersion()
+	mode := rl.Mode()
+
+	if mode == ModeBypass {
+		return Decision{
+			Allowed:       true,
+			Limit:         pol.Capacity,
+			Remaining:     pol.Capacity,
+			ConfigVersion: version,
+			Mode:          mode,
+		}
+	}
+
+	if left, banned := rl.banRemaining(key); banned {
+		return observe(mode, Decision{
+			Limit:         pol.Capacity,
+			RetryAfter:    left,
+			ConfigVersion: version,
+			Banned:        true,
+			Mode:          mode,
+		})
+	}
+
+	rl.mu.RLock()
+	_, overridden := rl.cfg.Routes[route]
+	can, hasCanary := rl.cfg.Canaries[route]
+	shadow, hasShadow := rl.cfg.Shadows[route]
+	flag := rl.cfg.PolicyFlag
+	rl.mu.RUnlock()
+
+	// Routes with their own policy get their own buckets so an override
+	// cannot be starved by traffic on default routes; the canary cohort
+	// likewise gets separate buckets under the canary policy.
+	bucketKey := key
+	if overridden {
+		bucketKey = key + "|" + route
+	}
+	// Reads and writes get separate buckets when the method defaults are
+	// split, so a GET and a POST from the same key cannot flap one
+	// bucket's limits back and forth.
+	if _, hasMethod := rl.methodDefault(method); hasMethod {
+		if isReadMethod(method) {
+			bucketKey += "|read"
+		} else {
+			bucketKey += "|write"
+		}
+	}
+	cohort := ""
+	if hasCanary {
+		cohort = CohortStable
+		if inCanary(key, can.Percent) {
+			cohort = CohortCanary
+			pol = mergePolicy(can.Policy, pol)
+			bucketKey += "#canary"
+		}
+	}
+	// A feature-flag variation replaces whatever the route and canary
+	// resolved, so segments can be moved to new limits gradually.
+	if flag != nil {
+		if varPol, flagged := flag(key, route, pol); flagged {
+			pol = mergePolicy(varPol, pol)
+		}
+	}
+	// A session policy bound by SessionStart replaces the route's
+	// resolution for the key.
+	if sesPol, ok := rl.sessionPolicy(key); ok {
+		pol = mergePolicy(sesPol, pol)
+	}
+	// A trust grant relaxes the resolved policy for the key; escalations
+	// below still trump it, so trust never shields an abusive client.
+	if trustPol, ok := rl.trustPolicy(key); ok {
+		pol = mergePolicy(trustPol, pol)
+	}
+	// An active escalation trumps whatever policy the route or canary
+	// selected for this key.
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	// An emergency clampdown and the startup warm-up ramp both scale
+	// whatever policy won.
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+
+	b := rl.bucketFor(bucketKey, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	ok, remaining, retryAfter := rl.take(b, pol, cost, peek)
+	if remaining < 0 {
+		remaining = 0 // in debt via the grace allowance
+	}
+	// A quota constraint is evaluated next to the bucket: both must admit
+	// the request, and a quota denial refunds the rate tokens so waiting
+	// out the Retry-After does not find the bucket drained too.
+	var constraints []ConstraintResult
+	if pol.Quota > 0 {
+		rateOK, rateRetry := ok, retryAfter
+		cr := rl.quotas.take(bucketKey, pol.Quota, pol.QuotaWindow, cost, rl.now(), peek || !rateOK)
+		if !cr.Allowed && rateOK {
+			if !peek {
+				b.ForceTake(-cost, rl.now())
+			}
+			remaining += cost
+			if remaining > float64(pol.Capacity) {
+				remaining = float64(pol.Capacity)
+			}
+			ok = false
+			retryAfter = cr.RetryAfter
+		}
+		constraints = []ConstraintResult{
+			{Name: ConstraintRate, Allowed: rateOK, Limit: pol.Capacity, Remaining: int(remaining), RetryAfter: rateRetry},
+			cr,
+		}
+	}
+	if !peek {
+		if hasCanary {
+			rl.cohorts.record(route, cohort, ok)
+		}
+		if hasShadow {
+			shadowOK := rl.evalShadow(bucketKey, mergePolicy(shadow, pol))
+			rl.shadows.record(route, ok, shadowOK)
+		}
+	}
+	// Warnings compare whole tokens so that fractional refill between
+	// requests cannot flap the threshold.
+	warning := false
+	if ok && pol.WarnThreshold > 0 {
+		warnRemaining := int((1-pol.WarnThreshold)*float64(pol.Capacity) + 1e-9)
+		warning = int(remaining) <= warnRemaining
+	}
+	d := observe(mode, Decision{
+		Allowed:       ok,
+		Limit:         pol.Capacity,
+		Remaining:     int(remaining),
+		RetryAfter:    retryAfter,
+		ConfigVersion: version,
+		Cohort:        cohort,
+		Warning:       warning,
+		Mode:          mode,
+		Constraints:   constraints,
+	})
+	if warning && !peek && rl.cfg.OnWarning != nil {
+		rl.cfg.OnWarning(key, route, d)
+	}
+	return d
+}
+
+// take consumes cost tokens from b, or — when peeking — reports whether the
+// take would succeed without consuming anything.
+func (rl *RateLimiter) take(b core.TokenBucket, pol Policy, cost float64, peek bool) (ok bool, remaining float64, retryAfter time.Duration) {
+	if !peek {
+		return b.Take(cost, rl.now())
+	}
+	remaining = b.Peek(rl.now())
+	ok = remaining-cost >= -float64(pol.Grace)
+	if !ok && pol.RefillPerSec > 0 {
+		retryAfter = time.Duration((cost - remaining) / pol.RefillPerSec * float64(time.Second))
+	}
+	return ok, remaining, retryAfter
+}
+
+// AllowPolicy checks and consumes one token for key under an explicit
+// policy, with zero fields filled from the default policy. The decision
+// service uses it for per-request overrides; overridden keys get their own
+// buckets so an override cannot distort a key's normally limited traffic.
+// Bans, escalations, clampdown, warm-up and the decision mode all apply.
+func (rl *RateLimiter) AllowPolicy(key string, pol Policy) Decision {
+	return rl.allowPolicy(key, pol, 1, false)
+}
+
+// AllowPolicyCost is AllowPolicy consuming cost tokens in one atomic take.
+func (rl *RateLimiter) AllowPolicyCost(key string, pol Policy, cost float64) Decision {
+	return rl.allowPolicy(key, pol, cost, false)
+}
+
+// PeekPolicy is AllowPolicy without consuming any tokens.
+func (rl *RateLimiter) PeekPolicy(key string, pol Policy) Decision {
+	return rl.allowPolicy(key, pol, 1, true)
+}
+
+func (rl *RateLimiter) allowPolicy(key string, pol Policy, cost float64, peek bool) Decision {
+	d := rl.injectChaos(rl.decidePolicy(key, pol, cost, peek), peek)
+	if !peek {
+		rl.countDecision("", d)
+	}
+	return d
+}
+
+func (rl *RateLimiter) decidePolicy(key string, pol Policy, cost float64, peek bool) Decision {
+	version := rl.ConfigVersion()
+	mode := rl.Mode()
+	pol = mergePolicy(pol, rl.effectivePolicyForKey(key, "", ""))
+
+	if mode == ModeBypass {
+		return Decision{
+			Allowed:       true,
+			Limit:         pol.Capacity,
+			Remaining:     pol.Capacity,
+			ConfigVersion: version,
+			Mode:          mode,
+		}
+	}
+	if left, banned := rl.banRemaining(key); banned {
+		return observe(mode, Decision{
+			Limit:         pol.Capacity,
+			RetryAfter:    left,
+			ConfigVersion: version,
+			Banned:        true,
+			Mode:          mode,
+		})
+	}
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+
+	b := rl.bucketFor(key+"|override", pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	ok, remaining, retryAfter := rl.take(b, pol, cost, peek)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return observe(mode, Decision{
+		Allowed:       ok,
+		Limit:         pol.Capacity,
+		Remaining:     int(remaining),
+		RetryAfter:    retryAfter,
+		ConfigVersion: version,
+		Mode:          mode,
+	})
+}
+
+// observe suppresses a denial under ModeObserve, marking it instead.
+func observe(mode Mode, d Decision) Decision {
+	if mode == ModeObserve && !d.Allowed {
+		d.Allowed = true
+		d.WouldBlock = true
+	}
+	return d
+}
+
+// AllowFast is the allocation-free fast path of Allow: it checks and
+// consumes one token for key against the default policy and reports only
+// admission. Bans, escalations, clampdown, warm-up and the decision mode
+// are all honored, but canary and shadow accounting, warnings and the
+// OnWarning hook are skipped — no Decision is built at all. Use it on hot
+// paths where GC pressure from high QPS matters more than the bookkeeping.
+func (rl *RateLimiter) AllowFast(key string) bool {
+	mode := rl.Mode()
+	if mode == ModeBypass {
+		return true
+	}
+	if _, banned := rl.banRemaining(key); banned {
+		return mode == ModeObserve
+	}
+	pol := rl.EffectivePolicy("")
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+	b := rl.bucketFor(key, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	ok, _, _ := b.Take(1, rl.now())
+	return ok || mode == ModeObserve
+}
+
+// mergePolicy fills zero fields of p from fallback.
+func mergePolicy(p, fallback Policy) Policy {
+	if p.Capacity <= 0 {
+		p.Capacity = fallback.Capacity
+	}
+	if p.RefillPerSec <= 0 {
+		p.RefillPerSec = fallback.RefillPerSec
+	}
+	if p.WarnThreshold <= 0 {
+		p.WarnThreshold = fallback.WarnThreshold
+	}
+	if p.Grace <= 0 {
+		p.Grace = fallback.Grace
+	}
+	if p.Quota <= 0 {
+		p.Quota = fallback.Quota
+	}
+	if p.QuotaWindow <= 0 {
+		p.QuotaWindow = fallback.QuotaWindow
+	}
+	if p.MaxConcurrent <= 0 {
+		p.MaxConcurrent = fallback.MaxConcurrent
+	}
+	if p.Message == "" {
+		p.Message = fallback.Message
+	}
+	if p.DocsURL == "" {
+		p.DocsURL = fallback.DocsURL
+	}
+	return p
+}
+
+// bucketFor returns the bucket stored under bucketKey, creating it under
+// the given policy if absent. The Load fast path keeps the newBucket
+// closure (and its allocation) off the steady-state request path.
+func (rl *RateLimiter) bucketFor(bucketKey string, pol Policy) core.TokenBucket {
+	if b, ok := rl.store.Load(bucketKey); ok {
+		return b
+	}
+	return rl.store.Get(bucketKey, func() core.TokenBucket {
+		return core.NewBucketAt(float64(pol.Capacity), pol.RefillPerSec, rl.now())
+	})
+}
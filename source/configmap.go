@@ -0,0 +1,66 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// defaultConfigMapPoll is how often ConfigMap re-reads the mounted file
+// when the caller does not set an interval. The kubelet itself syncs
+// mounted ConfigMaps on a cadence of roughly a minute, so polling faster
+// buys little.
+const defaultConfigMapPoll = 2 * time.Second
+
+// ConfigMap streams policy documents from a Kubernetes ConfigMap mounted
+// into the pod as a file. Kubernetes swaps mounted ConfigMap contents
+// atomically via symlinks when the object changes, so watching the file is
+// the dependency-free equivalent of an informer for the pod's own view —
+// no client-go, no API-server credentials. The content hash, not the
+// modification time, detects changes, because the symlink swap does not
+// reliably update mtime.
+type ConfigMap struct {
+	// Path is the mounted policy file, e.g.
+	// "/etc/signalfence/policies.json".
+	Path string
+	// PollEvery is the re-read interval; defaults to two seconds.
+	PollEvery time.Duration
+
+	seen bool
+	hash [sha256.Size]byte
+}
+
+// Next blocks until the mounted file's contents change (the first call
+// returns the current contents immediately) and returns the parsed policy
+// snapshot.
+func (s *ConfigMap) Next(ctx context.Context) (signalfence.Config, error) {
+	poll := s.PollEvery
+	if poll <= 0 {
+		poll = defaultConfigMapPoll
+	}
+	for {
+		data, err := os.ReadFile(s.Path)
+		if err != nil {
+			// Reads can fail transiently mid symlink swap; the follower's
+			// backoff absorbs that.
+			return signalfence.Config{}, err
+		}
+		if sum := sha256.Sum256(data); !s.seen || sum != s.hash {
+			fc, err := signalfence.ParseConfig(data)
+			if err != nil {
+				return signalfence.Config{}, fmt.Errorf("configmap %s: %w", s.Path, err)
+			}
+			s.seen, s.hash = true, sum
+			return fc.Config(), nil
+		}
+		select {
+		case <-time.After(poll):
+		case <-ctx.Done():
+			return signalfence.Config{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package source provides PolicySource implementations for common control
+// planes, so policy changes propagate to every instance within seconds
+// without a redeploy. Each source delivers the same JSON document that
+// signalfence.LoadConfigFile reads from disk.
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// defaultConsulWait is the blocking-query wait sent to Consul when the
+// caller does not set one. Consul adds jitter and may return earlier.
+const defaultConsulWait = 5 * time.Minute
+
+// Consul streams policy documents from a Consul KV key using blocking
+// queries, so updates arrive as soon as the key changes rather than on a
+// poll interval. The zero value is not usable; Address and Key are
+// required.
+type Consul struct {
+	// Address is the Consul agent base URL, e.g. "http://127.0.0.1:8500".
+	Address string
+	// Key is the KV path holding the policy JSON.
+	Key string
+	// Token is an optional ACL token sent as X-Consul-Token.
+	Token string
+	// Wait bounds each blocking query; defaults to five minutes.
+	Wait time.Duration
+	// HTTPClient overrides http.DefaultClient. Its timeout must exceed Wait
+	// or every blocking query will be cut short.
+	HTTPClient *http.Client
+
+	index uint64 // last seen X-Consul-Index
+}
+
+// Next blocks until the KV key's value changes (the first call returns the
+// current value immediately) and returns the parsed policy snapshot.
+func (c *Consul) Next(ctx context.Context) (signalfence.Config, error) {
+	for {
+		data, index, err := c.query(ctx)
+		if err != nil {
+			return signalfence.Config{}, err
+		}
+		if index == c.index && c.index != 0 {
+			// Wait timed out with no change; ask again.
+			continue
+		}
+		c.index = index
+		fc, err := signalfence.ParseConfig(data)
+		if err != nil {
+			return signalfence.Config{}, fmt.Errorf("consul key %s: %w", c.Key, err)
+		}
+		return fc.Config(), nil
+	}
+}
+
+// query performs one blocking read of the KV key, returning the decoded
+// value and the consistency index to resume from.
+func (c *Consul) query(ctx context.Context) ([]byte, uint64, error) {
+	wait := c.Wait
+	if wait <= 0 {
+		wait = defaultConsulWait
+	}
+	u := c.Address + "/v1/kv/" + c.Key + "?" + url.Values{
+		"index": {strconv.FormatUint(c.index, 10)},
+		"wait":  {wait.String()},
+	}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul key %s: status %d", c.Key, resp.StatusCode)
+	}
+
+	var entries []struct {
+		Value []byte `json:"Value"` // base64 in the wire form
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("consul key %s: %w", c.Key, err)
+	}
+	if len(entries) == 0 {
+		return nil, 0, fmt.Errorf("consul key %s: not found", c.Key)
+	}
+	index, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul key %s: bad index header: %w", c.Key, err)
+	}
+	return entries[0].Value, index, nil
+}
@@ -0,0 +1,127 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func kvBody(t *testing.T, doc string) []byte {
+	t.Helper()
+	body, err := json.Marshal([]struct {
+		Value []byte `json:"Value"`
+	}{{Value: []byte(doc)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestConsulBlockingQueries(t *testing.T) {
+	docV1 := `{"default":{"capacity":5,"refill_per_sec":2}}`
+	docV2 := `{"default":{"capacity":9,"refill_per_sec":3}}`
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v1/kv/signalfence/policies" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch index := r.URL.Query().Get("index"); {
+		case index == "0":
+			w.Header().Set("X-Consul-Index", "10")
+			w.Write(kvBody(t, docV1))
+		case calls == 2:
+			// Blocking query timed out with no change: same index, same doc.
+			w.Header().Set("X-Consul-Index", "10")
+			w.Write(kvBody(t, docV1))
+		default:
+			w.Header().Set("X-Consul-Index", "11")
+			w.Write(kvBody(t, docV2))
+		}
+	}))
+	defer srv.Close()
+
+	src := &Consul{Address: srv.URL, Key: "signalfence/policies", Wait: time.Second}
+	cfg, err := src.Next(context.Background())
+	if err != nil {
+		t.Fatalf("first Next = %v", err)
+	}
+	if cfg.Capacity != 5 || cfg.RefillPerSec != 2 {
+		t.Fatalf("first snapshot = %+v", cfg)
+	}
+
+	// The unchanged response is skipped; Next returns only on the change.
+	cfg, err = src.Next(context.Background())
+	if err != nil {
+		t.Fatalf("second Next = %v", err)
+	}
+	if cfg.Capacity != 9 || cfg.RefillPerSec != 3 {
+		t.Fatalf("second snapshot = %+v", cfg)
+	}
+	if calls != 3 {
+		t.Fatalf("server saw %d queries, want 3", calls)
+	}
+}
+
+func TestConsulErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	src := &Consul{Address: srv.URL, Key: "missing"}
+	if _, err := src.Next(context.Background()); err == nil {
+		t.Fatal("missing key did not error")
+	}
+}
+
+func TestConfigMapWatchesMountedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	write := func(doc string) {
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"default":{"capacity":5,"refill_per_sec":2}}`)
+
+	src := &ConfigMap{Path: path, PollEvery: 5 * time.Millisecond}
+	cfg, err := src.Next(context.Background())
+	if err != nil {
+		t.Fatalf("first Next = %v", err)
+	}
+	if cfg.Capacity != 5 {
+		t.Fatalf("first snapshot = %+v", cfg)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		cfg2, err := src.Next(context.Background())
+		if err == nil && cfg2.Capacity != 9 {
+			t.Errorf("changed snapshot = %+v", cfg2)
+		}
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	write(`{"default":{"capacity":9,"refill_per_sec":2}}`)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Next after change = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Next did not observe the file change")
+	}
+
+	// With no further change, Next blocks until the context ends.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := src.Next(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Next on unchanged file = %v, want deadline exceeded", err)
+	}
+}
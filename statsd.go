@@ -0,0 +1,154 @@
+package signalfence
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsDSink emits limiter metrics as StatsD datagrams with Datadog-style
+// tags, for fleets whose agents cannot scrape a Prometheus endpoint.
+// Decisions become counters (signalfence.allowed, signalfence.denied) and a
+// timing (signalfence.latency), all tagged by route; keys are deliberately
+// never a tag because their cardinality would melt the agent. Emission is
+// fire-and-forget UDP: a down agent costs nothing but the syscall.
+type StatsDSink struct {
+	conn   net.Conn
+	prefix string
+	tags   string // pre-rendered "|#k:v,..." suffix, "" when untagged
+}
+
+// NewStatsDSink connects to a StatsD agent at addr (e.g. "127.0.0.1:8125").
+// Prefix is prepended to every metric name with a trailing dot; empty means
+// "signalfence". Tags are added to every datagram in Datadog format.
+func NewStatsDSink(addr, prefix string, tags map[string]string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		prefix = "signalfence"
+	}
+	s := &StatsDSink{conn: conn, prefix: prefix + "."}
+	if len(tags) > 0 {
+		pairs := make([]string, 0, len(tags))
+		for k, v := range tags {
+			pairs = append(pairs, k+":"+v)
+		}
+		s.tags = "|#" + strings.Join(pairs, ",")
+	}
+	return s, nil
+}
+
+// Close releases the agent connection.
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+// emit sends one datagram; extraTags are appended to the static tags.
+func (s *StatsDSink) emit(name, value, kind string, extraTags ...string) {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if s.tags != "" {
+		b.WriteString(s.tags)
+		for _, t := range extraTags {
+			b.WriteByte(',')
+			b.WriteString(t)
+		}
+	} else if len(extraTags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(extraTags, ","))
+	}
+	s.conn.Write([]byte(b.String()))
+}
+
+// Count adds n to a counter.
+func (s *StatsDSink) Count(name string, n int64, tags ...string) {
+	s.emit(name, strconv.FormatInt(n, 10), "c", tags...)
+}
+
+// Timing records a duration in milliseconds.
+func (s *StatsDSink) Timing(name string, d time.Duration, tags ...string) {
+	s.emit(name, strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64), "ms", tags...)
+}
+
+// Gauge sets a gauge.
+func (s *StatsDSink) Gauge(name string, v float64, tags ...string) {
+	s.emit(name, strconv.FormatFloat(v, 'f', -1, 64), "g", tags...)
+}
+
+// MetricsSink implementation, so the sink can be handed to Config.Metrics
+// and the limiter instruments itself.
+
+func (s *StatsDSink) IncAllowed(route string) { s.Count("allowed", 1, "route:"+route) }
+
+func (s *StatsDSink) IncDenied(route string) { s.Count("denied", 1, "route:"+route) }
+
+func (s *StatsDSink) ObserveLatency(route string, d time.Duration) {
+	s.Timing("latency", d, "route:"+route)
+}
+
+func (s *StatsDSink) SetBuckets(n int) { s.Gauge("keys", float64(n)) }
+
+// Log records one decision, mirroring AccessLogger.Log so the two observers
+// share call sites. The key is accepted for symmetry but never emitted.
+func (s *StatsDSink) Log(_, route string, d Decision, latency time.Duration) {
+	routeTag := "route:" + route
+	if d.Allowed {
+		s.Count("allowed", 1, routeTag)
+	} else {
+		s.Count("denied", 1, routeTag)
+	}
+	if d.Banned {
+		s.Count("banned", 1, routeTag)
+	}
+	s.Timing("latency", latency, routeTag)
+}
+
+// Middleware counts one decision per HTTP request from the response status.
+// Wrap it outside RateLimiter.Middleware:
+//
+//	sink.Middleware(limiter.Middleware(handler))
+func (s *StatsDSink) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		routeTag := "route:" + r.URL.Path
+		if rec.status == http.StatusTooManyRequests {
+			s.Count("denied", 1, routeTag)
+		} else {
+			s.Count("allowed", 1, routeTag)
+		}
+		s.Timing("latency", time.Since(start), routeTag)
+	})
+}
+
+// StartGauges emits the store size as a signalfence.keys gauge on the given
+// interval under rl's worker supervision (see Health, worker
+// "statsd-gauges") until the limiter is closed.
+func (s *StatsDSink) StartGauges(rl *RateLimiter, every time.Duration) {
+	if every <= 0 {
+		every = 10 * time.Second
+	}
+	rl.Go("statsd-gauges", func(ctx context.Context) {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Gauge("keys", float64(rl.store.Len()))
+			}
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package signalfence
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statsdListener collects datagrams sent to a local UDP socket.
+func statsdListener(t *testing.T) (addr string, read func() string) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn.LocalAddr().String(), func() string {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, 1024)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("no datagram: %v", err)
+		}
+		return string(buf[:n])
+	}
+}
+
+func TestStatsDSinkWireFormat(t *testing.T) {
+	addr, read := statsdListener(t)
+	s, err := NewStatsDSink(addr, "", map[string]string{"env": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Count("denied", 1, "route:/login")
+	if got := read(); got != "signalfence.denied:1|c|#env:test,route:/login" {
+		t.Fatalf("counter = %q", got)
+	}
+
+	s.Gauge("keys", 42)
+	if got := read(); got != "signalfence.keys:42|g|#env:test" {
+		t.Fatalf("gauge = %q", got)
+	}
+
+	s.Timing("latency", 1500*time.Microsecond)
+	if got := read(); got != "signalfence.latency:1.500|ms|#env:test" {
+		t.Fatalf("timing = %q", got)
+	}
+}
+
+func TestStatsDSinkUntaggedAndPrefixed(t *testing.T) {
+	addr, read := statsdListener(t)
+	s, err := NewStatsDSink(addr, "myapp", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Count("allowed", 1)
+	if got := read(); got != "myapp.allowed:1|c" {
+		t.Fatalf("untagged counter = %q", got)
+	}
+	s.Count("allowed", 1, "route:/x")
+	if got := read(); got != "myapp.allowed:1|c|#route:/x" {
+		t.Fatalf("extra-tagged counter = %q", got)
+	}
+}
+
+func TestStatsDSinkLogCountsDecisions(t *testing.T) {
+	addr, read := statsdListener(t)
+	s, err := NewStatsDSink(addr, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Log("key-ignored", "/login", Decision{Allowed: true}, time.Millisecond)
+	if got := read(); got != "signalfence.allowed:1|c|#route:/login" {
+		t.Fatalf("allowed = %q", got)
+	}
+	if got := read(); !strings.HasPrefix(got, "signalfence.latency:") {
+		t.Fatalf("latency = %q", got)
+	}
+
+	s.Log("k", "/login", Decision{}, time.Millisecond)
+	if got := read(); got != "signalfence.denied:1|c|#route:/login" {
+		t.Fatalf("denied = %q", got)
+	}
+}
+
+func TestStatsDSinkMiddleware(t *testing.T) {
+	addr, read := statsdListener(t)
+	s, err := NewStatsDSink(addr, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001})
+	h := s.Middleware(rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "3.3.3.3:1"
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got := read(); got != "signalfence.allowed:1|c|#route:/api/data" {
+		t.Fatalf("first request = %q", got)
+	}
+	read() // latency
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got := read(); got != "signalfence.denied:1|c|#route:/api/data" {
+		t.Fatalf("second request = %q", got)
+	}
+}
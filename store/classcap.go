@@ -0,0 +1,119 @@
+package store
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// evictSample is how many candidate buckets an over-cap eviction inspects
+// before removing the least recently used of them. Sampling keeps eviction
+// O(sample) instead of scanning the whole class.
+const evictSample = 64
+
+// classCap caps the number of keys sharing a prefix, with its own count so
+// eviction in one class never touches another.
+type classCap struct {
+	prefix string
+	max    int
+
+	mu    sync.Mutex
+	count int
+}
+
+// SetClassCap limits how many keys with the given prefix (e.g. "ip:",
+// "bearer:") the store will hold. When an insert pushes a class over its
+// cap, the least recently used bucket of a sampled set from that same
+// class is evicted. Separate caps mean an IP-spoofing flood can exhaust
+// only the "ip:" budget and never evicts API-key buckets:
+//
+//	s.SetClassCap("ip:", 1_000_000)
+//	s.SetClassCap("bearer:", 100_000)
+//
+// Caps apply to keys inserted after the call.
+func (s *MemoryStore) SetClassCap(prefix string, max int) {
+	s.classMu.Lock()
+	defer s.classMu.Unlock()
+	for _, c := range s.classes {
+		if c.prefix == prefix {
+			c.mu.Lock()
+			c.max = max
+			c.mu.Unlock()
+			return
+		}
+	}
+	s.classes = append(s.classes, &classCap{prefix: prefix, max: max})
+}
+
+// classOf returns the capped class key belongs to, if any.
+func (s *MemoryStore) classOf(key string) *classCap {
+	s.classMu.RLock()
+	defer s.classMu.RUnlock()
+	for _, c := range s.classes {
+		if strings.HasPrefix(key, c.prefix) {
+			return c
+		}
+	}
+	return nil
+}
+
+// noteInsert accounts for a newly stored key and evicts within its class
+// when the cap is exceeded.
+func (s *MemoryStore) noteInsert(key string) {
+	c := s.classOf(key)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.count++
+	over := c.count > c.max
+	c.mu.Unlock()
+	if over {
+		s.evictOldest(c)
+	}
+}
+
+// noteDelete accounts for a removed key.
+func (s *MemoryStore) noteDelete(key string) {
+	c := s.classOf(key)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.count > 0 {
+		c.count--
+	}
+	c.mu.Unlock()
+}
+
+// evictOldest removes the least recently used bucket among a sample of the
+// class's keys.
+func (s *MemoryStore) evictOldest(c *classCap) {
+	var (
+		victim  string
+		oldest  time.Time
+		sampled int
+	)
+	s.buckets.Range(func(k, v any) bool {
+		key := k.(string)
+		if !strings.HasPrefix(key, c.prefix) {
+			return true
+		}
+		if last := v.(core.TokenBucket).LastAccess(); victim == "" || last.Before(oldest) {
+			victim, oldest = key, last
+		}
+		sampled++
+		return sampled < evictSample
+	})
+	if victim == "" {
+		return
+	}
+	if _, ok := s.buckets.Load(victim); ok {
+		s.buckets.Delete(victim)
+		s.size.Add(-1)
+		s.noteDelete(victim)
+		s.evictions.Add(1)
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// classCounts tallies live keys per prefix.
+func classCounts(s *MemoryStore) (ip, bearer int) {
+	s.Range(func(key string, _ core.TokenBucket) bool {
+		switch {
+		case strings.HasPrefix(key, "ip:"):
+			ip++
+		case strings.HasPrefix(key, "bearer:"):
+			bearer++
+		}
+		return true
+	})
+	return ip, bearer
+}
+
+func TestClassCapEvictsWithinClass(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetClassCap("ip:", 10)
+
+	// A handful of bearer keys that must survive the flood.
+	for i := 0; i < 5; i++ {
+		s.Get(fmt.Sprintf("bearer:token-%d", i), newBucket)
+	}
+
+	// Flood far past the ip: cap.
+	for i := 0; i < 100; i++ {
+		s.Get(fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256), newBucket)
+	}
+
+	ip, bearer := classCounts(s)
+	if ip > 10 {
+		t.Fatalf("ip keys = %d, want at most the cap of 10", ip)
+	}
+	if bearer != 5 {
+		t.Fatalf("bearer keys = %d, want all 5 untouched", bearer)
+	}
+	if s.Evictions() == 0 {
+		t.Fatal("eviction counter should have moved")
+	}
+}
+
+func TestUncappedClassesAreUnlimited(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetClassCap("ip:", 5)
+	for i := 0; i < 50; i++ {
+		s.Get(fmt.Sprintf("bearer:token-%d", i), newBucket)
+	}
+	if _, bearer := classCounts(s); bearer != 50 {
+		t.Fatalf("bearer keys = %d, want 50", bearer)
+	}
+}
+
+func TestClassCapUpdate(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetClassCap("ip:", 5)
+	s.SetClassCap("ip:", 2)
+	for i := 0; i < 20; i++ {
+		s.Get(fmt.Sprintf("ip:10.0.0.%d", i), newBucket)
+	}
+	if ip, _ := classCounts(s); ip > 2 {
+		t.Fatalf("ip keys = %d, want at most 2 after cap update", ip)
+	}
+}
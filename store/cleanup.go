@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// CleanupStats describes the most recent cleanup pass.
+type CleanupStats struct {
+	// LastRun is when the pass started; zero if none has run yet.
+	LastRun time.Time
+	// Removed is how many idle buckets the pass evicted.
+	Removed int
+	// Remaining is how many keys were still tracked afterwards.
+	Remaining int
+	// Took is how long the pass took.
+	Took time.Duration
+	// NextInterval is the delay chosen before the next background pass.
+	// It is zero for passes triggered manually via Cleanup.
+	NextInterval time.Duration
+}
+
+// cleanupJitter is the fraction of random spread applied to each interval,
+// so multiple instances don't sweep in lockstep.
+const cleanupJitter = 0.2
+
+// cleanupInterval picks the next sweep delay from the store size: a large
+// map is swept aggressively, a near-empty one barely at all. A fixed tick
+// is either too slow under attack or pure churn when idle.
+func cleanupInterval(keys int) time.Duration {
+	var base time.Duration
+	switch {
+	case keys > 1_000_000:
+		base = 15 * time.Second
+	case keys > 100_000:
+		base = 30 * time.Second
+	case keys > 10_000:
+		base = time.Minute
+	case keys > 1_000:
+		base = 5 * time.Minute
+	default:
+		base = 10 * time.Minute
+	}
+	spread := 1 - cleanupJitter + 2*cleanupJitter*rand.Float64()
+	return time.Duration(float64(base) * spread)
+}
+
+// cleanupState carries stats shared between the background loop and
+// LastCleanup.
+type cleanupState struct {
+	mu    sync.Mutex
+	stats CleanupStats
+}
+
+// runCleanup runs one expiry pass and records its stats.
+func (s *MemoryStore) runCleanup(maxIdle, next time.Duration) int {
+	start := time.Now()
+	removed := s.sweep(maxIdle)
+	s.cleanup.mu.Lock()
+	s.cleanup.stats = CleanupStats{
+		LastRun:      start,
+		Removed:      removed,
+		Remaining:    s.Len(),
+		Took:         time.Since(start),
+		NextInterval: next,
+	}
+	s.cleanup.mu.Unlock()
+	s.maybeRecover()
+	return removed
+}
+
+// LastCleanup returns stats for the most recent cleanup pass, whether it
+// was run manually or by the background loop.
+func (s *MemoryStore) LastCleanup() CleanupStats {
+	s.cleanup.mu.Lock()
+	defer s.cleanup.mu.Unlock()
+	return s.cleanup.stats
+}
+
+// StartCleanup sweeps idle buckets in the background until ctx is done.
+// Intervals adapt to the store size and are jittered; see cleanupInterval.
+func (s *MemoryStore) StartCleanup(ctx context.Context, maxIdle time.Duration) {
+	go func() {
+		for {
+			next := cleanupInterval(s.Len())
+			t := time.NewTimer(next)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+				s.runCleanup(maxIdle, next)
+			}
+		}
+	}()
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCleanupIntervalAdapts(t *testing.T) {
+	small := cleanupInterval(10)
+	large := cleanupInterval(2_000_000)
+	if large >= small {
+		t.Fatalf("large-store interval %v should be shorter than small-store %v", large, small)
+	}
+	// 15s base with ±20% jitter stays within [12s, 18s].
+	if large < 12*time.Second || large > 18*time.Second {
+		t.Fatalf("large-store interval %v outside jitter bounds", large)
+	}
+}
+
+func TestCleanupIntervalIsJittered(t *testing.T) {
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 50; i++ {
+		seen[cleanupInterval(2_000_000)] = true
+	}
+	if len(seen) < 2 {
+		t.Fatal("jitter should vary the interval")
+	}
+}
+
+func TestCleanupRecordsStats(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		s.Get(string(rune('a'+i)), newBucket)
+	}
+	if got := s.LastCleanup(); !got.LastRun.IsZero() {
+		t.Fatalf("stats before any run = %+v", got)
+	}
+	s.Cleanup(0)
+	got := s.LastCleanup()
+	if got.LastRun.IsZero() || got.Removed != 5 || got.Remaining != 0 {
+		t.Fatalf("stats = %+v", got)
+	}
+}
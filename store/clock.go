@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TimeSource is a storage backend's authoritative clock — Redis TIME, SQL
+// now() — sampled to anchor refill math across instances.
+type TimeSource interface {
+	Now() (time.Time, error)
+}
+
+// SyncedClockConfig configures backend clock anchoring.
+type SyncedClockConfig struct {
+	// Source is the backend clock to anchor to.
+	Source TimeSource
+	// Every is the sampling interval for Start. Zero means one minute.
+	Every time.Duration
+	// Tolerance is the drift ignored as sampling jitter: offsets within
+	// it keep the previous anchor, so the clock does not wobble with
+	// every round trip. Zero means 250ms.
+	Tolerance time.Duration
+	// OnSkew, when set, is called when a sample moves the anchor (drift
+	// beyond the tolerance) or fails, for alerting on badly drifting
+	// instance clocks.
+	OnSkew func(offset time.Duration, err error)
+}
+
+// SyncedClock serves time anchored to a shared backend's clock, for use as
+// Config.Now when several instances share one store. Each instance applies
+// the same backend-derived offset to its local clock, so clock drift
+// between instances stops over- and under-granting tokens: refill math
+// happens in the backend's timeline everywhere. Between samples time
+// advances with the local clock, so reads stay cheap and monotonic.
+type SyncedClock struct {
+	cfg    SyncedClockConfig
+	offset atomic.Int64 // backend minus local, nanoseconds
+}
+
+// NewSyncedClock returns a clock anchored by src with cfg's zero values
+// defaulted. Call Sync (or Start) before trusting Now.
+func NewSyncedClock(cfg SyncedClockConfig) *SyncedClock {
+	if cfg.Every <= 0 {
+		cfg.Every = time.Minute
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 250 * time.Millisecond
+	}
+	return &SyncedClock{cfg: cfg}
+}
+
+// Now returns the current time in the backend's timeline.
+func (c *SyncedClock) Now() time.Time {
+	return time.Now().Add(time.Duration(c.offset.Load()))
+}
+
+// Offset returns the currently applied backend-minus-local offset.
+func (c *SyncedClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Sync takes one backend clock sample, updating the anchor when the drift
+// exceeds the tolerance. It returns the source's error, if any.
+func (c *SyncedClock) Sync() error {
+	backend, err := c.cfg.Source.Now()
+	if err != nil {
+		if c.cfg.OnSkew != nil {
+			c.cfg.OnSkew(c.Offset(), err)
+		}
+		return err
+	}
+	offset := time.Until(backend)
+	if delta := offset - c.Offset(); delta > -c.cfg.Tolerance && delta < c.cfg.Tolerance {
+		return nil
+	}
+	c.offset.Store(int64(offset))
+	if c.cfg.OnSkew != nil {
+		c.cfg.OnSkew(offset, nil)
+	}
+	return nil
+}
+
+// Start samples the backend clock on the configured interval until ctx
+// ends, after one immediate sample to anchor startup.
+func (c *SyncedClock) Start(ctx context.Context) {
+	c.Sync()
+	go func() {
+		ticker := time.NewTicker(c.cfg.Every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Sync()
+			}
+		}
+	}()
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeTimeSource struct {
+	offset time.Duration
+	err    error
+}
+
+func (f *fakeTimeSource) Now() (time.Time, error) {
+	if f.err != nil {
+		return time.Time{}, f.err
+	}
+	return time.Now().Add(f.offset), nil
+}
+
+func TestSyncedClockAdoptsBackendOffset(t *testing.T) {
+	src := &fakeTimeSource{offset: 5 * time.Second}
+	var skews int
+	c := NewSyncedClock(SyncedClockConfig{
+		Source: src,
+		OnSkew: func(offset time.Duration, err error) { skews++ },
+	})
+
+	if err := c.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if off := c.Offset(); off < 4900*time.Millisecond || off > 5100*time.Millisecond {
+		t.Fatalf("offset = %v, want ~5s", off)
+	}
+	if got := time.Until(c.Now()); got < 4*time.Second {
+		t.Fatalf("Now not in backend timeline: %v ahead", got)
+	}
+	if skews != 1 {
+		t.Fatalf("OnSkew calls = %d, want 1", skews)
+	}
+
+	// Drift within the tolerance keeps the anchor stable.
+	src.offset = 5*time.Second + 50*time.Millisecond
+	before := c.Offset()
+	c.Sync()
+	if c.Offset() != before {
+		t.Fatal("anchor moved for sub-tolerance jitter")
+	}
+	if skews != 1 {
+		t.Fatalf("OnSkew called for sub-tolerance jitter")
+	}
+}
+
+func TestSyncedClockReportsSourceErrors(t *testing.T) {
+	srcErr := errors.New("backend down")
+	var gotErr error
+	c := NewSyncedClock(SyncedClockConfig{
+		Source: &fakeTimeSource{err: srcErr},
+		OnSkew: func(_ time.Duration, err error) { gotErr = err },
+	})
+	if err := c.Sync(); !errors.Is(err, srcErr) {
+		t.Fatalf("Sync error = %v", err)
+	}
+	if !errors.Is(gotErr, srcErr) {
+		t.Fatalf("OnSkew error = %v", gotErr)
+	}
+	if c.Offset() != 0 {
+		t.Fatalf("offset moved on error: %v", c.Offset())
+	}
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// LeaderStore is implemented by backends that can hold a shared lease, used
+// to elect one replica to run expensive background jobs (cleanup,
+// aggregation) when several processes share the same storage. A distributed
+// backend implements the lease in shared storage; MemoryStore implements it
+// in-process, where the single replica is trivially the leader.
+type LeaderStore interface {
+	// AcquireLease grants or renews the named lease for holder, valid for
+	// ttl, and reports whether holder now owns it. A lease held by another
+	// holder is only granted once it has expired, which gives automatic
+	// failover when a leader dies without releasing.
+	AcquireLease(name, holder string, ttl time.Duration) bool
+}
+
+// lease records who holds a named lease and until when.
+type lease struct {
+	holder  string
+	expires time.Time
+}
+
+// leases holds the in-memory lease table for MemoryStore.
+type leases struct {
+	mu sync.Mutex
+	m  map[string]lease
+}
+
+// AcquireLease implements LeaderStore.
+func (s *MemoryStore) AcquireLease(name, holder string, ttl time.Duration) bool {
+	s.leases.mu.Lock()
+	defer s.leases.mu.Unlock()
+	if s.leases.m == nil {
+		s.leases.m = make(map[string]lease)
+	}
+	now := time.Now()
+	cur, held := s.leases.m[name]
+	if held && cur.holder != holder && now.Before(cur.expires) {
+		return false
+	}
+	s.leases.m[name] = lease{holder: holder, expires: now.Add(ttl)}
+	return true
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// cleanupBatch is how many expired buckets Cleanup removes before yielding
+// the processor, bounding the latency impact of a large scan.
+const cleanupBatch = 1024
+
+// MemoryStore keeps buckets in process memory using a sync.Map. It is the
+// default backend and suits single-instance deployments.
+type MemoryStore struct {
+	buckets   sync.Map // key string -> core.TokenBucket
+	size      atomic.Int64
+	evictions atomic.Uint64
+	cleanup   cleanupState
+
+	classMu sync.RWMutex
+	classes []*classCap
+
+	maxBytes atomic.Int64
+	degraded atomic.Bool
+	globalMu sync.Mutex
+	global   core.TokenBucket
+
+	leases leases
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Get returns the bucket for key, creating it with newBucket if absent.
+// While the memory guardrail has the store degraded, unknown keys share a
+// single global bucket instead of growing the map (see SetMaxBytes).
+func (s *MemoryStore) Get(key string, newBucket func() core.TokenBucket) core.TokenBucket {
+	if b, ok := s.buckets.Load(key); ok {
+		return b.(core.TokenBucket)
+	}
+	if s.degraded.Load() {
+		return s.globalBucket(newBucket)
+	}
+	b, loaded := s.buckets.LoadOrStore(key, newBucket())
+	if !loaded {
+		s.size.Add(1)
+		s.noteInsert(key)
+		s.checkGuardrail()
+	}
+	return b.(core.TokenBucket)
+}
+
+// Load returns the bucket for key without creating one.
+func (s *MemoryStore) Load(key string) (core.TokenBucket, bool) {
+	b, ok := s.buckets.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return b.(core.TokenBucket), true
+}
+
+// Delete removes key's bucket immediately, for callers that know a key is
+// done — a logged-out session — rather than waiting for idle cleanup.
+func (s *MemoryStore) Delete(key string) {
+	if _, ok := s.buckets.LoadAndDelete(key); ok {
+		s.size.Add(-1)
+		s.noteDelete(key)
+	}
+}
+
+// Len returns the number of keys currently tracked.
+func (s *MemoryStore) Len() int {
+	return int(s.size.Load())
+}
+
+// Cleanup removes buckets that have been idle for longer than maxIdle and
+// returns how many were removed. It runs incrementally: expiry checks ride
+// on sync.Map.Range, which never blocks concurrent readers or writers, and
+// the goroutine yields after every cleanupBatch deletions so a scan over
+// millions of keys cannot monopolize a processor and spike tail latency.
+func (s *MemoryStore) Cleanup(maxIdle time.Duration) int {
+	return s.runCleanup(maxIdle, 0)
+}
+
+// sweep is the expiry pass backing Cleanup; runCleanup wraps it with stats
+// recording.
+func (s *MemoryStore) sweep(maxIdle time.Duration) int {
+	cutoff := time.Now().Add(-maxIdle)
+	removed, inBatch := 0, 0
+	s.buckets.Range(func(k, v any) bool {
+		if v.(core.TokenBucket).LastAccess().Before(cutoff) {
+			s.buckets.Delete(k)
+			s.size.Add(-1)
+			s.noteDelete(k.(string))
+			removed++
+			if inBatch++; inBatch >= cleanupBatch {
+				inBatch = 0
+				runtime.Gosched()
+			}
+		}
+		return true
+	})
+	s.evictions.Add(uint64(removed))
+	return removed
+}
+
+// Evictions returns the cumulative number of buckets removed by Cleanup.
+func (s *MemoryStore) Evictions() uint64 {
+	return s.evictions.Load()
+}
+
+// Range calls fn for each key and bucket in the store until fn returns false.
+func (s *MemoryStore) Range(fn func(key string, b core.TokenBucket) bool) {
+	s.buckets.Range(func(k, v any) bool {
+		return fn(k.(string), v.(core.TokenBucket))
+	})
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+func newBucket() core.TokenBucket { return core.NewBucket(10, 1) }
+
+func TestMemoryStoreGetAndLoad(t *testing.T) {
+	s := NewMemoryStore()
+	b := s.Get("k", newBucket)
+	if got := s.Get("k", newBucket); got != b {
+		t.Fatal("Get should return the same bucket for the same key")
+	}
+	if got, ok := s.Load("k"); !ok || got != b {
+		t.Fatal("Load should find the existing bucket")
+	}
+	if _, ok := s.Load("missing"); ok {
+		t.Fatal("Load must not create buckets")
+	}
+	if s.Len() != 1 {
+		t.Fatalf("Len = %d", s.Len())
+	}
+}
+
+func TestCleanupRemovesIdleBuckets(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		s.Get(fmt.Sprintf("k%d", i), newBucket)
+	}
+
+	// Nothing is older than an hour.
+	if removed := s.Cleanup(time.Hour); removed != 0 {
+		t.Fatalf("removed %d fresh buckets", removed)
+	}
+
+	// With a zero idle allowance everything is expired.
+	if removed := s.Cleanup(0); removed != 100 {
+		t.Fatalf("removed = %d, want 100", removed)
+	}
+	if s.Len() != 0 {
+		t.Fatalf("Len = %d after cleanup", s.Len())
+	}
+	if s.Evictions() != 100 {
+		t.Fatalf("Evictions = %d", s.Evictions())
+	}
+}
+
+func TestCleanupRunsConcurrentlyWithTraffic(t *testing.T) {
+	s := NewMemoryStore()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		i := 0
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			s.Get(fmt.Sprintf("live%d", i%50), newBucket).Take(1, time.Now())
+			i++
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		s.Cleanup(time.Hour)
+	}
+	close(stop)
+	wg.Wait()
+}
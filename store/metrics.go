@@ -0,0 +1,83 @@
+package store
+
+import "github.com/yourusername/signalfence/core"
+
+// estimatedBytesPerBucket approximates what one tracked key costs: the
+// Bucket struct itself plus the sync.Map entry, the key string header and
+// a typical key payload.
+const estimatedBytesPerBucket = 192
+
+// Metrics is a snapshot of the store's size and churn gauges, suitable for
+// export to a metrics backend.
+type Metrics struct {
+	// Buckets is the number of keys currently tracked.
+	Buckets int `json:"buckets"`
+	// EstimatedBytes approximates the memory held by those buckets.
+	EstimatedBytes int64 `json:"estimated_bytes"`
+	// Evictions is the cumulative number of buckets removed by cleanup
+	// and class-cap eviction.
+	Evictions uint64 `json:"evictions"`
+	// Degraded reports whether the memory guardrail has collapsed the
+	// store to global-bucket-only mode.
+	Degraded bool `json:"degraded"`
+}
+
+// Metrics returns the current gauges.
+func (s *MemoryStore) Metrics() Metrics {
+	n := s.size.Load()
+	return Metrics{
+		Buckets:        int(n),
+		EstimatedBytes: n * estimatedBytesPerBucket,
+		Evictions:      s.evictions.Load(),
+		Degraded:       s.degraded.Load(),
+	}
+}
+
+// SetMaxBytes installs a hard memory guardrail. When the estimated bucket
+// memory exceeds maxBytes the store degrades: unknown keys stop growing the
+// map and instead share one global bucket, trading per-key fairness for
+// staying alive. The store recovers automatically once cleanup shrinks the
+// estimate below 90% of the limit. Zero removes the guardrail.
+func (s *MemoryStore) SetMaxBytes(maxBytes int64) {
+	s.maxBytes.Store(maxBytes)
+	if maxBytes == 0 {
+		s.degraded.Store(false)
+		return
+	}
+	s.checkGuardrail()
+}
+
+// checkGuardrail flips the store into degraded mode when the memory
+// estimate crosses the limit.
+func (s *MemoryStore) checkGuardrail() {
+	max := s.maxBytes.Load()
+	if max <= 0 {
+		return
+	}
+	if s.size.Load()*estimatedBytesPerBucket > max {
+		s.degraded.Store(true)
+	}
+}
+
+// maybeRecover clears degraded mode once usage has fallen well below the
+// limit; called after cleanup passes.
+func (s *MemoryStore) maybeRecover() {
+	max := s.maxBytes.Load()
+	if max <= 0 || !s.degraded.Load() {
+		return
+	}
+	if float64(s.size.Load()*estimatedBytesPerBucket) < 0.9*float64(max) {
+		s.degraded.Store(false)
+	}
+}
+
+// globalBucket returns the shared bucket used while degraded, creating it
+// from newBucket (and therefore the first caller's policy) on first use.
+func (s *MemoryStore) globalBucket(newBucket func() core.TokenBucket) core.TokenBucket {
+	s.globalMu.Lock()
+	defer s.globalMu.Unlock()
+	if s.global == nil {
+		s.global = newBucket()
+	}
+	return s.global
+}
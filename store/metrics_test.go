@@ -0,0 +1,64 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMetricsGauges(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 10; i++ {
+		s.Get(fmt.Sprintf("k%d", i), newBucket)
+	}
+	m := s.Metrics()
+	if m.Buckets != 10 || m.EstimatedBytes != 10*estimatedBytesPerBucket {
+		t.Fatalf("metrics = %+v", m)
+	}
+	s.Cleanup(0)
+	m = s.Metrics()
+	if m.Buckets != 0 || m.Evictions != 10 {
+		t.Fatalf("metrics after cleanup = %+v", m)
+	}
+}
+
+func TestMemoryGuardrailDegrades(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetMaxBytes(5 * estimatedBytesPerBucket)
+
+	for i := 0; i < 10; i++ {
+		s.Get(fmt.Sprintf("k%d", i), newBucket)
+	}
+	if !s.Metrics().Degraded {
+		t.Fatal("store should be degraded past the byte limit")
+	}
+	if s.Len() > 6 {
+		t.Fatalf("map kept growing while degraded: %d keys", s.Len())
+	}
+
+	// All unknown keys share the global bucket now.
+	a := s.Get("unseen-a", newBucket)
+	b := s.Get("unseen-b", newBucket)
+	if a != b {
+		t.Fatal("degraded mode should serve one shared bucket")
+	}
+
+	// Cleanup shrinks the store and lifts the degradation.
+	s.Cleanup(0)
+	if s.Metrics().Degraded {
+		t.Fatal("store should recover after cleanup")
+	}
+	c := s.Get("unseen-c", newBucket)
+	if c == a {
+		t.Fatal("recovered store should mint per-key buckets again")
+	}
+}
+
+func TestGuardrailOffByDefault(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 1000; i++ {
+		s.Get(fmt.Sprintf("k%d", i), newBucket)
+	}
+	if s.Metrics().Degraded {
+		t.Fatal("no guardrail configured, store must never degrade")
+	}
+}
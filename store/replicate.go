@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// defaultSyncEvery is the reconcile interval when the caller does not set
+// one. It bounds the cross-region overshoot window.
+const defaultSyncEvery = 5 * time.Second
+
+// GlobalCounter is the cross-region aggregation point for replicated rate
+// limiting — in production a Redis primary (or any shared counter service)
+// reachable from every region. Add reports tokens consumed locally and
+// returns the new global total for the key's current accounting window.
+// Implementations own the window semantics, including resets.
+type GlobalCounter interface {
+	Add(key string, n float64) (total float64, err error)
+}
+
+// ReplicaConfig configures a Replicated store.
+type ReplicaConfig struct {
+	// Counter is the shared global counter. Required.
+	Counter GlobalCounter
+	// GlobalPerWindow is the cross-region consumption allowed per counter
+	// window. When the global total exceeds it, the local overshoot is
+	// drained from the region's buckets. Zero disables correction, leaving
+	// the counter as pure telemetry.
+	GlobalPerWindow float64
+	// Every is the reconcile interval for Start. Zero means five seconds.
+	Every time.Duration
+	// OnError, when set, receives counter errors. Reporting retries on the
+	// next pass; token consumption never blocks on the counter.
+	OnError func(error)
+}
+
+// Replicated wraps a region-local MemoryStore with asynchronous global
+// reconciliation: requests consume tokens against local buckets at local
+// latency, and a background pass reports each key's consumption delta to
+// the shared counter. When the global total for a key overruns its
+// cross-region allowance, the overshoot is repaid by force-taking tokens
+// locally — capped at what this region itself consumed since the last
+// pass, so one region is never punished for another's traffic. Overshoot
+// is therefore bounded by roughly one reconcile interval of aggregate
+// traffic; strict global consistency would cost a cross-region round trip
+// per request.
+type Replicated struct {
+	local *MemoryStore
+	cfg   ReplicaConfig
+
+	mu       sync.Mutex
+	reported map[string]float64 // key -> consumption already reported
+}
+
+// NewReplicated returns local wrapped with reconciliation under cfg.
+func NewReplicated(local *MemoryStore, cfg ReplicaConfig) *Replicated {
+	if cfg.Every <= 0 {
+		cfg.Every = defaultSyncEvery
+	}
+	return &Replicated{local: local, cfg: cfg, reported: make(map[string]float64)}
+}
+
+// Get returns the bucket for key, creating it with newBucket if absent.
+func (r *Replicated) Get(key string, newBucket func() core.TokenBucket) core.TokenBucket {
+	return r.local.Get(key, newBucket)
+}
+
+// Load returns the bucket for key without creating one.
+func (r *Replicated) Load(key string) (core.TokenBucket, bool) {
+	return r.local.Load(key)
+}
+
+// Delete removes key's bucket and its reconciliation bookkeeping. Tokens
+// already reported to the global counter stay reported; they age out with
+// the counter's window.
+func (r *Replicated) Delete(key string) {
+	r.local.Delete(key)
+	r.mu.Lock()
+	delete(r.reported, key)
+	r.mu.Unlock()
+}
+
+// Len returns the number of keys currently tracked.
+func (r *Replicated) Len() int {
+	return r.local.Len()
+}
+
+// Sync runs one reconcile pass at time now: it reports every key's
+// consumption since the previous pass and drains local overshoot against
+// the global allowance. Start calls it on a ticker; tests call it
+// directly.
+func (r *Replicated) Sync(now time.Time) {
+	r.local.Range(func(key string, b core.TokenBucket) bool {
+		taken := b.Taken()
+		r.mu.Lock()
+		delta := taken - r.reported[key]
+		r.mu.Unlock()
+		if delta <= 0 {
+			return true
+		}
+		total, err := r.cfg.Counter.Add(key, delta)
+		if err != nil {
+			if r.cfg.OnError != nil {
+				r.cfg.OnError(err)
+			}
+			return true // leave reported as-is; the delta retries next pass
+		}
+		r.mu.Lock()
+		r.reported[key] = taken
+		r.mu.Unlock()
+		if r.cfg.GlobalPerWindow > 0 && total > r.cfg.GlobalPerWindow {
+			overshoot := total - r.cfg.GlobalPerWindow
+			if overshoot > delta {
+				overshoot = delta
+			}
+			b.ForceTake(overshoot, now)
+			// The drain raises the bucket's consumption counter; fold it
+			// into reported so a correction is never re-reported as
+			// client traffic.
+			r.mu.Lock()
+			r.reported[key] += overshoot
+			r.mu.Unlock()
+		}
+		return true
+	})
+}
+
+// Start reconciles on the configured interval until ctx ends.
+func (r *Replicated) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.cfg.Every)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				r.Sync(now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// MemoryCounter is a process-local GlobalCounter for tests and
+// single-region deployments. Totals reset when a key's window expires; a
+// window of zero never resets.
+type MemoryCounter struct {
+	mu     sync.Mutex
+	window time.Duration
+	totals map[string]*counterWindow
+}
+
+type counterWindow struct {
+	start time.Time
+	total float64
+}
+
+// NewMemoryCounter returns an empty counter with the given window.
+func NewMemoryCounter(window time.Duration) *MemoryCounter {
+	return &MemoryCounter{window: window, totals: make(map[string]*counterWindow)}
+}
+
+// Add records n consumed tokens for key and returns the window's total.
+func (c *MemoryCounter) Add(key string, n float64) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	w, ok := c.totals[key]
+	if !ok || (c.window > 0 && now.Sub(w.start) >= c.window) {
+		w = &counterWindow{start: now}
+		c.totals[key] = w
+	}
+	w.total += n
+	return w.total, nil
+}
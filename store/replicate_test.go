@@ -0,0 +1,105 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+func drain(t *testing.T, s Store, key string, n int, now time.Time) {
+	t.Helper()
+	b := s.Get(key, func() core.TokenBucket { return core.NewBucketAt(100, 0.001, now) })
+	for i := 0; i < n; i++ {
+		if ok, _, _ := b.Take(1, now); !ok {
+			t.Fatalf("take %d for %s failed", i, key)
+		}
+	}
+}
+
+func TestReplicatedReportsAndDrainsOvershoot(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	counter := NewMemoryCounter(0)
+	east := NewReplicated(NewMemoryStore(), ReplicaConfig{Counter: counter, GlobalPerWindow: 60})
+	west := NewReplicated(NewMemoryStore(), ReplicaConfig{Counter: counter, GlobalPerWindow: 60})
+
+	// Each region consumes 40 locally: 80 globally against an allowance
+	// of 60.
+	drain(t, east, "user:u1", 40, now)
+	drain(t, west, "user:u1", 40, now)
+
+	east.Sync(now)
+	// East reported first: global total 40, under the allowance — no
+	// correction.
+	be, _ := east.Load("user:u1")
+	if got := be.Peek(now); got != 60 {
+		t.Fatalf("east drained early: %v tokens, want 60", got)
+	}
+
+	west.Sync(now)
+	// West's report pushes the total to 80; it repays the 20 overshoot.
+	bw, _ := west.Load("user:u1")
+	if got := bw.Peek(now); got != 40 {
+		t.Fatalf("west after correction: %v tokens, want 40", got)
+	}
+
+	// A later pass with no new consumption reports and drains nothing.
+	west.Sync(now)
+	if got := bw.Peek(now); got != 40 {
+		t.Fatalf("idle pass changed tokens: %v", got)
+	}
+}
+
+func TestReplicatedCorrectionCappedAtLocalDelta(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	counter := NewMemoryCounter(0)
+	// Someone else already consumed the entire global allowance.
+	counter.Add("user:u1", 200)
+
+	local := NewReplicated(NewMemoryStore(), ReplicaConfig{Counter: counter, GlobalPerWindow: 60})
+	drain(t, local, "user:u1", 5, now)
+	local.Sync(now)
+
+	// The overshoot is 145 but this region only consumed 5 since the last
+	// pass, so only 5 are drained.
+	b, _ := local.Load("user:u1")
+	if got := b.Peek(now); got != 90 {
+		t.Fatalf("tokens = %v, want 90 (95 minus the capped 5)", got)
+	}
+}
+
+func TestReplicatedRetriesAfterCounterError(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	fails := 1
+	inner := NewMemoryCounter(0)
+	counter := counterFunc(func(key string, n float64) (float64, error) {
+		if fails > 0 {
+			fails--
+			return 0, errors.New("redis: connection refused")
+		}
+		return inner.Add(key, n)
+	})
+
+	var seen error
+	local := NewReplicated(NewMemoryStore(), ReplicaConfig{
+		Counter: counter,
+		OnError: func(err error) { seen = err },
+	})
+	drain(t, local, "user:u1", 10, now)
+
+	local.Sync(now)
+	if seen == nil {
+		t.Fatal("counter error not surfaced")
+	}
+	// The unreported delta is retried on the next pass.
+	local.Sync(now)
+	if total, _ := inner.Add("user:u1", 0); total != 10 {
+		t.Fatalf("global total = %v, want 10", total)
+	}
+}
+
+// counterFunc adapts a function to the GlobalCounter interface.
+type counterFunc func(key string, n float64) (float64, error)
+
+func (f counterFunc) Add(key string, n float64) (float64, error) { return f(key, n) }
@@ -0,0 +1,27 @@
+// Package store provides bucket storage backends for SignalFence.
+package store
+
+import "github.com/yourusername/signalfence/core"
+
+// Store maps rate limit keys to their token buckets. Implementations must be
+// safe for concurrent use. Buckets are returned as the core.TokenBucket
+// interface so custom stores can supply their own bucket types; the
+// built-in backends hand out *core.Bucket.
+type Store interface {
+	// Get returns the bucket for key, creating it with newBucket if the key
+	// has not been seen before.
+	Get(key string, newBucket func() core.TokenBucket) core.TokenBucket
+
+	// Load returns the bucket for key without creating one. It exists so
+	// hot paths can avoid building the newBucket closure when the key is
+	// already tracked.
+	Load(key string) (core.TokenBucket, bool)
+
+	// Delete removes key's bucket immediately. Deleting an unknown key is
+	// a no-op. It backs GDPR deletions and manual resets, where waiting
+	// for idle cleanup is not acceptable.
+	Delete(key string)
+
+	// Len returns the number of keys currently tracked.
+	Len() int
+}
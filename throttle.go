@@ -0,0 +1,75 @@
+package signalfence
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// egressPrefix namespaces per-key egress buckets away from request buckets.
+const egressPrefix = "egress:"
+
+// ThrottleResponses wraps next so response bytes are paced per key by a
+// token bucket under pol, whose Capacity and RefillPerSec are a byte budget
+// (burst bytes and bytes per second). A client whose request rate is modest
+// can still saturate egress by streaming large downloads; this caps that
+// without rejecting the request — writes simply slow to the permitted rate.
+// Zero policy fields inherit the limiter's defaults.
+func (rl *RateLimiter) ThrottleResponses(pol Policy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := mergePolicy(pol, rl.EffectivePolicy(""))
+		b := rl.bucketFor(egressPrefix+rl.keyFunc(r), p)
+		b.EnsureLimits(float64(p.Capacity), p.RefillPerSec, float64(p.Grace))
+		next.ServeHTTP(&throttledWriter{
+			ResponseWriter: w,
+			bucket:         b,
+			now:            rl.now,
+			max:            p.Capacity,
+		}, r)
+	})
+}
+
+// throttledWriter meters writes through a byte bucket, sleeping until the
+// refill affords each chunk. Chunks are clamped to the bucket capacity so a
+// single large write can never deadlock against a small burst budget.
+type throttledWriter struct {
+	http.ResponseWriter
+	bucket core.TokenBucket
+	now    func() time.Time
+	max    int
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := len(p)
+		if chunk > tw.max {
+			chunk = tw.max
+		}
+		for {
+			ok, _, retry := tw.bucket.Take(float64(chunk), tw.now())
+			if ok {
+				break
+			}
+			if retry <= 0 {
+				retry = time.Millisecond
+			}
+			time.Sleep(retry)
+		}
+		n, err := tw.ResponseWriter.Write(p[:chunk])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[chunk:]
+	}
+	return written, nil
+}
+
+// Flush forwards to the underlying writer when it supports streaming.
+func (tw *throttledWriter) Flush() {
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
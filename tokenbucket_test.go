@@ -0,0 +1,80 @@
+package signalfence
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// tracingBucket is a custom TokenBucket implementation: the concrete
+// bucket with take counting layered on, as a stand-in for the remote or
+// windowed buckets custom stores return.
+type tracingBucket struct {
+	*core.Bucket
+	takes int
+}
+
+// tracingStore returns tracingBuckets, exercising the interface-typed
+// Store contract end to end.
+type tracingStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tracingBucket
+}
+
+func (s *tracingStore) Get(key string, newBucket func() core.TokenBucket) core.TokenBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tracingBucket{Bucket: newBucket().(*core.Bucket)}
+		s.buckets[key] = b
+	}
+	b.takes++
+	return b
+}
+
+func (s *tracingStore) Load(key string) (core.TokenBucket, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[key]
+	return b, ok
+}
+
+func (s *tracingStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets, key)
+}
+
+func (s *tracingStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+func TestCustomBucketTypeBehindStore(t *testing.T) {
+	ts := &tracingStore{buckets: make(map[string]*tracingBucket)}
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, Store: ts})
+
+	for i := 0; i < 2; i++ {
+		if d := rl.Allow("user:x"); !d.Allowed {
+			t.Fatalf("take %d denied within capacity", i)
+		}
+	}
+	if d := rl.Allow("user:x"); d.Allowed {
+		t.Fatal("custom bucket did not enforce capacity")
+	}
+	if ts.Len() != 1 {
+		t.Fatalf("store tracks %d keys, want 1", ts.Len())
+	}
+
+	// The all-or-nothing path must also work with non-concrete buckets.
+	d := rl.AllowAll([]string{"user:fresh", "user:x"})
+	if d.Allowed {
+		t.Fatalf("AllowAll over a drained member = %+v", d)
+	}
+	if b, _ := ts.Load("user:fresh"); b.Peek(rl.now()) != 2 {
+		t.Fatal("sequential fallback leaked a token on denial")
+	}
+}
@@ -0,0 +1,141 @@
+package signalfence
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTraceHistory is the trace ring size when TraceConfig.History is
+// zero.
+const DefaultTraceHistory = 128
+
+// TraceConfig enables decision trace sampling. Traces answer "why was this
+// request limited?" without grepping logs: each one records what the
+// pipeline saw for a single request.
+type TraceConfig struct {
+	// SampleEvery captures one in N requests. Zero samples none.
+	SampleEvery int
+	// AllDenials captures every denied request regardless of the sample
+	// stride — mystery 429s are the reason traces exist.
+	AllDenials bool
+	// History is the ring size of retained traces. Zero means
+	// DefaultTraceHistory.
+	History int
+}
+
+// DecisionTrace is one sampled request's walk through the pipeline: the
+// extractor's key, the route the path resolved to, the policy that was
+// enforced, the key-level state that could have reshaped it (escalation,
+// trust, session), the resulting decision and how long the decide step —
+// including the store access — took.
+type DecisionTrace struct {
+	At         time.Time     `json:"at"`
+	Key        string        `json:"key"`
+	Route      string        `json:"route"`
+	Policy     Policy        `json:"policy"`
+	Escalated  bool          `json:"escalated,omitempty"`
+	TrustLevel int           `json:"trust_level,omitempty"`
+	Session    bool          `json:"session,omitempty"`
+	Decision   Decision      `json:"decision"`
+	DecideTime time.Duration `json:"decide_ns"`
+}
+
+// traceBuffer is a fixed-size ring of recent traces plus the sampling
+// counter.
+type traceBuffer struct {
+	counter atomic.Uint64
+	mu      sync.Mutex
+	entries []DecisionTrace
+	next    int
+	full    bool
+}
+
+func (tb *traceBuffer) add(tr DecisionTrace, history int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.entries == nil {
+		tb.entries = make([]DecisionTrace, history)
+	}
+	tb.entries[tb.next] = tr
+	tb.next++
+	if tb.next == len(tb.entries) {
+		tb.next = 0
+		tb.full = true
+	}
+}
+
+// snapshot returns the retained traces newest first.
+func (tb *traceBuffer) snapshot() []DecisionTrace {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	n := tb.next
+	if tb.full {
+		n = len(tb.entries)
+	}
+	out := make([]DecisionTrace, 0, n)
+	for i := tb.next - 1; i >= 0; i-- {
+		out = append(out, tb.entries[i])
+	}
+	if tb.full {
+		for i := len(tb.entries) - 1; i >= tb.next; i-- {
+			out = append(out, tb.entries[i])
+		}
+	}
+	return out
+}
+
+// tracingEnabled reports whether any sampling is configured.
+func (rl *RateLimiter) tracingEnabled() bool {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.cfg.Trace.SampleEvery > 0 || rl.cfg.Trace.AllDenials
+}
+
+// traceSampled reports whether this request falls in the sample stride.
+func (rl *RateLimiter) traceSampled() bool {
+	rl.mu.RLock()
+	every := rl.cfg.Trace.SampleEvery
+	rl.mu.RUnlock()
+	if every <= 0 {
+		return false
+	}
+	return rl.traces.counter.Add(1)%uint64(every) == 0
+}
+
+// recordTrace captures one request's trace when it qualifies: sampled by
+// stride, or denied under AllDenials.
+func (rl *RateLimiter) recordTrace(key, route string, d Decision, decide time.Duration, sampled bool) {
+	rl.mu.RLock()
+	cfg := rl.cfg.Trace
+	rl.mu.RUnlock()
+	if !sampled && !(cfg.AllDenials && !d.Allowed) {
+		return
+	}
+	history := cfg.History
+	if history <= 0 {
+		history = DefaultTraceHistory
+	}
+	tr := DecisionTrace{
+		At:         time.Now(),
+		Key:        key,
+		Route:      route,
+		Policy:     rl.EffectivePolicy(route),
+		Decision:   d,
+		DecideTime: decide,
+	}
+	_, tr.Escalated = rl.escalated.get(key)
+	tr.TrustLevel = rl.TrustLevel(key)
+	_, tr.Session = rl.sessionPolicy(key)
+	rl.traces.add(tr, history)
+}
+
+// Traces returns up to n retained decision traces, newest first. n <= 0
+// returns them all.
+func (rl *RateLimiter) Traces(n int) []DecisionTrace {
+	out := rl.traces.snapshot()
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
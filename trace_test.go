@@ -0,0 +1,113 @@
+package signalfence
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceSampleStride(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 1,
+		Trace:        TraceConfig{SampleEvery: 3},
+	})
+	defer rl.Close()
+
+	for i := 0; i < 9; i++ {
+		rl.Allow("k")
+	}
+	if got := len(rl.Traces(0)); got != 3 {
+		t.Fatalf("traces = %d, want 3", got)
+	}
+}
+
+func TestTraceAllDenials(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     1,
+		RefillPerSec: 0.001,
+		Trace:        TraceConfig{AllDenials: true},
+	})
+	defer rl.Close()
+
+	rl.Allow("k")
+	rl.Allow("k")
+	traces := rl.Traces(0)
+	if len(traces) != 1 {
+		t.Fatalf("traces = %d, want 1 (denial only)", len(traces))
+	}
+	tr := traces[0]
+	if tr.Decision.Allowed || tr.Key != "k" || tr.Decision.RetryAfter <= 0 {
+		t.Fatalf("trace = %+v", tr)
+	}
+	if tr.DecideTime <= 0 {
+		t.Fatalf("DecideTime = %v", tr.DecideTime)
+	}
+}
+
+func TestTraceRingWraps(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 1,
+		Trace:        TraceConfig{SampleEvery: 1, History: 2},
+	})
+	defer rl.Close()
+
+	rl.Allow("a")
+	rl.Allow("b")
+	rl.Allow("c")
+	traces := rl.Traces(0)
+	if len(traces) != 2 || traces[0].Key != "c" || traces[1].Key != "b" {
+		t.Fatalf("traces = %+v", traces)
+	}
+	if got := len(rl.Traces(1)); got != 1 {
+		t.Fatalf("Traces(1) = %d entries", got)
+	}
+}
+
+func TestTracePeekNotSampled(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 1,
+		Trace:        TraceConfig{SampleEvery: 1},
+	})
+	defer rl.Close()
+
+	rl.Peek("k", "")
+	if got := len(rl.Traces(0)); got != 0 {
+		t.Fatalf("peek traced: %d entries", got)
+	}
+}
+
+func TestAdminTraces(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 1,
+		Trace:        TraceConfig{SampleEvery: 1},
+	})
+	defer rl.Close()
+
+	rl.AllowRoute("a", "/api/data")
+	rl.Allow("b")
+	rl.Allow("b")
+	h := rl.AdminHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/traces?key=b&limit=1", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var traces []DecisionTrace
+	if err := json.Unmarshal(w.Body.Bytes(), &traces); err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 || traces[0].Key != "b" {
+		t.Fatalf("traces = %+v", traces)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/traces?limit=zero", nil))
+	if w.Code != 400 {
+		t.Fatalf("bad limit status = %d", w.Code)
+	}
+}
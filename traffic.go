@@ -0,0 +1,95 @@
+package signalfence
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TrafficStats aggregates per-key traffic counters: how many requests the
+// key made, how many were admitted, and the bytes moved in each direction.
+// They feed the bandwidth limiters, capacity planning and billing export.
+type TrafficStats struct {
+	Requests uint64 `json:"requests"`
+	Allowed  uint64 `json:"allowed"`
+	BytesIn  uint64 `json:"bytes_in"`
+	BytesOut uint64 `json:"bytes_out"`
+}
+
+// trafficCounters is the internal atomic form of TrafficStats. lastSeen
+// (unix nanos) lets the retention sweep purge counters for keys gone
+// quiet.
+type trafficCounters struct {
+	requests atomic.Uint64
+	allowed  atomic.Uint64
+	bytesIn  atomic.Uint64
+	bytesOut atomic.Uint64
+	lastSeen atomic.Int64
+}
+
+type trafficStats struct {
+	keys sync.Map // key string -> *trafficCounters
+}
+
+func (ts *trafficStats) record(key string, allowed bool, in, out uint64) {
+	v, ok := ts.keys.Load(key)
+	if !ok {
+		v, _ = ts.keys.LoadOrStore(key, &trafficCounters{})
+	}
+	c := v.(*trafficCounters)
+	c.requests.Add(1)
+	if allowed {
+		c.allowed.Add(1)
+	}
+	c.bytesIn.Add(in)
+	c.bytesOut.Add(out)
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+func (ts *trafficStats) snapshot(key string) TrafficStats {
+	v, ok := ts.keys.Load(key)
+	if !ok {
+		return TrafficStats{}
+	}
+	c := v.(*trafficCounters)
+	return TrafficStats{
+		Requests: c.requests.Load(),
+		Allowed:  c.allowed.Load(),
+		BytesIn:  c.bytesIn.Load(),
+		BytesOut: c.bytesOut.Load(),
+	}
+}
+
+func (ts *trafficStats) all() map[string]TrafficStats {
+	out := make(map[string]TrafficStats)
+	ts.keys.Range(func(k, v any) bool {
+		c := v.(*trafficCounters)
+		out[k.(string)] = TrafficStats{
+			Requests: c.requests.Load(),
+			Allowed:  c.allowed.Load(),
+			BytesIn:  c.bytesIn.Load(),
+			BytesOut: c.bytesOut.Load(),
+		}
+		return true
+	})
+	return out
+}
+
+// recordTraffic records one request's counters when tracking is enabled.
+func (rl *RateLimiter) recordTraffic(key string, allowed bool, in, out uint64) {
+	if rl.cfg.TrackTraffic {
+		rl.traffic.record(key, allowed, in, out)
+	}
+}
+
+// Traffic returns the accumulated traffic counters for key. All zeros means
+// the key has not been seen (or traffic tracking is disabled).
+func (rl *RateLimiter) Traffic(key string) TrafficStats {
+	return rl.traffic.snapshot(key)
+}
+
+// TrafficSnapshot returns the traffic counters for every key seen since
+// startup. The map is a copy; mutating it does not affect the limiter.
+func (rl *RateLimiter) TrafficSnapshot() map[string]TrafficStats {
+	return rl.traffic.all()
+}
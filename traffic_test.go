@@ -0,0 +1,55 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTrafficAccounting(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, TrackTraffic: true})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+
+	send := func() {
+		req := httptest.NewRequest("POST", "/api", strings.NewReader("0123456789"))
+		req.RemoteAddr = "1.2.3.4:5"
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	for i := 0; i < 3; i++ {
+		send()
+	}
+
+	got := rl.Traffic("1.2.3.4")
+	if got.Requests != 3 || got.Allowed != 2 {
+		t.Fatalf("requests/allowed = %d/%d, want 3/2", got.Requests, got.Allowed)
+	}
+	if got.BytesIn != 30 {
+		t.Fatalf("bytes in = %d, want 30", got.BytesIn)
+	}
+	// Two allowed responses wrote "hello"; the denial wrote an error body.
+	if got.BytesOut <= 10 {
+		t.Fatalf("bytes out = %d, want more than the two handler bodies", got.BytesOut)
+	}
+
+	if snap := rl.TrafficSnapshot(); len(snap) != 1 || snap["1.2.3.4"] != got {
+		t.Fatalf("snapshot = %v", snap)
+	}
+	if other := rl.Traffic("9.9.9.9"); other != (TrafficStats{}) {
+		t.Fatalf("unseen key has counters: %+v", other)
+	}
+}
+
+func TestTrafficAccountingDisabled(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 5, RefillPerSec: 1})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if snap := rl.TrafficSnapshot(); len(snap) != 0 {
+		t.Fatalf("tracking disabled but snapshot has %d keys", len(snap))
+	}
+}
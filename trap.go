@@ -0,0 +1,108 @@
+package signalfence
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTrapBanDuration is how long a key that touched a trap path stays
+// blocklisted when Config.TrapBanDuration is zero.
+const DefaultTrapBanDuration = 10 * time.Minute
+
+// traps holds honeytrap paths and the blocklist of keys that touched them.
+// No legitimate client ever requests a trap path, so a single hit is enough
+// to ban the key.
+type trapList struct {
+	mu    sync.RWMutex
+	paths map[string]struct{}
+	bans  sync.Map // key string -> time.Time (ban expiry)
+}
+
+// AddTrap registers path as a honeytrap. Any key requesting it through the
+// middleware is instantly banned for the configured duration:
+//
+//	limiter.AddTrap("/wp-admin")
+func (rl *RateLimiter) AddTrap(path string) {
+	rl.traps.mu.Lock()
+	defer rl.traps.mu.Unlock()
+	if rl.traps.paths == nil {
+		rl.traps.paths = make(map[string]struct{})
+	}
+	rl.traps.paths[path] = struct{}{}
+}
+
+// RemoveTrap unregisters a honeytrap path.
+func (rl *RateLimiter) RemoveTrap(path string) {
+	rl.traps.mu.Lock()
+	defer rl.traps.mu.Unlock()
+	delete(rl.traps.paths, path)
+}
+
+// isTrap reports whether path is a registered honeytrap.
+func (rl *RateLimiter) isTrap(path string) bool {
+	rl.traps.mu.RLock()
+	defer rl.traps.mu.RUnlock()
+	_, ok := rl.traps.paths[path]
+	return ok
+}
+
+// Ban blocklists key for d. Every decision for a banned key is denied with
+// the remaining ban time as RetryAfter. Registered notifiers (see
+// NotifyBans) are told about the ban.
+func (rl *RateLimiter) Ban(key string, d time.Duration) {
+	now := time.Now()
+	rl.traps.bans.Store(key, now.Add(d))
+	rl.notifyBan(BanEvent{
+		Key:      key,
+		Action:   BanActionBan,
+		Duration: d,
+		Until:    now.Add(d),
+		At:       now,
+	})
+}
+
+// Unban removes key from the blocklist. Notifiers only hear about it when
+// key actually had an active ban, so idempotent cleanup calls stay quiet.
+func (rl *RateLimiter) Unban(key string) {
+	v, loaded := rl.traps.bans.LoadAndDelete(key)
+	if !loaded || time.Now().After(v.(time.Time)) {
+		return
+	}
+	rl.notifyBan(BanEvent{Key: key, Action: BanActionUnban, At: time.Now()})
+}
+
+// Banned reports whether key is currently blocklisted, clearing expired
+// entries lazily.
+func (rl *RateLimiter) Banned(key string) bool {
+	_, banned := rl.banRemaining(key)
+	return banned
+}
+
+// banRemaining returns the time left on key's ban, if any. Keys matching
+// the imported blocklist (see SetBlocklist) count as banned with no expiry.
+func (rl *RateLimiter) banRemaining(key string) (time.Duration, bool) {
+	if rl.blocked.match(key) {
+		return 0, true
+	}
+	v, ok := rl.traps.bans.Load(key)
+	if !ok {
+		return 0, false
+	}
+	until := v.(time.Time)
+	left := time.Until(until)
+	if left <= 0 {
+		rl.traps.bans.Delete(key)
+		return 0, false
+	}
+	return left, true
+}
+
+// trapBanDuration returns the configured ban duration for trap hits.
+func (rl *RateLimiter) trapBanDuration() time.Duration {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	if rl.cfg.TrapBanDuration > 0 {
+		return rl.cfg.TrapBanDuration
+	}
+	return DefaultTrapBanDuration
+}
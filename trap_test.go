@@ -0,0 +1,76 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTrapPathBansKey(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 100})
+	rl.AddTrap("/wp-admin")
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	bot := httptest.NewRequest("GET", "/wp-admin", nil)
+	bot.RemoteAddr = "6.6.6.6:1"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, bot)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("trap hit status = %d, want 429", rec.Code)
+	}
+	if !rl.Banned("6.6.6.6") {
+		t.Fatal("key touching a trap should be banned")
+	}
+
+	// The ban covers every route, not just the trap.
+	api := httptest.NewRequest("GET", "/api/data", nil)
+	api.RemoteAddr = "6.6.6.6:1"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, api)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("banned key status = %d, want 429", rec.Code)
+	}
+
+	// Other clients are unaffected.
+	ok := httptest.NewRequest("GET", "/api/data", nil)
+	ok.RemoteAddr = "7.7.7.7:1"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, ok)
+	if rec.Code != 200 {
+		t.Fatalf("clean key status = %d", rec.Code)
+	}
+}
+
+func TestBanExpires(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10})
+	rl.Ban("k", time.Millisecond)
+	if !rl.Banned("k") {
+		t.Fatal("want banned")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if rl.Banned("k") {
+		t.Fatal("ban should have expired")
+	}
+	if d := rl.Allow("k"); !d.Allowed {
+		t.Fatalf("post-ban decision = %+v", d)
+	}
+}
+
+func TestBannedDecision(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10})
+	rl.Ban("k", time.Minute)
+	d := rl.Allow("k")
+	if d.Allowed || !d.Banned || d.RetryAfter <= 0 {
+		t.Fatalf("decision = %+v", d)
+	}
+}
+
+func TestRemoveTrap(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10})
+	rl.AddTrap("/trap")
+	rl.RemoveTrap("/trap")
+	if rl.isTrap("/trap") {
+		t.Fatal("trap should be removed")
+	}
+}
@@ -0,0 +1,86 @@
+package signalfence
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrustHeader is the header checked for a signed upstream decision: proof
+// that an edge limiter already admitted this request, so internal hops can
+// skip re-checking and spare their stores.
+const TrustHeader = "X-SignalFence-Trusted"
+
+// SignTrust mints a trust token signed with secret under keyID, asserting
+// that clientKey was admitted upstream. The token is bound to the client
+// key, so a token minted for one client cannot be replayed for another,
+// and to an expiry, which should barely outlive one request hop. Secrets
+// come from the same BypassKeys keyring the bypass tokens use, with the
+// same rotation story.
+//
+// The token format is "keyID:expiryUnix:base64url(clientKey):signature"
+// where the signature is base64url(HMAC-SHA256 of the preceding fields).
+func SignTrust(keyID string, secret []byte, clientKey string, expiry time.Time) string {
+	payload := keyID + ":" + strconv.FormatInt(expiry.Unix(), 10) + ":" +
+		base64.RawURLEncoding.EncodeToString([]byte(clientKey))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return payload + ":" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyTrust checks token against the configured keys and the request's
+// client key. It returns the signing key's ID when the token is authentic,
+// unexpired and minted for this client.
+func (rl *RateLimiter) verifyTrust(token, clientKey string) (string, bool) {
+	parts := strings.SplitN(token, ":", 4)
+	if len(parts) != 4 {
+		return "", false
+	}
+	keyID, expiryStr, keyB64, sig := parts[0], parts[1], parts[2], parts[3]
+
+	rl.mu.RLock()
+	secret, ok := rl.cfg.BypassKeys[keyID]
+	rl.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	expiry, err := strconv.ParseInt(expiryStr, 10, 64)
+	if err != nil || time.Now().Unix() > expiry {
+		return "", false
+	}
+	if keyB64 != base64.RawURLEncoding.EncodeToString([]byte(clientKey)) {
+		return "", false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(keyID + ":" + expiryStr + ":" + keyB64))
+	want, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(want, mac.Sum(nil)) {
+		return "", false
+	}
+	return keyID, true
+}
+
+// TrustUpstream wraps next with rate limiting that honors signed upstream
+// decisions: requests carrying a valid TrustHeader token for their client
+// key pass straight through, and everything else goes through Middleware
+// as usual. Edges mint tokens with SignTrust after admitting a request and
+// stamp them onto the proxied hop, so a multi-hop architecture checks each
+// request against the store once instead of at every service.
+func (rl *RateLimiter) TrustUpstream(next http.Handler) http.Handler {
+	limited := rl.Middleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get(TrustHeader); token != "" {
+			if _, ok := rl.verifyTrust(token, rl.keyFunc(r)); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		limited.ServeHTTP(w, r)
+	})
+}
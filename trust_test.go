@@ -0,0 +1,72 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTrustUpstreamSkipsRecheck(t *testing.T) {
+	secret := []byte("edge-secret")
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{
+		Capacity:     1,
+		RefillPerSec: 0.001,
+		Now:          clock.Now,
+		BypassKeys:   map[string][]byte{"edge": secret},
+	})
+	h := rl.TrustUpstream(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.2.2.2:999"
+
+	// Drain the single token.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("untrusted drained request status = %d, want 429", rec.Code)
+	}
+
+	// A valid trust token for this client skips the local check entirely.
+	trusted := req.Clone(req.Context())
+	trusted.Header.Set(TrustHeader, SignTrust("edge", secret, "10.2.2.2", time.Now().Add(time.Minute)))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, trusted)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("trusted request status = %d, want 200", rec.Code)
+	}
+}
+
+func TestTrustTokenRejections(t *testing.T) {
+	secret := []byte("edge-secret")
+	rl := NewRateLimiter(Config{
+		Capacity:     100,
+		RefillPerSec: 100,
+		BypassKeys:   map[string][]byte{"edge": secret},
+	})
+
+	expiry := time.Now().Add(time.Minute)
+	cases := map[string]string{
+		"other client":  SignTrust("edge", secret, "10.9.9.9", expiry),
+		"expired":       SignTrust("edge", secret, "10.2.2.2", time.Now().Add(-time.Minute)),
+		"unknown keyID": SignTrust("rogue", secret, "10.2.2.2", expiry),
+		"wrong secret":  SignTrust("edge", []byte("forged"), "10.2.2.2", expiry),
+		"garbage":       "not:a:token",
+	}
+	for name, token := range cases {
+		if _, ok := rl.verifyTrust(token, "10.2.2.2"); ok {
+			t.Errorf("%s token verified", name)
+		}
+	}
+	if _, ok := rl.verifyTrust(SignTrust("edge", secret, "10.2.2.2", expiry), "10.2.2.2"); !ok {
+		t.Fatal("valid token rejected")
+	}
+}
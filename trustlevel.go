@@ -0,0 +1,37 @@
+package signalfence
+
+// Trust records a key's trust level, applying the policy Config.TrustLevels
+// maps it to: after MFA an application can grant a session 10x limits with
+// one call instead of juggling a second limiter. Level 0 clears the grant.
+// Unknown levels are recorded but have no policy effect until a reload
+// defines them. Escalations and clampdown still apply on top, so a trusted
+// key that turns abusive is reined in like any other.
+func (rl *RateLimiter) Trust(key string, level int) {
+	if level == 0 {
+		rl.trusted.Delete(key)
+		return
+	}
+	rl.trusted.Store(key, level)
+}
+
+// TrustLevel returns the trust level recorded for key, zero when none.
+func (rl *RateLimiter) TrustLevel(key string) int {
+	v, ok := rl.trusted.Load(key)
+	if !ok {
+		return 0
+	}
+	return v.(int)
+}
+
+// trustPolicy returns the policy override for key's trust level, if the
+// key is trusted and the level is mapped.
+func (rl *RateLimiter) trustPolicy(key string) (Policy, bool) {
+	v, ok := rl.trusted.Load(key)
+	if !ok {
+		return Policy{}, false
+	}
+	rl.mu.RLock()
+	pol, ok := rl.cfg.TrustLevels[v.(int)]
+	rl.mu.RUnlock()
+	return pol, ok
+}
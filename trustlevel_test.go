@@ -0,0 +1,50 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrustLevelRelaxesPolicy(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     2,
+		RefillPerSec: 0.001,
+		TrustLevels: map[int]Policy{
+			2: {Capacity: 20},
+		},
+	})
+
+	if d := rl.Peek("session", ""); d.Limit != 2 {
+		t.Fatalf("untrusted limit = %d", d.Limit)
+	}
+	rl.Trust("session", 2)
+	if lvl := rl.TrustLevel("session"); lvl != 2 {
+		t.Fatalf("TrustLevel = %d", lvl)
+	}
+	if d := rl.Allow("session"); d.Limit != 20 {
+		t.Fatalf("trusted limit = %d, want 20", d.Limit)
+	}
+
+	// Level 0 revokes the grant; unmapped levels have no effect.
+	rl.Trust("session", 0)
+	if d := rl.Peek("session", ""); d.Limit != 2 {
+		t.Fatalf("revoked limit = %d", d.Limit)
+	}
+	rl.Trust("session", 9)
+	if d := rl.Peek("session", ""); d.Limit != 2 {
+		t.Fatalf("unmapped level limit = %d", d.Limit)
+	}
+}
+
+func TestTrustDoesNotShieldEscalated(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     2,
+		RefillPerSec: 0.001,
+		TrustLevels:  map[int]Policy{1: {Capacity: 20}},
+	})
+	rl.Trust("session", 1)
+	rl.Escalate("session", Policy{Capacity: 1}, time.Minute)
+	if d := rl.Peek("session", ""); d.Limit != 1 {
+		t.Fatalf("escalated trusted limit = %d, want 1", d.Limit)
+	}
+}
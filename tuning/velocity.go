@@ -0,0 +1,180 @@
+// Package tuning observes each key's achieved request rate against its
+// policy and recommends refill adjustments, replacing manual — and
+// perpetually stale — capacity planning with measurements. Recommendations
+// can be read programmatically, served to the admin API, or auto-applied
+// within configured bounds.
+package tuning
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// Config configures a Tuner. The zero value is usable and observes with
+// one-minute windows without auto-applying anything.
+type Config struct {
+	// Window is how long each measurement window runs before a key's
+	// achieved rate is assessed. Zero means one minute.
+	Window time.Duration
+	// HighWater is the utilization (achieved rate over refill rate) above
+	// which a raise is suggested even without denials. Zero means 0.9.
+	HighWater float64
+	// LowWater is the utilization below which a reduction is suggested.
+	// Zero means 0.1.
+	LowWater float64
+	// Headroom is the multiplier applied to the achieved rate when
+	// suggesting a new refill, so the suggestion leaves room to grow.
+	// Zero means 1.25.
+	Headroom float64
+	// MinRefill and MaxRefill bound every suggestion. MaxRefill zero
+	// disables raises; it must be set deliberately so a runaway client
+	// cannot talk the tuner into an unbounded limit.
+	MinRefill float64
+	MaxRefill float64
+	// AutoApply escalates keys to their suggested refill (bounded as
+	// above) for ApplyTTL instead of only recording the recommendation.
+	AutoApply bool
+	// ApplyTTL is how long an auto-applied adjustment lasts. Zero means
+	// ten minutes.
+	ApplyTTL time.Duration
+	// Now overrides the time source for tests; nil means time.Now.
+	Now func() time.Time
+}
+
+// Recommendation is one key's assessment from its last completed window.
+type Recommendation struct {
+	Key             string  `json:"key"`
+	AchievedPerSec  float64 `json:"achieved_per_sec"`
+	RefillPerSec    float64 `json:"refill_per_sec"`
+	Denied          int     `json:"denied"`
+	SuggestedRefill float64 `json:"suggested_refill"`
+	Applied         bool    `json:"applied"`
+}
+
+// keyWindow accumulates one key's observations in the running window.
+type keyWindow struct {
+	start    time.Time
+	attempts int
+	denied   int
+	last     *Recommendation
+}
+
+// Tuner records per-key velocity and produces refill recommendations.
+type Tuner struct {
+	rl  *signalfence.RateLimiter
+	cfg Config
+
+	mu   sync.Mutex
+	keys map[string]*keyWindow
+}
+
+// New returns a Tuner assessing keys of rl under cfg.
+func New(rl *signalfence.RateLimiter, cfg Config) *Tuner {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.HighWater <= 0 {
+		cfg.HighWater = 0.9
+	}
+	if cfg.LowWater <= 0 {
+		cfg.LowWater = 0.1
+	}
+	if cfg.Headroom <= 0 {
+		cfg.Headroom = 1.25
+	}
+	if cfg.ApplyTTL <= 0 {
+		cfg.ApplyTTL = 10 * time.Minute
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Tuner{rl: rl, cfg: cfg, keys: make(map[string]*keyWindow)}
+}
+
+// Observe records one decision for key. Call it after every Allow so both
+// admitted and denied attempts count toward the achieved rate.
+func (t *Tuner) Observe(key string, d signalfence.Decision) {
+	now := t.cfg.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w, ok := t.keys[key]
+	if !ok {
+		w = &keyWindow{start: now}
+		t.keys[key] = w
+	}
+	if now.Sub(w.start) >= t.cfg.Window {
+		t.assess(key, w, now)
+		w.start, w.attempts, w.denied = now, 0, 0
+	}
+	w.attempts++
+	if !d.Allowed {
+		w.denied++
+	}
+}
+
+// assess closes a key's window: it computes the achieved rate, derives a
+// suggestion, and auto-applies it when configured. Callers must hold mu.
+func (t *Tuner) assess(key string, w *keyWindow, now time.Time) {
+	achieved := float64(w.attempts) / now.Sub(w.start).Seconds()
+	refill := t.rl.EffectivePolicy("").RefillPerSec
+	rec := &Recommendation{
+		Key:             key,
+		AchievedPerSec:  achieved,
+		RefillPerSec:    refill,
+		Denied:          w.denied,
+		SuggestedRefill: refill,
+	}
+	switch {
+	case w.denied > 0 || achieved > t.cfg.HighWater*refill:
+		if s := achieved * t.cfg.Headroom; s > refill && t.cfg.MaxRefill > 0 {
+			if s > t.cfg.MaxRefill {
+				s = t.cfg.MaxRefill
+			}
+			rec.SuggestedRefill = s
+		}
+	case achieved < t.cfg.LowWater*refill:
+		s := achieved * t.cfg.Headroom
+		if s < t.cfg.MinRefill {
+			s = t.cfg.MinRefill
+		}
+		if s < refill {
+			rec.SuggestedRefill = s
+		}
+	}
+	if t.cfg.AutoApply && rec.SuggestedRefill != refill {
+		t.rl.Escalate(key, signalfence.Policy{RefillPerSec: rec.SuggestedRefill}, t.cfg.ApplyTTL)
+		rec.Applied = true
+	}
+	w.last = rec
+}
+
+// Recommendations returns the assessment from each key's last completed
+// window, for keys whose suggestion differs from the current refill.
+func (t *Tuner) Recommendations() []Recommendation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var recs []Recommendation
+	for _, w := range t.keys {
+		if w.last != nil && w.last.SuggestedRefill != w.last.RefillPerSec {
+			recs = append(recs, *w.last)
+		}
+	}
+	return recs
+}
+
+// Handler serves the current recommendations as JSON, for mounting next to
+// the limiter's admin endpoints.
+func (t *Tuner) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recs := t.Recommendations()
+		if recs == nil {
+			recs = []Recommendation{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(recs)
+	})
+}
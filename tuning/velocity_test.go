@@ -0,0 +1,110 @@
+package tuning
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// testClock is a settable time source for window control.
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+func newTestClock() *testClock               { return &testClock{now: time.Unix(1700000000, 0)} }
+
+func TestTunerSuggestsRaiseForStarvedKey(t *testing.T) {
+	clock := newTestClock()
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 10, RefillPerSec: 10})
+	tuner := New(rl, Config{Window: 10 * time.Second, MaxRefill: 50, Now: clock.Now})
+
+	// 150 attempts in a 10s window is 15/s against a 10/s refill, with
+	// denials to show the key is starved.
+	for i := 0; i < 150; i++ {
+		tuner.Observe("user:hot", signalfence.Decision{Allowed: i < 100})
+	}
+	clock.Advance(10 * time.Second)
+	tuner.Observe("user:hot", signalfence.Decision{Allowed: true})
+
+	recs := tuner.Recommendations()
+	if len(recs) != 1 {
+		t.Fatalf("recommendations = %+v, want one", recs)
+	}
+	rec := recs[0]
+	if rec.Key != "user:hot" || rec.AchievedPerSec != 15 || rec.Denied != 50 {
+		t.Fatalf("recommendation = %+v", rec)
+	}
+	if want := 15 * 1.25; rec.SuggestedRefill != want {
+		t.Fatalf("suggested refill = %v, want %v", rec.SuggestedRefill, want)
+	}
+	if rec.Applied {
+		t.Fatal("suggestion applied without AutoApply")
+	}
+}
+
+func TestTunerBoundsAndAutoApply(t *testing.T) {
+	clock := newTestClock()
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 10, RefillPerSec: 10})
+	tuner := New(rl, Config{
+		Window:    10 * time.Second,
+		MaxRefill: 12,
+		AutoApply: true,
+		Now:       clock.Now,
+	})
+
+	for i := 0; i < 300; i++ {
+		tuner.Observe("user:hot", signalfence.Decision{})
+	}
+	clock.Advance(10 * time.Second)
+	tuner.Observe("user:hot", signalfence.Decision{Allowed: true})
+
+	recs := tuner.Recommendations()
+	if len(recs) != 1 || recs[0].SuggestedRefill != 12 {
+		t.Fatalf("suggestion not capped at MaxRefill: %+v", recs)
+	}
+	if !recs[0].Applied {
+		t.Fatal("suggestion not auto-applied")
+	}
+	if !rl.Escalated("user:hot") {
+		t.Fatal("auto-apply did not escalate the key")
+	}
+}
+
+func TestTunerSuggestsReductionForIdleKey(t *testing.T) {
+	clock := newTestClock()
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 100, RefillPerSec: 100})
+	tuner := New(rl, Config{Window: 10 * time.Second, MinRefill: 1, Now: clock.Now})
+
+	// Five attempts in 10s is 0.5/s against 100/s: far below the low water.
+	for i := 0; i < 5; i++ {
+		tuner.Observe("user:idle", signalfence.Decision{Allowed: true})
+	}
+	clock.Advance(10 * time.Second)
+	tuner.Observe("user:idle", signalfence.Decision{Allowed: true})
+
+	recs := tuner.Recommendations()
+	if len(recs) != 1 {
+		t.Fatalf("recommendations = %+v, want one", recs)
+	}
+	if got := recs[0].SuggestedRefill; got >= 100 || got < 1 {
+		t.Fatalf("suggested refill = %v, want a bounded reduction", got)
+	}
+}
+
+func TestTunerHandler(t *testing.T) {
+	rl := signalfence.NewRateLimiter(signalfence.Config{Capacity: 10, RefillPerSec: 10})
+	tuner := New(rl, Config{})
+
+	rec := httptest.NewRecorder()
+	tuner.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/velocity", nil))
+	var recs []Recommendation
+	if err := json.Unmarshal(rec.Body.Bytes(), &recs); err != nil {
+		t.Fatalf("handler body %q: %v", rec.Body.String(), err)
+	}
+	if len(recs) != 0 {
+		t.Fatalf("fresh tuner served %+v", recs)
+	}
+}
@@ -0,0 +1,67 @@
+package signalfence
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTypedLimiterInt64Keys(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, Now: clock.Now})
+	users := Typed[int64](rl, "user", Int64Key)
+
+	users.Allow(42)
+	users.Allow(42)
+	if d := users.Allow(42); d.Allowed {
+		t.Fatalf("user 42 over capacity: %+v", d)
+	}
+	if d := users.Allow(7); !d.Allowed {
+		t.Fatalf("fresh user denied: %+v", d)
+	}
+
+	users.Ban(42, time.Minute)
+	if !users.Banned(42) {
+		t.Fatal("typed ban not visible")
+	}
+	// The ban landed on the namespaced key of the shared limiter.
+	if !rl.Banned("user:42") {
+		t.Fatal("ban missing from the underlying limiter")
+	}
+}
+
+func TestTypedLimiterClassesDoNotCollide(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001, Now: clock.Now})
+	users := Typed[int64](rl, "user", Int64Key)
+	orgs := Typed[int64](rl, "org", Int64Key)
+
+	users.Allow(1)
+	// The same numeric ID in another class has its own bucket.
+	if d := orgs.Allow(1); !d.Allowed {
+		t.Fatalf("org 1 shared user 1's bucket: %+v", d)
+	}
+	if d := users.Allow(1); d.Allowed {
+		t.Fatal("user 1 not limited after its take")
+	}
+}
+
+func TestTypedLimiterTupleKeys(t *testing.T) {
+	type tenantUser struct {
+		Tenant string
+		User   int64
+	}
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 0.001, Now: clock.Now})
+	tl := Typed(rl, "tu", func(k tenantUser) string {
+		return k.Tenant + "/" + strconv.FormatInt(k.User, 10)
+	})
+
+	tl.Allow(tenantUser{"acme", 1})
+	if d := tl.Allow(tenantUser{"acme", 1}); d.Allowed {
+		t.Fatal("tuple key not limited")
+	}
+	if d := tl.Allow(tenantUser{"globex", 1}); !d.Allowed {
+		t.Fatalf("distinct tenant collided: %+v", d)
+	}
+}
@@ -0,0 +1,79 @@
+package signalfence
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate reports configuration mistakes that NewRateLimiter would
+// otherwise paper over with defaults or silent clamping: negative limits,
+// thresholds outside their range, a hook that can never fire. It returns
+// all problems joined, so a config review fixes everything in one pass.
+// NewRateLimiter does not call it — zero values are legitimate there — so
+// callers that want loud failures validate explicitly, as cmd/server does
+// at startup.
+func (cfg Config) Validate() error {
+	var errs []error
+	errs = append(errs, validatePolicy("default policy", Policy{
+		Capacity:      cfg.Capacity,
+		RefillPerSec:  cfg.RefillPerSec,
+		WarnThreshold: cfg.WarnThreshold,
+		Grace:         cfg.Grace,
+	})...)
+	for route, pol := range cfg.Routes {
+		if route == "" {
+			errs = append(errs, errors.New(`route "" duplicates the default policy; set Capacity and RefillPerSec instead`))
+		}
+		errs = append(errs, validatePolicy(fmt.Sprintf("route %q", route), pol)...)
+	}
+	if cfg.ReadDefaults != nil {
+		errs = append(errs, validatePolicy("read defaults", *cfg.ReadDefaults)...)
+	}
+	if cfg.WriteDefaults != nil {
+		errs = append(errs, validatePolicy("write defaults", *cfg.WriteDefaults)...)
+	}
+	for class, pol := range cfg.KeyClasses {
+		if class == "" || class[len(class)-1] != ':' {
+			errs = append(errs, fmt.Errorf("key class %q must be a prefix ending in ':'", class))
+		}
+		errs = append(errs, validatePolicy(fmt.Sprintf("key class %q", class), pol)...)
+	}
+	for route, can := range cfg.Canaries {
+		if can.Percent < 0 || can.Percent > 100 {
+			errs = append(errs, fmt.Errorf("canary for %q: percent %d out of range", route, can.Percent))
+		}
+		errs = append(errs, validatePolicy(fmt.Sprintf("canary for %q", route), can.Policy)...)
+	}
+	for route, pol := range cfg.Shadows {
+		errs = append(errs, validatePolicy(fmt.Sprintf("shadow for %q", route), pol)...)
+	}
+	if cfg.MaxConfigHistory < 0 {
+		errs = append(errs, errors.New("max config history is negative"))
+	}
+	if cfg.TrapBanDuration < 0 {
+		errs = append(errs, errors.New("trap ban duration is negative"))
+	}
+	if cfg.OnBypass != nil && len(cfg.BypassKeys) == 0 {
+		errs = append(errs, errors.New("OnBypass is set but BypassKeys is empty, so no bypass token can ever verify"))
+	}
+	return errors.Join(errs...)
+}
+
+// validatePolicy collects the range errors for one policy, labelled with
+// where it came from.
+func validatePolicy(where string, p Policy) []error {
+	var errs []error
+	if p.Capacity < 0 {
+		errs = append(errs, fmt.Errorf("%s: capacity is negative", where))
+	}
+	if p.RefillPerSec < 0 {
+		errs = append(errs, fmt.Errorf("%s: refill rate is negative", where))
+	}
+	if p.WarnThreshold < 0 || p.WarnThreshold > 1 {
+		errs = append(errs, fmt.Errorf("%s: warn threshold %v outside (0, 1]", where, p.WarnThreshold))
+	}
+	if p.Grace < 0 {
+		errs = append(errs, fmt.Errorf("%s: grace is negative", where))
+	}
+	return errs
+}
@@ -0,0 +1,55 @@
+package signalfence
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAcceptsGoodConfig(t *testing.T) {
+	cfg := Config{
+		Capacity:      100,
+		RefillPerSec:  10,
+		WarnThreshold: 0.8,
+		Routes:        map[string]Policy{"/search": {Capacity: 5, RefillPerSec: 1}},
+		Canaries:      map[string]Canary{"/search": {Percent: 10, Policy: Policy{Capacity: 3}}},
+		Shadows:       map[string]Policy{"": {Capacity: 50, RefillPerSec: 5}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate = %v", err)
+	}
+	// The zero config is legitimate: NewRateLimiter fills defaults.
+	if err := (Config{}).Validate(); err != nil {
+		t.Fatalf("zero config Validate = %v", err)
+	}
+}
+
+func TestValidateCollectsAllProblems(t *testing.T) {
+	cfg := Config{
+		Capacity:      -1,
+		WarnThreshold: 2,
+		Routes: map[string]Policy{
+			"":        {Capacity: 5},
+			"/search": {RefillPerSec: -1},
+		},
+		Canaries:         map[string]Canary{"/x": {Percent: 150}},
+		MaxConfigHistory: -1,
+		OnBypass:         func(keyID, clientKey, route string) {},
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("bad config validated clean")
+	}
+	for _, want := range []string{
+		"capacity is negative",
+		"warn threshold",
+		`route ""`,
+		`route "/search": refill rate is negative`,
+		"percent 150 out of range",
+		"max config history is negative",
+		"OnBypass is set but BypassKeys is empty",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+}
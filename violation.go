@@ -0,0 +1,157 @@
+package signalfence
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Defaults for ViolationConfig's zero fields.
+const (
+	DefaultViolationHalfLife    = 10 * time.Minute
+	DefaultViolationEscalateAt  = 1.0
+	DefaultViolationBanAt       = 3.0
+	DefaultViolationEscalateTTL = 10 * time.Minute
+)
+
+// ViolationConfig tunes how externally reported violations (see
+// ReportViolation) translate into limits and bans.
+type ViolationConfig struct {
+	// HalfLife is how fast a key's accumulated violation score decays:
+	// after one half-life, half the score remains. Zero means
+	// DefaultViolationHalfLife.
+	HalfLife time.Duration
+	// EscalateAt is the score at which the key is escalated. Zero means
+	// DefaultViolationEscalateAt.
+	EscalateAt float64
+	// EscalatePolicy is the override applied at EscalateAt; its zero
+	// fields inherit as usual. A zero policy means Capacity 1 with a slow
+	// refill.
+	EscalatePolicy Policy
+	// EscalateTTL is how long the escalation lasts. Zero means
+	// DefaultViolationEscalateTTL.
+	EscalateTTL time.Duration
+	// BanAt is the score at which the key is banned outright. Zero means
+	// DefaultViolationBanAt.
+	BanAt float64
+	// BanDuration is the ban length at BanAt. Zero means the limiter's
+	// trap ban duration.
+	BanDuration time.Duration
+}
+
+// violationState is one key's decaying violation score.
+type violationState struct {
+	score float64
+	at    time.Time // when score was last brought current
+}
+
+// violationCounters tracks decaying violation scores per key.
+type violationCounters struct {
+	mu   sync.Mutex
+	keys map[string]*violationState
+}
+
+// add brings key's score current at now, adds severity, and returns the
+// new score.
+func (v *violationCounters) add(key string, severity float64, halfLife time.Duration, now time.Time) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		v.keys = make(map[string]*violationState)
+	}
+	st, ok := v.keys[key]
+	if !ok {
+		st = &violationState{}
+		v.keys[key] = st
+	}
+	st.score = decayScore(st.score, now.Sub(st.at), halfLife) + severity
+	st.at = now
+	return st.score
+}
+
+// current returns key's score decayed to now, dropping entries that have
+// decayed to noise.
+func (v *violationCounters) current(key string, halfLife time.Duration, now time.Time) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	st, ok := v.keys[key]
+	if !ok {
+		return 0
+	}
+	score := decayScore(st.score, now.Sub(st.at), halfLife)
+	if score < 0.01 {
+		delete(v.keys, key)
+		return 0
+	}
+	return score
+}
+
+// forget drops key's violation score outright.
+func (v *violationCounters) forget(key string) {
+	v.mu.Lock()
+	delete(v.keys, key)
+	v.mu.Unlock()
+}
+
+// decayScore applies exponential decay over elapsed with the given
+// half-life.
+func decayScore(score float64, elapsed, halfLife time.Duration) float64 {
+	if elapsed <= 0 || score == 0 {
+		return score
+	}
+	return score * math.Exp2(-float64(elapsed)/float64(halfLife))
+}
+
+// ReportViolation feeds an external detector's verdict — a WAF rule hit, an
+// IDS alert — into the limiter. Severity is on a 0-to-1 scale per incident;
+// severities accumulate per key and decay with the configured half-life, so
+// a burst of rule hits tightens limits while a lone stale one fades out.
+// Crossing Config.Violations.EscalateAt applies the escalation policy;
+// crossing BanAt bans the key outright. The current score is also exposed
+// to AbuseScore as the "violations" signal, normalized against BanAt.
+func (rl *RateLimiter) ReportViolation(key string, severity float64) {
+	if severity <= 0 {
+		return
+	}
+	cfg := rl.violationConfig()
+	score := rl.violations.add(key, severity, cfg.HalfLife, time.Now())
+	switch {
+	case score >= cfg.BanAt:
+		rl.Ban(key, cfg.BanDuration)
+	case score >= cfg.EscalateAt:
+		rl.Escalate(key, cfg.EscalatePolicy, cfg.EscalateTTL)
+	}
+}
+
+// ViolationScore returns key's current decayed violation score.
+func (rl *RateLimiter) ViolationScore(key string) float64 {
+	cfg := rl.violationConfig()
+	return rl.violations.current(key, cfg.HalfLife, time.Now())
+}
+
+// violationConfig returns the configured violation tuning with zero fields
+// defaulted.
+func (rl *RateLimiter) violationConfig() ViolationConfig {
+	rl.mu.RLock()
+	cfg := rl.cfg.Violations
+	rl.mu.RUnlock()
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = DefaultViolationHalfLife
+	}
+	if cfg.EscalateAt <= 0 {
+		cfg.EscalateAt = DefaultViolationEscalateAt
+	}
+	if cfg.BanAt <= 0 {
+		cfg.BanAt = DefaultViolationBanAt
+	}
+	if cfg.EscalateTTL <= 0 {
+		cfg.EscalateTTL = DefaultViolationEscalateTTL
+	}
+	if cfg.BanDuration <= 0 {
+		cfg.BanDuration = rl.trapBanDuration()
+	}
+	if cfg.EscalatePolicy == (Policy{}) {
+		cfg.EscalatePolicy = Policy{Capacity: 1, RefillPerSec: 0.1}
+	}
+	return cfg
+}
@@ -0,0 +1,70 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportViolationEscalatesThenBans(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+
+	rl.ReportViolation("k", 0.4)
+	if rl.Escalated("k") || rl.Banned("k") {
+		t.Fatal("sub-threshold violation escalated")
+	}
+
+	rl.ReportViolation("k", 0.7)
+	if !rl.Escalated("k") {
+		t.Fatal("score past EscalateAt should escalate")
+	}
+	if rl.Banned("k") {
+		t.Fatal("banned before BanAt")
+	}
+
+	rl.ReportViolation("k", 1)
+	rl.ReportViolation("k", 1)
+	if !rl.Banned("k") {
+		t.Fatal("score past BanAt should ban")
+	}
+}
+
+func TestViolationScoreDecays(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		Capacity:     10,
+		RefillPerSec: 1,
+		Violations:   ViolationConfig{HalfLife: time.Minute},
+	})
+
+	rl.ReportViolation("k", 0.8)
+	if s := rl.ViolationScore("k"); s < 0.79 || s > 0.81 {
+		t.Fatalf("fresh score = %v", s)
+	}
+
+	// Age the recorded state by two half-lives.
+	rl.violations.mu.Lock()
+	rl.violations.keys["k"].at = time.Now().Add(-2 * time.Minute)
+	rl.violations.mu.Unlock()
+	if s := rl.ViolationScore("k"); s < 0.15 || s > 0.25 {
+		t.Fatalf("score after two half-lives = %v, want ~0.2", s)
+	}
+}
+
+func TestViolationFeedsAbuseScore(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	rl.ReportViolation("k", 0.6)
+	if s := rl.AbuseScore("k"); s <= 0 {
+		t.Fatalf("abuse score = %v, want > 0 from violations signal", s)
+	}
+	if rl.AbuseScore("clean") != 0 {
+		t.Fatal("clean key scored")
+	}
+}
+
+func TestReportViolationIgnoresNonPositive(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 1})
+	rl.ReportViolation("k", 0)
+	rl.ReportViolation("k", -1)
+	if rl.ViolationScore("k") != 0 {
+		t.Fatal("non-positive severities recorded")
+	}
+}
@@ -0,0 +1,207 @@
+package signalfence
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// waitAging is the starvation protection for priority waiting: every full
+// interval spent queued raises a waiter's effective priority by one level,
+// so a steady stream of high-priority arrivals cannot park low-priority
+// work forever.
+const waitAging = time.Second
+
+// waiter is one blocked WaitPriority call.
+type waiter struct {
+	priority int
+	seq      uint64 // FIFO tiebreak among equal priorities
+	enqueued time.Time
+	ready    chan struct{}
+	canceled atomic.Bool
+}
+
+// effective is the waiter's priority after aging at time now.
+func (w *waiter) effective(now time.Time) int {
+	return w.priority + int(now.Sub(w.enqueued)/waitAging)
+}
+
+// waitQueue orders the blocked waiters for one key. Queues are expected to
+// stay short, so selection is a linear scan rather than a heap.
+type waitQueue struct {
+	mu      sync.Mutex
+	waiters []*waiter
+	seq     uint64
+	running bool
+}
+
+// pop removes and returns the waiter with the highest effective priority
+// (earliest arrival breaking ties), dropping canceled waiters on the way.
+// It returns nil — and marks the dispatcher as stopped — when the queue is
+// empty.
+func (q *waitQueue) pop(now time.Time) *waiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	live := q.waiters[:0]
+	var best *waiter
+	bestIdx := -1
+	for _, w := range q.waiters {
+		if w.canceled.Load() {
+			continue
+		}
+		live = append(live, w)
+		if best == nil {
+			best, bestIdx = w, len(live)-1
+			continue
+		}
+		we, be := w.effective(now), best.effective(now)
+		if we > be || (we == be && w.seq < best.seq) {
+			best, bestIdx = w, len(live)-1
+		}
+	}
+	if best == nil {
+		q.waiters = live
+		q.running = false
+		return nil
+	}
+	q.waiters = append(live[:bestIdx], live[bestIdx+1:]...)
+	return best
+}
+
+// DeadlineError reports that Wait declined without queueing because the
+// bucket cannot afford a token before the request's context deadline.
+// Failing fast spares the server from holding a doomed request; HTTP
+// handlers should map it to 429 with RetryAfter as the Retry-After header.
+type DeadlineError struct {
+	// RetryAfter is the estimated wait until the take would succeed. It is
+	// zero when the bucket does not refill and the wait is unbounded.
+	RetryAfter time.Duration
+}
+
+func (e *DeadlineError) Error() string {
+	if e.RetryAfter <= 0 {
+		return "signalfence: wait would exceed deadline"
+	}
+	return "signalfence: wait of " + e.RetryAfter.String() + " would exceed deadline"
+}
+
+// waitEstimate estimates how long a new waiter behind ahead queued waiters
+// would wait for a token for key, based on the current balance and refill
+// rate. A negative result means the wait is unbounded.
+func (rl *RateLimiter) waitEstimate(key string, ahead int) time.Duration {
+	pol := rl.EffectivePolicy("")
+	if escPol, ok := rl.escalated.get(key); ok {
+		pol = mergePolicy(escPol, pol)
+	}
+	pol = rl.clampPolicy(pol)
+	pol = rl.warmupPolicy(pol)
+	b := rl.bucketFor(key, pol)
+	b.EnsureLimits(float64(pol.Capacity), pol.RefillPerSec, float64(pol.Grace))
+	need := float64(ahead+1) - b.Peek(rl.now())
+	if need <= 0 {
+		return 0
+	}
+	if pol.RefillPerSec <= 0 {
+		return -1
+	}
+	return time.Duration(need / pol.RefillPerSec * float64(time.Second))
+}
+
+// Wait blocks until one token is available for key under the default
+// policy, then consumes it. It is WaitPriority at priority zero.
+func (rl *RateLimiter) Wait(ctx context.Context, key string) error {
+	return rl.WaitPriority(ctx, key, 0)
+}
+
+// WaitPriority blocks until one token is available for key and this call's
+// turn has come. Waiters are served by priority — higher values jump the
+// queue — not plain FIFO, with equal priorities served in arrival order.
+// Every second spent queued raises a waiter's effective priority by one
+// level, so low-priority work cannot be starved indefinitely. It returns
+// nil once a token has been consumed, or the context error if ctx ends
+// first (a token acquired in that window is forfeited).
+//
+// When ctx carries a deadline and the estimated wait — current deficit plus
+// the waiters already queued, at the current refill rate — cannot finish in
+// time, WaitPriority fails fast with a *DeadlineError instead of queueing.
+func (rl *RateLimiter) WaitPriority(ctx context.Context, key string, priority int) error {
+	qv, ok := rl.waits.Load(key)
+	if !ok {
+		qv, _ = rl.waits.LoadOrStore(key, &waitQueue{})
+	}
+	q := qv.(*waitQueue)
+
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		q.mu.Lock()
+		ahead := 0
+		for _, queued := range q.waiters {
+			if !queued.canceled.Load() {
+				ahead++
+			}
+		}
+		q.mu.Unlock()
+		wait := rl.waitEstimate(key, ahead)
+		if wait < 0 {
+			return &DeadlineError{}
+		}
+		if wait > time.Until(deadline) {
+			return &DeadlineError{RetryAfter: wait}
+		}
+	}
+
+	w := &waiter{priority: priority, enqueued: rl.now(), ready: make(chan struct{})}
+	q.mu.Lock()
+	w.seq = q.seq
+	q.seq++
+	q.waiters = append(q.waiters, w)
+	start := !q.running
+	if start {
+		q.running = true
+	}
+	q.mu.Unlock()
+	if start {
+		go rl.dispatch(key, q)
+	}
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		w.canceled.Store(true)
+		return ctx.Err()
+	case <-rl.bgCtx.Done():
+		w.canceled.Store(true)
+		return context.Canceled
+	}
+}
+
+// dispatch serves a key's wait queue: it repeatedly picks the best waiter,
+// blocks until the bucket affords a token, and releases the waiter.
+func (rl *RateLimiter) dispatch(key string, q *waitQueue) {
+	for {
+		w := q.pop(rl.now())
+		if w == nil {
+			return
+		}
+		acquired := false
+		for !w.canceled.Load() {
+			ok, retry := rl.paceTake(key)
+			if ok {
+				acquired = true
+				break
+			}
+			if retry <= 0 {
+				retry = time.Millisecond
+			}
+			select {
+			case <-time.After(retry):
+			case <-rl.bgCtx.Done():
+				return
+			}
+		}
+		if acquired {
+			close(w.ready)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package signalfence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitQueueOrdering(t *testing.T) {
+	q := &waitQueue{}
+	t0 := time.Now()
+	low := &waiter{priority: 0, seq: 0, enqueued: t0}
+	lowLater := &waiter{priority: 0, seq: 1, enqueued: t0}
+	high := &waiter{priority: 5, seq: 2, enqueued: t0}
+	q.waiters = []*waiter{low, lowLater, high}
+
+	// Priority beats arrival order; equal priorities are FIFO.
+	if got := q.pop(t0); got != high {
+		t.Fatalf("first pop = %+v, want the high-priority waiter", got)
+	}
+	if got := q.pop(t0); got != low {
+		t.Fatalf("second pop = %+v, want the earliest low-priority waiter", got)
+	}
+	if got := q.pop(t0); got != lowLater {
+		t.Fatalf("third pop = %+v, want the remaining waiter", got)
+	}
+	if got := q.pop(t0); got != nil {
+		t.Fatalf("pop of empty queue = %+v, want nil", got)
+	}
+	if q.running {
+		t.Fatal("empty pop did not mark the dispatcher stopped")
+	}
+}
+
+func TestWaitQueueAgingAndCancel(t *testing.T) {
+	q := &waitQueue{}
+	t0 := time.Now()
+	old := &waiter{priority: 0, seq: 0, enqueued: t0}
+	fresh := &waiter{priority: 3, seq: 1, enqueued: t0.Add(5 * time.Second)}
+	gone := &waiter{priority: 9, seq: 2, enqueued: t0}
+	gone.canceled.Store(true)
+	q.waiters = []*waiter{old, fresh, gone}
+
+	// After five seconds queued the old waiter has aged five levels,
+	// outranking the fresh priority-3 arrival; canceled waiters are skipped.
+	if got := q.pop(t0.Add(5 * time.Second)); got != old {
+		t.Fatalf("pop = %+v, want the aged low-priority waiter", got)
+	}
+	if got := q.pop(t0.Add(5 * time.Second)); got != fresh {
+		t.Fatalf("pop = %+v, want the fresh waiter", got)
+	}
+	if got := q.pop(t0.Add(5 * time.Second)); got != nil {
+		t.Fatalf("pop = %+v, want nil after the canceled waiter is dropped", got)
+	}
+}
+
+func TestWaitPriorityJumpsQueue(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 10, RefillPerSec: 10, Now: clock.Now})
+	defer rl.Close()
+
+	for i := 0; i < 10; i++ {
+		rl.Allow("user:w")
+	}
+
+	order := make(chan string, 3)
+	wait := func(name string, priority int) {
+		if err := rl.WaitPriority(context.Background(), "user:w", priority); err != nil {
+			t.Errorf("WaitPriority(%s) = %v", name, err)
+			return
+		}
+		order <- name
+	}
+
+	// The first waiter is picked up immediately and blocks on the empty
+	// bucket; the next two queue behind it.
+	go wait("first", 0)
+	time.Sleep(20 * time.Millisecond)
+	go wait("low", 0)
+	time.Sleep(20 * time.Millisecond)
+	go wait("high", 5)
+	time.Sleep(20 * time.Millisecond)
+
+	// Release one token per step so completions are observed one at a time.
+	want := []string{"first", "high", "low"}
+	for _, name := range want {
+		clock.Advance(100 * time.Millisecond)
+		select {
+		case got := <-order:
+			if got != name {
+				t.Fatalf("completion order: got %q, want %q", got, name)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q to complete", name)
+		}
+	}
+}
+
+func TestWaitImmediateAndCancel(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 0.001, Now: clock.Now})
+	defer rl.Close()
+
+	// With tokens available Wait returns promptly.
+	if err := rl.Wait(context.Background(), "user:w"); err != nil {
+		t.Fatalf("Wait with tokens available = %v", err)
+	}
+	rl.Allow("user:w")
+
+	// With the bucket drained and the clock frozen, the context ends first.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	if err := rl.Wait(ctx, "user:w"); err != context.Canceled {
+		t.Fatalf("Wait on drained bucket = %v, want canceled", err)
+	}
+}
+
+func TestWaitDeadlineFailsFast(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 1, RefillPerSec: 1, Now: clock.Now})
+	defer rl.Close()
+	rl.Allow("user:w")
+
+	// The bucket needs a full second to afford a token; a 100ms deadline is
+	// doomed, so Wait declines immediately with the accurate retry hint.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := rl.Wait(ctx, "user:w")
+	var de *DeadlineError
+	if !errors.As(err, &de) {
+		t.Fatalf("Wait with doomed deadline = %v, want *DeadlineError", err)
+	}
+	if de.RetryAfter < 900*time.Millisecond || de.RetryAfter > 1100*time.Millisecond {
+		t.Fatalf("RetryAfter = %v, want about 1s", de.RetryAfter)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Fatal("deadline check queued instead of failing fast")
+	}
+
+	// A generous deadline queues normally and completes once tokens refill.
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+	done := make(chan error, 1)
+	go func() { done <- rl.Wait(ctx2, "user:w") }()
+	time.Sleep(20 * time.Millisecond)
+	clock.Advance(time.Second)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Wait with generous deadline = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait did not complete after refill")
+	}
+}
+
+func TestWaitEstimateCountsQueue(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewRateLimiter(Config{Capacity: 2, RefillPerSec: 1, Now: clock.Now})
+	rl.Allow("user:w")
+	rl.Allow("user:w")
+
+	// Empty bucket, two waiters ahead: three tokens at one per second.
+	if got := rl.waitEstimate("user:w", 2); got != 3*time.Second {
+		t.Fatalf("waitEstimate = %v, want 3s", got)
+	}
+	// A full bucket with no one ahead waits not at all.
+	if got := rl.waitEstimate("user:fresh", 0); got != 0 {
+		t.Fatalf("waitEstimate on full bucket = %v, want 0", got)
+	}
+}
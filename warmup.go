@@ -0,0 +1,60 @@
+package signalfence
+
+import (
+	"sync"
+	"time"
+)
+
+// warmupStartFraction is the fraction of each policy in effect the moment
+// a warm-up begins; limits ramp linearly from here to 100%.
+const warmupStartFraction = 0.1
+
+// warmup ramps effective limits up after process start.
+type warmup struct {
+	mu    sync.RWMutex
+	start time.Time
+	dur   time.Duration
+}
+
+// WithWarmup makes every policy ramp linearly from 10% to 100% of its
+// configured limits over d, starting now. A fresh instance joining a pool
+// therefore sees load grow gradually instead of absorbing the full allowed
+// rate against cold caches and empty connection pools. It returns rl for
+// chaining:
+//
+//	limiter := signalfence.NewRateLimiter(cfg).WithWarmup(30 * time.Second)
+func (rl *RateLimiter) WithWarmup(d time.Duration) *RateLimiter {
+	if d <= 0 {
+		return rl
+	}
+	rl.warm.mu.Lock()
+	rl.warm.start = time.Now()
+	rl.warm.dur = d
+	rl.warm.mu.Unlock()
+	return rl
+}
+
+// warmupFactor returns the current ramp factor, if a warm-up is active.
+func (rl *RateLimiter) warmupFactor() (float64, bool) {
+	rl.warm.mu.RLock()
+	start, dur := rl.warm.start, rl.warm.dur
+	rl.warm.mu.RUnlock()
+	if dur <= 0 {
+		return 1, false
+	}
+	elapsed := time.Since(start)
+	if elapsed >= dur {
+		return 1, false
+	}
+	frac := warmupStartFraction + (1-warmupStartFraction)*(float64(elapsed)/float64(dur))
+	return frac, true
+}
+
+// warmupPolicy scales pol by the current warm-up factor.
+func (rl *RateLimiter) warmupPolicy(pol Policy) Policy {
+	factor, active := rl.warmupFactor()
+	if !active {
+		return pol
+	}
+	return scalePolicy(pol, factor)
+}
@@ -0,0 +1,39 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWarmupRampsLimits(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10}).WithWarmup(time.Hour)
+
+	// Immediately after start the effective limit is ~10% of capacity.
+	d := rl.Allow("k")
+	if d.Limit < 10 || d.Limit > 15 {
+		t.Fatalf("warm-up limit = %d, want ~10", d.Limit)
+	}
+
+	// Halfway through, the factor should be ~55%.
+	rl.warm.mu.Lock()
+	rl.warm.start = time.Now().Add(-30 * time.Minute)
+	rl.warm.mu.Unlock()
+	if d := rl.Allow("k"); d.Limit < 50 || d.Limit > 60 {
+		t.Fatalf("mid-ramp limit = %d, want ~55", d.Limit)
+	}
+}
+
+func TestWarmupEndsAtFullLimits(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10}).WithWarmup(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if d := rl.Allow("k"); d.Limit != 100 {
+		t.Fatalf("post-warm-up limit = %d, want 100", d.Limit)
+	}
+}
+
+func TestZeroWarmupIsNoop(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 100, RefillPerSec: 10}).WithWarmup(0)
+	if d := rl.Allow("k"); d.Limit != 100 {
+		t.Fatalf("limit = %d, want 100", d.Limit)
+	}
+}
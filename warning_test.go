@@ -0,0 +1,53 @@
+package signalfence
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWarnThresholdMarksDecisions(t *testing.T) {
+	var hooked []Decision
+	rl := NewRateLimiter(Config{
+		Capacity:      10,
+		RefillPerSec:  0.001,
+		WarnThreshold: 0.8,
+		OnWarning: func(key, route string, d Decision) {
+			hooked = append(hooked, d)
+		},
+	})
+
+	// Requests 1-8 consume up to the 80% threshold; the 8th decision is the
+	// first to have crossed it.
+	for i := 1; i <= 7; i++ {
+		if d := rl.Allow("k"); d.Warning {
+			t.Fatalf("request %d warned early: %+v", i, d)
+		}
+	}
+	if d := rl.Allow("k"); !d.Warning || !d.Allowed {
+		t.Fatalf("8th request = %+v, want allowed with warning", d)
+	}
+	if len(hooked) != 1 {
+		t.Fatalf("OnWarning fired %d times, want 1", len(hooked))
+	}
+}
+
+func TestMiddlewareSetsWarningHeader(t *testing.T) {
+	rl := NewRateLimiter(Config{Capacity: 4, RefillPerSec: 0.001, WarnThreshold: 0.5})
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "1.2.3.4:5"
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("X-RateLimit-Warning") != "" {
+		t.Fatal("first request should not warn")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("X-RateLimit-Warning") == "" {
+		t.Fatal("second request crossed 50% threshold, want warning header")
+	}
+}
@@ -0,0 +1,97 @@
+package signalfence
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence/core"
+)
+
+// defaultWindowMaxKeys caps how many keys a WindowLimiter tracks before it
+// starts evicting the least recently seen ones.
+const defaultWindowMaxKeys = 65536
+
+// WindowLimiter applies an exact sliding-window-log limit per key. Use it
+// instead of RateLimiter for very small limits where token approximation is
+// not acceptable — "3 password reset emails per hour" must mean never more
+// than 3 in any rolling hour. Each key costs O(limit) memory; aged-out
+// entries are pruned automatically and the total key count is capped, with
+// the least recently seen keys evicted first.
+type WindowLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	maxKeys int
+	keys    map[string]*windowEntry
+	now     func() time.Time
+}
+
+type windowEntry struct {
+	log      *core.WindowLog
+	lastSeen int64
+}
+
+// NewWindowLimiter returns a limiter admitting limit events per key per
+// rolling window.
+func NewWindowLimiter(limit int, window time.Duration) *WindowLimiter {
+	return &WindowLimiter{
+		limit:   limit,
+		window:  window,
+		maxKeys: defaultWindowMaxKeys,
+		keys:    make(map[string]*windowEntry),
+		now:     time.Now,
+	}
+}
+
+// SetMaxKeys changes the memory cap on tracked keys.
+func (wl *WindowLimiter) SetMaxKeys(n int) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	wl.maxKeys = n
+}
+
+// Allow records one event for key, reporting whether it was admitted and,
+// if not, how long until a slot frees up.
+func (wl *WindowLimiter) Allow(key string) (ok bool, retryAfter time.Duration) {
+	now := wl.now()
+	wl.mu.Lock()
+	e, found := wl.keys[key]
+	if !found {
+		if len(wl.keys) >= wl.maxKeys {
+			wl.evict(now)
+		}
+		e = &windowEntry{log: core.NewWindowLog(wl.limit, wl.window)}
+		wl.keys[key] = e
+	}
+	e.lastSeen = now.UnixNano()
+	wl.mu.Unlock()
+	return e.log.Allow(now)
+}
+
+// Len returns the number of keys currently tracked.
+func (wl *WindowLimiter) Len() int {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	return len(wl.keys)
+}
+
+// evict makes room for one more key: idle logs — no events left in the
+// window — go first, then the least recently seen key. Callers must hold mu.
+func (wl *WindowLimiter) evict(now time.Time) {
+	for key, e := range wl.keys {
+		if e.log.Active(now) == 0 {
+			delete(wl.keys, key)
+		}
+	}
+	for len(wl.keys) >= wl.maxKeys {
+		oldestKey := ""
+		oldest := int64(1<<63 - 1)
+		for key, e := range wl.keys {
+			if e.lastSeen < oldest {
+				oldest = e.lastSeen
+				oldestKey = key
+			}
+		}
+		delete(wl.keys, oldestKey)
+	}
+}
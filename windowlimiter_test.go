@@ -0,0 +1,81 @@
+package signalfence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowLimiterExactness(t *testing.T) {
+	clock := newFakeClock()
+	wl := NewWindowLimiter(3, time.Hour)
+	wl.now = clock.Now
+
+	// Admit three events at t=0, 10 and 20 minutes.
+	for i := 0; i < 3; i++ {
+		if ok, _ := wl.Allow("user"); !ok {
+			t.Fatalf("event %d denied under the limit", i)
+		}
+		clock.Advance(10 * time.Minute)
+	}
+	ok, retry := wl.Allow("user")
+	if ok {
+		t.Fatal("fourth event in the hour admitted")
+	}
+	if retry <= 0 || retry > time.Hour {
+		t.Fatalf("retry hint = %v", retry)
+	}
+
+	// At t=50m the window still holds all three events — a token bucket
+	// would have refilled here, the log must not.
+	clock.Advance(20 * time.Minute)
+	if ok, _ := wl.Allow("user"); ok {
+		t.Fatal("admitted while three events remain in the rolling hour")
+	}
+
+	// At t=61m only the first event has aged out: exactly one slot frees.
+	clock.Advance(11 * time.Minute)
+	if ok, _ := wl.Allow("user"); !ok {
+		t.Fatal("denied after the oldest event aged out")
+	}
+	if ok, _ := wl.Allow("user"); ok {
+		t.Fatal("second slot should not be free yet")
+	}
+
+	// Keys are independent.
+	if ok, _ := wl.Allow("other"); !ok {
+		t.Fatal("fresh key denied")
+	}
+}
+
+func TestWindowLimiterMemoryCap(t *testing.T) {
+	clock := newFakeClock()
+	wl := NewWindowLimiter(1, time.Minute)
+	wl.now = clock.Now
+	wl.SetMaxKeys(4)
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		wl.Allow(key)
+		clock.Advance(time.Second)
+	}
+	if wl.Len() != 4 {
+		t.Fatalf("tracking %d keys, want 4", wl.Len())
+	}
+
+	// A fifth key forces an eviction; nothing has aged out, so the least
+	// recently seen key ("a") goes.
+	wl.Allow("e")
+	if wl.Len() != 4 {
+		t.Fatalf("tracking %d keys after eviction, want 4", wl.Len())
+	}
+	if ok, _ := wl.Allow("a"); !ok {
+		t.Fatal("evicted key should start from a fresh window")
+	}
+
+	// Once windows age out, idle logs are reclaimed before anything live.
+	clock.Advance(2 * time.Minute)
+	wl.Allow("f")
+	wl.Allow("g")
+	if wl.Len() > 4 {
+		t.Fatalf("idle logs not reclaimed, tracking %d keys", wl.Len())
+	}
+}
@@ -0,0 +1,109 @@
+package signalfence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Worker restart backoff bounds: a panicked worker restarts after
+// workerBackoffMin, doubling per consecutive panic up to workerBackoffMax.
+const (
+	workerBackoffMin = time.Second
+	workerBackoffMax = 30 * time.Second
+)
+
+// WorkerStatus is one supervised background worker's health, as reported
+// by Health.
+type WorkerStatus struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// Restarts counts panic recoveries; a climbing value means the worker
+	// is crash-looping and its backoff is absorbing the panics.
+	Restarts  uint64    `json:"restarts"`
+	LastPanic string    `json:"last_panic,omitempty"`
+	PanicAt   time.Time `json:"panic_at"`
+}
+
+// workerState tracks one supervised worker.
+type workerState struct {
+	name     string
+	running  atomic.Bool
+	restarts atomic.Uint64
+
+	mu        sync.Mutex
+	lastPanic string
+	panicAt   time.Time
+}
+
+// Go runs fn as a supervised background worker under the limiter's
+// lifetime: panics are recovered and the worker restarted with exponential
+// backoff instead of dying silently, and its health shows up in Health by
+// name. fn should loop until ctx ends; returning normally retires the
+// worker. The limiter's own background tasks (retention, policy
+// followers, elected cleanup) run through here, and embedders can add
+// their own.
+func (rl *RateLimiter) Go(name string, fn func(ctx context.Context)) {
+	w := &workerState{name: name}
+	if prev, loaded := rl.workers.LoadOrStore(name, w); loaded {
+		w = prev.(*workerState)
+	}
+	w.running.Store(true)
+	go func() {
+		defer w.running.Store(false)
+		backoff := workerBackoffMin
+		for {
+			if done := w.runOnce(rl.bgCtx, fn); done {
+				return
+			}
+			select {
+			case <-rl.bgCtx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > workerBackoffMax {
+				backoff = workerBackoffMax
+			}
+		}
+	}()
+}
+
+// runOnce runs one supervised invocation of fn, recovering a panic and
+// reporting whether the worker is done (clean return or context end)
+// rather than due a restart.
+func (w *workerState) runOnce(ctx context.Context, fn func(ctx context.Context)) (done bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.restarts.Add(1)
+			w.mu.Lock()
+			w.lastPanic = fmt.Sprint(r)
+			w.panicAt = time.Now()
+			w.mu.Unlock()
+			done = ctx.Err() != nil
+		}
+	}()
+	fn(ctx)
+	return true
+}
+
+// Health reports the status of every supervised worker, keyed by name.
+func (rl *RateLimiter) Health() map[string]WorkerStatus {
+	out := make(map[string]WorkerStatus)
+	rl.workers.Range(func(k, v any) bool {
+		w := v.(*workerState)
+		w.mu.Lock()
+		st := WorkerStatus{
+			Name:      w.name,
+			Running:   w.running.Load(),
+			Restarts:  w.restarts.Load(),
+			LastPanic: w.lastPanic,
+			PanicAt:   w.panicAt,
+		}
+		w.mu.Unlock()
+		out[k.(string)] = st
+		return true
+	})
+	return out
+}
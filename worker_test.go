@@ -0,0 +1,70 @@
+package signalfence
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met in time")
+}
+
+func TestWorkerRecoversFromPanic(t *testing.T) {
+	rl := NewRateLimiter(Config{})
+	defer rl.Close()
+
+	calls := make(chan struct{}, 8)
+	rl.Go("flaky", func(ctx context.Context) {
+		calls <- struct{}{}
+		panic("boom")
+	})
+
+	// The first run panics; the supervisor restarts it after backoff.
+	<-calls
+	waitFor(t, func() bool { return rl.Health()["flaky"].Restarts >= 1 })
+
+	st := rl.Health()["flaky"]
+	if st.LastPanic != "boom" {
+		t.Fatalf("LastPanic = %q", st.LastPanic)
+	}
+	if st.PanicAt.IsZero() {
+		t.Fatal("PanicAt not recorded")
+	}
+	if !st.Running {
+		t.Fatal("worker not marked running while supervised")
+	}
+}
+
+func TestWorkerRetiresOnCleanReturn(t *testing.T) {
+	rl := NewRateLimiter(Config{})
+	defer rl.Close()
+
+	done := make(chan struct{})
+	rl.Go("oneshot", func(ctx context.Context) { close(done) })
+	<-done
+	waitFor(t, func() bool {
+		st := rl.Health()["oneshot"]
+		return !st.Running && st.Restarts == 0
+	})
+}
+
+func TestWorkerStopsOnClose(t *testing.T) {
+	rl := NewRateLimiter(Config{})
+	started := make(chan struct{})
+	rl.Go("looper", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+	rl.Close()
+	waitFor(t, func() bool { return !rl.Health()["looper"].Running })
+}
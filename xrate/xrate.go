@@ -0,0 +1,156 @@
+// Package xrate adapts golang.org/x/time/rate limiters to SignalFence's
+// keying and middleware, for teams already standardized on that package
+// who want its exact semantics — including ReserveN — per client key.
+//
+// The dependency is inverted so this module stays free of external
+// requirements: the caller constructs their own *rate.Limiter values and
+// hands them over through the Limiter interface, which *rate.Limiter
+// satisfies with a one-line wrapper for the ReserveN return type:
+//
+//	type xl struct{ *rate.Limiter }
+//
+//	func (l xl) ReserveN(t time.Time, n int) xrate.Reservation {
+//		return l.Limiter.ReserveN(t, n)
+//	}
+//
+//	keyed := xrate.NewKeyed(func(key string) xrate.Limiter {
+//		return xl{rate.NewLimiter(10, 30)}
+//	})
+package xrate
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/signalfence"
+)
+
+// Limiter is the subset of *rate.Limiter the adapter drives. Any limiter
+// with compatible token accounting can stand in.
+type Limiter interface {
+	AllowN(now time.Time, n int) bool
+	ReserveN(now time.Time, n int) Reservation
+}
+
+// Reservation matches *rate.Reservation: a claim on future tokens that can
+// be cancelled if the caller decides not to wait.
+type Reservation interface {
+	OK() bool
+	DelayFrom(now time.Time) time.Duration
+	CancelAt(now time.Time)
+}
+
+// keyedEntry pairs a key's limiter with its last use, for idle expiry.
+type keyedEntry struct {
+	lim      Limiter
+	lastSeen time.Time
+}
+
+// Keyed maintains one Limiter per client key, created on first use.
+type Keyed struct {
+	newLimiter func(key string) Limiter
+	now        func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*keyedEntry
+}
+
+// NewKeyed returns a Keyed creating per-key limiters with newLimiter.
+func NewKeyed(newLimiter func(key string) Limiter) *Keyed {
+	return &Keyed{
+		newLimiter: newLimiter,
+		now:        time.Now,
+		limiters:   make(map[string]*keyedEntry),
+	}
+}
+
+// limiter returns key's limiter, creating it on first use.
+func (k *Keyed) limiter(key string) Limiter {
+	now := k.now()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.limiters[key]
+	if !ok {
+		e = &keyedEntry{lim: k.newLimiter(key)}
+		k.limiters[key] = e
+	}
+	e.lastSeen = now
+	return e.lim
+}
+
+// Allow reports whether key may proceed now, consuming one token.
+func (k *Keyed) Allow(key string) bool {
+	return k.AllowN(key, 1)
+}
+
+// AllowN reports whether key may consume n tokens now.
+func (k *Keyed) AllowN(key string, n int) bool {
+	return k.limiter(key).AllowN(k.now(), n)
+}
+
+// ReserveN claims n future tokens for key, with rate's reservation
+// semantics: the claim always succeeds unless n exceeds the burst, and the
+// caller either waits out the delay or cancels.
+func (k *Keyed) ReserveN(key string, n int) Reservation {
+	return k.limiter(key).ReserveN(k.now(), n)
+}
+
+// Len returns the number of keys currently tracked.
+func (k *Keyed) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.limiters)
+}
+
+// Cleanup removes limiters idle for longer than maxIdle and returns how
+// many were removed.
+func (k *Keyed) Cleanup(maxIdle time.Duration) int {
+	now := k.now()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	removed := 0
+	for key, e := range k.limiters {
+		if now.Sub(e.lastSeen) > maxIdle {
+			delete(k.limiters, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Middleware wraps next with per-key limiting in SignalFence's HTTP style:
+// denied requests receive 429 with a Retry-After derived from a cancelled
+// reservation, so the hint reflects rate's own accounting. A nil keyFn
+// keys by client IP.
+func (k *Keyed) Middleware(keyFn func(*http.Request) string, next http.Handler) http.Handler {
+	if keyFn == nil {
+		keyFn = signalfence.ClientIP
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := k.now()
+		res := k.ReserveN(keyFn(r), 1)
+		if res.OK() {
+			if delay := res.DelayFrom(now); delay > 0 {
+				res.CancelAt(now)
+				deny(w, delay)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		deny(w, 0)
+	})
+}
+
+// deny writes the 429 response with a Retry-After hint.
+func deny(w http.ResponseWriter, delay time.Duration) {
+	retry := int(math.Ceil(delay.Seconds()))
+	if retry < 1 {
+		retry = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+	http.Error(w, "429 Too Many Requests", http.StatusTooManyRequests)
+}
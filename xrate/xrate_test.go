@@ -0,0 +1,152 @@
+package xrate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeLimiter implements rate.Limiter-style accounting for the adapter
+// tests, so they run without the real dependency.
+type fakeLimiter struct {
+	tokens float64
+	limit  float64
+	burst  int
+	last   time.Time
+}
+
+func (l *fakeLimiter) advance(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.limit
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
+
+func (l *fakeLimiter) AllowN(now time.Time, n int) bool {
+	l.advance(now)
+	if l.tokens >= float64(n) {
+		l.tokens -= float64(n)
+		return true
+	}
+	return false
+}
+
+func (l *fakeLimiter) ReserveN(now time.Time, n int) Reservation {
+	l.advance(now)
+	if n > l.burst {
+		return &fakeReservation{}
+	}
+	l.tokens -= float64(n)
+	r := &fakeReservation{ok: true, lim: l, n: n}
+	if l.tokens < 0 {
+		r.delay = time.Duration(-l.tokens / l.limit * float64(time.Second))
+	}
+	return r
+}
+
+type fakeReservation struct {
+	ok    bool
+	delay time.Duration
+	lim   *fakeLimiter
+	n     int
+}
+
+func (r *fakeReservation) OK() bool                          { return r.ok }
+func (r *fakeReservation) DelayFrom(time.Time) time.Duration { return r.delay }
+func (r *fakeReservation) CancelAt(time.Time)                { r.lim.tokens += float64(r.n) }
+
+func newTestKeyed(start time.Time) (*Keyed, *time.Time) {
+	now := start
+	k := NewKeyed(func(key string) Limiter {
+		return &fakeLimiter{tokens: 3, limit: 1, burst: 3}
+	})
+	k.now = func() time.Time { return now }
+	return k, &now
+}
+
+func TestKeyedAllowPerKey(t *testing.T) {
+	k, _ := newTestKeyed(time.Unix(1700000000, 0))
+
+	for i := 0; i < 3; i++ {
+		if !k.Allow("user:a") {
+			t.Fatalf("take %d denied within burst", i)
+		}
+	}
+	if k.Allow("user:a") {
+		t.Fatal("burst exceeded")
+	}
+	// Keys are independent.
+	if !k.Allow("user:b") {
+		t.Fatal("fresh key denied")
+	}
+	if k.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", k.Len())
+	}
+}
+
+func TestKeyedReserveN(t *testing.T) {
+	k, now := newTestKeyed(time.Unix(1700000000, 0))
+
+	res := k.ReserveN("user:a", 3)
+	if !res.OK() || res.DelayFrom(*now) != 0 {
+		t.Fatalf("burst reservation: ok=%v delay=%v", res.OK(), res.DelayFrom(*now))
+	}
+	// The next token is a second out.
+	res = k.ReserveN("user:a", 1)
+	if !res.OK() || res.DelayFrom(*now) != time.Second {
+		t.Fatalf("queued reservation: ok=%v delay=%v", res.OK(), res.DelayFrom(*now))
+	}
+	// Over-burst reservations fail outright.
+	if k.ReserveN("user:b", 10).OK() {
+		t.Fatal("over-burst reservation succeeded")
+	}
+}
+
+func TestKeyedMiddleware(t *testing.T) {
+	k, _ := newTestKeyed(time.Unix(1700000000, 0))
+	h := k.Middleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.1.1:999"
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d", i, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Retry-After = %q, want 1", rec.Header().Get("Retry-After"))
+	}
+	// The cancelled reservation returned its token: one more denial does
+	// not deepen the debt.
+	if k.ReserveN("10.1.1.1", 1).DelayFrom(k.now()) > time.Second {
+		t.Fatal("denied request consumed tokens")
+	}
+}
+
+func TestKeyedCleanup(t *testing.T) {
+	k, now := newTestKeyed(time.Unix(1700000000, 0))
+	k.Allow("user:a")
+	k.Allow("user:b")
+
+	*now = now.Add(10 * time.Minute)
+	k.Allow("user:b")
+	if removed := k.Cleanup(5 * time.Minute); removed != 1 {
+		t.Fatalf("Cleanup removed %d, want 1", removed)
+	}
+	if k.Len() != 1 {
+		t.Fatalf("Len = %d after cleanup, want 1", k.Len())
+	}
+}